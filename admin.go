@@ -0,0 +1,351 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// AdminHandler returns an http.Handler exposing the admin endpoints of the Handler.
+// Mount it under a path of your choice, e.g.:
+//
+//	http.Handle("/debug/slogscope/", http.StripPrefix("/debug/slogscope", h.AdminHandler()))
+//
+// Available endpoints:
+//
+//	GET  /tail?package=pkg/db&level=DEBUG&regex=timeout  Streams matching records as server-sent events.
+//	GET  /config?format=json                              Returns the current config (see ExportConfig).
+//	GET  /docs?format=html                                Returns the documentation of the current config (see ExportDocs).
+//	PUT  /config                                          Applies the config given as JSON, YAML or TOML body (see UseConfig).
+//	                                                      Invalid configs are rejected (see Config.Validate).
+//	PUT  /config?canary=30s                               Applies the config for a probation window (see UseConfigCanary).
+//	GET  /packages                                        Lists configured and observed packages (see GetPackages).
+//	PUT  /packages/{name...}                              Sets the log level of a package (see SetPackageLevel).
+//	                                                      Body: {"log_level": "DEBUG"}
+//	POST /overrides                                       Temporarily sets the log level of a package.
+//	                                                      Body: {"package": "pkg/db", "log_level": "DEBUG", "ttl": "5m",
+//	                                                      "session": "incident-1234"} (session is optional)
+//	GET  /snapshot?duration=30s                           Returns a gzip-compressed debug snapshot (see DebugSnapshot).
+//	GET  /explain?package=pkg/db&level=DEBUG              Explains the log level decision for a package (see ExplainDecision).
+//	GET  /history                                         Lists the recently applied configs (see History).
+//	POST /rollback?n=1                                    Reapplies the n-th previous config (see Rollback).
+//	GET  /stats                                           Returns diagnostic counters (see Stats).
+//	GET  /maintenance                                     Reports whether the maintenance mode is active.
+//	PUT  /maintenance                                     Activates or deactivates the maintenance mode (see SetMaintenanceMode).
+//	                                                      Body: {"enabled": true}
+//	POST /selftest                                        Runs a self-test of the pipeline (see SelfTest), status 503 if it fails.
+func (h *Handler) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tail", h.handleTail)
+	mux.HandleFunc("GET /config", h.handleGetConfig)
+	mux.HandleFunc("PUT /config", h.handlePutConfig)
+	mux.HandleFunc("GET /docs", h.handleGetDocs)
+	mux.HandleFunc("GET /packages", h.handleGetPackages)
+	mux.HandleFunc("PUT /packages/{name...}", h.handlePutPackage)
+	mux.HandleFunc("POST /overrides", h.handlePostOverride)
+	mux.HandleFunc("GET /snapshot", h.handleGetSnapshot)
+	mux.HandleFunc("GET /explain", h.handleGetExplain)
+	mux.HandleFunc("GET /history", h.handleGetHistory)
+	mux.HandleFunc("POST /rollback", h.handlePostRollback)
+	mux.HandleFunc("GET /stats", h.handleGetStats)
+	mux.HandleFunc("GET /maintenance", h.handleGetMaintenance)
+	mux.HandleFunc("PUT /maintenance", h.handlePutMaintenance)
+	mux.HandleFunc("POST /selftest", h.handlePostSelfTest)
+	return mux
+}
+
+// override is the request body of the POST /overrides endpoint.
+type override struct {
+	Package  string `json:"package"`
+	LogLevel string `json:"log_level"`
+	TTL      string `json:"ttl"`
+	Session  string `json:"session,omitempty"` // Name of the override session (see HandlerOptions.AnnotateOverrides).
+}
+
+func (h *Handler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	data, err := h.ExportConfig(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == FormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	_, _ = w.Write(data)
+}
+
+func (h *Handler) handleGetDocs(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatMarkdown
+	}
+	data, err := h.ExportDocs(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == FormatHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	_, _ = w.Write(data)
+}
+
+func (h *Handler) handlePutConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var cfg Config
+	if err = decodeConfig(data, &cfg); err != nil {
+		http.Error(w, fmt.Sprintf("invalid config: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	var invalid ValidationErrors
+	if errors.As(cfg.Validate(), &invalid) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errors": invalid})
+		return
+	}
+
+	prov := provenance{source: "admin PUT /config from " + r.RemoteAddr}
+	if v := r.URL.Query().Get("canary"); v != "" {
+		probation, err := time.ParseDuration(v)
+		if err != nil || probation <= 0 {
+			http.Error(w, fmt.Sprintf("invalid canary probation: %q", v), http.StatusBadRequest)
+			return
+		}
+		res := h.useConfigCanary(cfg, CanaryOptions{Probation: probation}, prov)
+		if !res.Accepted {
+			writeJSON(w, http.StatusConflict, res)
+			return
+		}
+		h.persist(cfg)
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	h.useConfig(cfg, prov)
+	h.persist(cfg)
+	writeJSON(w, http.StatusOK, h.GetConfig())
+}
+
+func (h *Handler) handleGetPackages(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.GetPackages())
+}
+
+// packageLevel is the request and response body of the PUT /packages/{name...} endpoint.
+type packageLevel struct {
+	Package  string `json:"package"`
+	LogLevel string `json:"log_level"`
+}
+
+func (h *Handler) handlePutPackage(w http.ResponseWriter, r *http.Request) {
+	var p packageLevel
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	p.Package = r.PathValue("name")
+	if _, ok := parseLogLevel(p.LogLevel); !ok {
+		http.Error(w, fmt.Sprintf("invalid log_level: %q", p.LogLevel), http.StatusBadRequest)
+		return
+	}
+
+	h.setPackageLevel(p.Package, p.LogLevel, "admin PUT /packages from "+r.RemoteAddr)
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) handlePostOverride(w http.ResponseWriter, r *http.Request) {
+	var o override
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	ttl, err := time.ParseDuration(o.TTL)
+	if err != nil || ttl <= 0 {
+		http.Error(w, fmt.Sprintf("invalid ttl: %q", o.TTL), http.StatusBadRequest)
+		return
+	}
+	if o.Package == "" || o.LogLevel == "" {
+		http.Error(w, "package and log_level are required", http.StatusBadRequest)
+		return
+	}
+	if _, ok := parseLogLevel(o.LogLevel); !ok {
+		http.Error(w, fmt.Sprintf("invalid log_level: %q", o.LogLevel), http.StatusBadRequest)
+		return
+	}
+
+	session := o.Session
+	if session == "" {
+		session = defaultOverrideSession
+	}
+	h.usePackageLevelTemporarily(o.Package, o.LogLevel, ttl, "admin POST /overrides from "+r.RemoteAddr, session)
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (h *Handler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	d, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil || d <= 0 || d > maxSnapshotDuration {
+		http.Error(w, fmt.Sprintf("invalid duration: %q (max. %s)", r.URL.Query().Get("duration"), maxSnapshotDuration), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="slogscope-snapshot-%s.jsonl.gz"`, time.Now().UTC().Format("20060102T150405Z")))
+	if err = h.debugSnapshot(r.Context(), d, w); err != nil {
+		h.logger.Debug(fmt.Sprintf("error writing debug snapshot: %s", err.Error()))
+	}
+}
+
+func (h *Handler) handleGetExplain(w http.ResponseWriter, r *http.Request) {
+	pkgName, level := r.URL.Query().Get("package"), r.URL.Query().Get("level")
+	if pkgName == "" || level == "" {
+		http.Error(w, "package and level are required", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.ExplainDecision(pkgName, h.GetLogLevel(level)))
+}
+
+func (h *Handler) handleGetHistory(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.History())
+}
+
+func (h *Handler) handlePostRollback(w http.ResponseWriter, r *http.Request) {
+	n := 1
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid n: %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := h.Rollback(n); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.GetConfig())
+}
+
+func (h *Handler) handleGetStats(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.Stats())
+}
+
+func (h *Handler) handlePostSelfTest(w http.ResponseWriter, r *http.Request) {
+	report := h.SelfTest(r.Context())
+	if !report.Passed {
+		writeJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeJSON writes v as JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// maintenanceMode is the request and response body of the maintenance endpoints.
+type maintenanceMode struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (h *Handler) handleGetMaintenance(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, maintenanceMode{Enabled: h.MaintenanceMode()})
+}
+
+func (h *Handler) handlePutMaintenance(w http.ResponseWriter, r *http.Request) {
+	var m maintenanceMode
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.SetMaintenanceMode(m.Enabled)
+	writeJSON(w, http.StatusOK, maintenanceMode{Enabled: h.MaintenanceMode()})
+}
+
+// handleTail streams all records matching the filter given by the query parameters package, level and regex
+// to the client using server-sent events. Each event contains one record in JSON format.
+func (h *Handler) handleTail(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	t := &tap{pkg: r.URL.Query().Get("package"), level: slog.LevelDebug}
+	if v := r.URL.Query().Get("level"); v != "" {
+		lvl, ok := parseLogLevel(v)
+		if !ok {
+			http.Error(w, fmt.Sprintf("invalid level: %q", v), http.StatusBadRequest)
+			return
+		}
+		t.level = lvl
+	}
+	if expr := r.URL.Query().Get("regex"); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid regex: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		t.re = re
+	}
+
+	recCh := make(chan []byte, tailBufferSize)
+	t.h = slog.NewJSONHandler(chanWriter(recCh), nil)
+	remove := h.taps.add(t)
+	defer remove()
+	h.logger.Debug(fmt.Sprintf("tail client connected: %s", r.RemoteAddr))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case data := <-recCh:
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			h.logger.Debug(fmt.Sprintf("tail client disconnected: %s", r.RemoteAddr))
+			return
+		}
+	}
+}
+
+// chanWriter is an io.Writer sending a copy of every written line to the channel.
+// Lines are dropped if the channel is full, so slow clients never block logging.
+type chanWriter chan []byte
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	select {
+	case c <- line:
+	default:
+	}
+	return len(p), nil
+}
@@ -0,0 +1,236 @@
+//go:build !slogscope_minimal
+
+package slogscope_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_AdminHandler(t *testing.T) {
+	t.Run("test tail streams records independent of the configured log level", func(t *testing.T) {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &newCfg})
+		srv := httptest.NewServer(h.AdminHandler())
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/tail?package=apperia-de/slogscope_test&level=DEBUG&regex=^tailed")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		l := slog.New(h)
+		l.Debug("ignored message")
+		l.Debug("tailed message")
+
+		lineCh := make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if strings.HasPrefix(scanner.Text(), "data: ") {
+					lineCh <- scanner.Text()
+				}
+			}
+		}()
+
+		select {
+		case line := <-lineCh:
+			assert.Contains(t, line, `"msg":"tailed message"`)
+			assert.Contains(t, line, `"level":"DEBUG"`)
+		case <-time.After(time.Second):
+			t.Fatal("no record received")
+		}
+		// The wrapped handler must not receive DEBUG records, since the configured log level is ERROR.
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test tail rejects invalid regular expressions", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &newCfg})
+		srv := httptest.NewServer(h.AdminHandler())
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/tail?regex=(")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("test tail rejects invalid levels", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &newCfg})
+		srv := httptest.NewServer(h.AdminHandler())
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/tail?level=DEBG")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "invalid level: \"DEBG\"\n", string(body))
+	})
+}
+
+func TestHandler_AdminHandlerPackages(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+	srv := httptest.NewServer(h.AdminHandler())
+	defer srv.Close()
+
+	slog.New(h).Info("observed message")
+
+	t.Run("test observed packages are listed", func(t *testing.T) {
+		var packages []slogscope.PackageInfo
+		resp, err := http.Get(srv.URL + "/packages")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&packages))
+		assert.Equal(t, []slogscope.PackageInfo{
+			{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug, Observed: 1},
+		}, packages)
+	})
+
+	t.Run("test temporary package override", func(t *testing.T) {
+		body := `{"package": "github.com/apperia-de/slogscope_test", "log_level": "ERROR", "ttl": "100ms"}`
+		resp, err := http.Post(srv.URL+"/overrides", "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, slogscope.LogLevelError, h.GetPackages()[0].LogLevel)
+
+		time.Sleep(150 * time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelDebug, h.GetPackages()[0].LogLevel)
+	})
+
+	t.Run("test invalid override is rejected", func(t *testing.T) {
+		for _, body := range []string{
+			`{"package": "x", "ttl": "-1s"}`,
+			`{"package": "x", "log_level": "LOUD", "ttl": "1m"}`,
+		} {
+			resp, err := http.Post(srv.URL+"/overrides", "application/json", strings.NewReader(body))
+			assert.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+		}
+		assert.Len(t, h.GetPackages(), 1)
+	})
+
+	t.Run("test package level", func(t *testing.T) {
+		put := func(pkgName, body string) int {
+			req, err := http.NewRequest(http.MethodPut, srv.URL+"/packages/"+pkgName, strings.NewReader(body))
+			assert.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			_ = resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, http.StatusOK, put("github.com/foo/bar", `{"log_level": "WARN"}`))
+		d := h.ExplainDecision("github.com/foo/bar", slog.LevelInfo)
+		assert.False(t, d.Enabled)
+		assert.Equal(t, "github.com/foo/bar", d.Rule)
+		assert.Contains(t, d.Source, "admin PUT /packages from ")
+		// Other package rules are kept.
+		assert.Equal(t, slogscope.LogLevelDebug, h.ExplainDecision("github.com/apperia-de/slogscope_test", slog.LevelDebug).LogLevel)
+
+		assert.Equal(t, http.StatusBadRequest, put("github.com/foo/bar", `{"log_level": "LOUD"}`))
+		assert.Equal(t, http.StatusBadRequest, put("github.com/foo/bar", `{`))
+	})
+}
+
+func TestHandler_AdminHandlerConfig(t *testing.T) {
+	src := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelWarn,
+		Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug}},
+	}})
+	dst := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &newCfg})
+	srcSrv := httptest.NewServer(src.AdminHandler())
+	defer srcSrv.Close()
+	dstSrv := httptest.NewServer(dst.AdminHandler())
+	defer dstSrv.Close()
+
+	t.Run("test config of one instance is applied to another instance", func(t *testing.T) {
+		resp, err := http.Get(srcSrv.URL + "/config")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		req, err := http.NewRequest(http.MethodPut, dstSrv.URL+"/config", resp.Body)
+		assert.NoError(t, err)
+		putResp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = putResp.Body.Close()
+		assert.Equal(t, http.StatusOK, putResp.StatusCode)
+		assert.Equal(t, src.GetConfig(), dst.GetConfig())
+	})
+
+	t.Run("test config in yaml format", func(t *testing.T) {
+		requireFullBuild(t)
+
+		resp, err := http.Get(srcSrv.URL + "/config?format=yaml")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "log_level: WARN")
+	})
+
+	t.Run("test invalid config is rejected", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, dstSrv.URL+"/config", strings.NewReader(`{"log_level": 1`))
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestHandler_AnnotateOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, nil), &slogscope.HandlerOptions{
+		Config:            &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+		AnnotateOverrides: true,
+	})
+	srv := httptest.NewServer(h.AdminHandler())
+	defer srv.Close()
+	l := slog.New(h)
+
+	t.Run("test records without override are not annotated", func(t *testing.T) {
+		buf.Reset()
+		l.Info("normal message")
+		assert.NotContains(t, buf.String(), "slogscope.override")
+	})
+
+	t.Run("test records are annotated with the override session", func(t *testing.T) {
+		body := `{"package": "github.com/apperia-de/slogscope_test", "log_level": "DEBUG", "ttl": "100ms", "session": "incident-1234"}`
+		resp, err := http.Post(srv.URL+"/overrides", "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+
+		buf.Reset()
+		l.Debug("debug message")
+		assert.Contains(t, buf.String(), "slogscope.override=incident-1234")
+
+		time.Sleep(150 * time.Millisecond)
+		buf.Reset()
+		l.Info("normal message")
+		assert.NotContains(t, buf.String(), "slogscope.override")
+	})
+
+	t.Run("test unnamed sessions", func(t *testing.T) {
+		h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelDebug}, 100*time.Millisecond)
+		buf.Reset()
+		l.Debug("debug message")
+		assert.Contains(t, buf.String(), "slogscope.override=temporary")
+		time.Sleep(150 * time.Millisecond)
+	})
+}
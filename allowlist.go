@@ -0,0 +1,51 @@
+package slogscope
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// allowKeys returns the record with the attributes, whose keys are not allowed by the rule p, removed (see
+// Package.AllowedKeys). The rule may be nil. Removed attributes are counted by package name (see Stats.DroppedAttrs).
+func (ss *slogscope) allowKeys(p *pkg, pkgName string, rec slog.Record) slog.Record {
+	if p == nil || p.allowedKeys == nil {
+		return rec
+	}
+	var dropped uint64
+	rec.Attrs(func(a slog.Attr) bool {
+		if _, ok := p.allowedKeys[a.Key]; !ok {
+			dropped++
+		}
+		return true
+	})
+	if dropped == 0 {
+		return rec
+	}
+
+	allowed := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		if _, ok := p.allowedKeys[a.Key]; ok {
+			allowed.AddAttrs(a)
+		}
+		return true
+	})
+	v, ok := ss.droppedAttrs.Load(pkgName)
+	if !ok {
+		v, _ = ss.droppedAttrs.LoadOrStore(pkgName, new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(dropped)
+	return allowed
+}
+
+// droppedAttrsSnapshot returns the number of attributes removed by Package.AllowedKeys by package name.
+func (ss *slogscope) droppedAttrsSnapshot() map[string]uint64 {
+	var dropped map[string]uint64
+	ss.droppedAttrs.Range(func(k, v any) bool {
+		if dropped == nil {
+			dropped = map[string]uint64{}
+		}
+		dropped[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return dropped
+}
@@ -0,0 +1,52 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_AllowedKeys(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{
+			Name:        "github.com/apperia-de/slogscope_test",
+			LogLevel:    slogscope.LogLevelInfo,
+			AllowedKeys: []string{"method", "status"},
+		}},
+	}})
+	l := slog.New(h)
+
+	t.Run("test attributes with allowed keys are kept", func(t *testing.T) {
+		out.Reset()
+		l.Info("request", "method", "GET", "status", 200)
+		assert.Contains(t, out.String(), "method=GET status=200")
+		assert.Empty(t, h.Stats().DroppedAttrs)
+	})
+
+	t.Run("test other attributes are dropped and counted", func(t *testing.T) {
+		out.Reset()
+		l.Info("request", "method", "GET", "user_id", 42, "headers", map[string]string{"a": "b"})
+		assert.Contains(t, out.String(), "method=GET")
+		assert.NotContains(t, out.String(), "user_id")
+		assert.NotContains(t, out.String(), "headers")
+		assert.Equal(t, map[string]uint64{"github.com/apperia-de/slogscope_test": 2}, h.Stats().DroppedAttrs)
+	})
+
+	t.Run("test attributes of derived loggers are kept", func(t *testing.T) {
+		out.Reset()
+		l.With("component", "api").Info("request", "method", "GET")
+		assert.Contains(t, out.String(), "component=api method=GET")
+	})
+
+	t.Run("test empty key is invalid", func(t *testing.T) {
+		cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelInfo, AllowedKeys: []string{""}},
+		}}
+		assert.ErrorContains(t, cfg.Validate(), "packages[0].allowed_keys[0]")
+	})
+}
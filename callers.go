@@ -0,0 +1,37 @@
+package slogscope
+
+import "runtime"
+
+// caller is the resolved function of a program counter.
+type caller struct {
+	function string // Fully qualified function name, e.g. "github.com/foo/bar.(*Server).handle".
+	pkgName  string // Package name (see splitFuncName).
+	funcName string // Function name without the package.
+	file     string // Path of the source file.
+}
+
+// callerOf returns the function of a program counter as returned by runtime.Callers, e.g. slog.Record.PC.
+// Functions are cached by their entry, so every function is resolved only once (see Handler.WarmUp). Program counters
+// within inlined functions are resolved every time, since their entry is the one of the function they are inlined to.
+func (ss *slogscope) callerOf(pc uintptr) caller {
+	if pc == 0 {
+		return caller{}
+	}
+	// The program counter is the return address, so pc-1 is within the calling function.
+	f := runtime.FuncForPC(pc - 1)
+	if f == nil {
+		return caller{}
+	}
+	if v, ok := ss.callers.Load(f.Entry()); ok {
+		if c := v.(*caller); c.function == f.Name() {
+			return *c
+		}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkgName, funcName := splitFuncName(frame.Function)
+	c := &caller{function: frame.Function, pkgName: pkgName, funcName: funcName, file: frame.File}
+	if frame.Func != nil {
+		ss.callers.Store(f.Entry(), c)
+	}
+	return *c
+}
@@ -0,0 +1,39 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_WarmUp(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{
+				Name:      "github.com/apperia-de/slogscope_test",
+				LogLevel:  slogscope.LogLevelWarn,
+				Functions: []slogscope.Function{{Name: "logFromOtherFunction", LogLevel: slogscope.LogLevelDebug}},
+			}},
+		},
+	})
+
+	n, err := h.WarmUp()
+	if err != nil {
+		t.Skipf("symbol table is not available: %s", err.Error())
+	}
+	assert.Greater(t, n, 0)
+
+	l := slog.New(h)
+	l.Info("info from test")
+	logFromOtherFunction(l, "debug from other function")
+	func() { l.Warn("warn from closure") }()
+
+	assert.NotContains(t, buf.String(), "info from test")
+	assert.Contains(t, buf.String(), "debug from other function")
+	assert.Contains(t, buf.String(), "warn from closure")
+}
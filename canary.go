@@ -0,0 +1,112 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// CanaryOptions configure the probation window of Handler.UseConfigCanary.
+type CanaryOptions struct {
+	Probation time.Duration // Duration of the probation window (default: 30s).
+	MaxFactor float64       // Maximum factor of the records per second during probation compared to the baseline (default: 10).
+	MinRate   float64       // Lower bound of the baseline in records per second, e.g. for mostly silent services (default: 1).
+}
+
+// CanaryResult reports the outcome of Handler.UseConfigCanary.
+type CanaryResult struct {
+	Accepted bool    `json:"accepted"`
+	Baseline float64 `json:"baseline"` // Records per second before the config was applied.
+	Rate     float64 `json:"rate"`     // Records per second during probation.
+	Records  uint64  `json:"records"`  // Number of records during probation.
+}
+
+// UseConfigCanary applies the Config like UseConfig, but only for a probation window, during which the number of
+// records handed to the wrapped slog.Handler is measured. If it exceeds MaxFactor times the rate measured since
+// the previous config was applied, the previous config is restored immediately and a warning is emitted via the
+// wrapped slog.Handler. UseConfigCanary blocks until the config is either accepted or rolled back.
+func (h *Handler) UseConfigCanary(cfg Config, opts CanaryOptions) CanaryResult {
+	res := h.useConfigCanary(cfg, opts, provenance{source: "UseConfigCanary"})
+	if res.Accepted {
+		h.persist(cfg)
+	}
+	return res
+}
+
+// useConfigCanary is UseConfigCanary, recording prov as the provenance of the config.
+func (h *Handler) useConfigCanary(cfg Config, opts CanaryOptions, prov provenance) CanaryResult {
+	if opts.Probation <= 0 {
+		opts.Probation = 30 * time.Second
+	}
+	if opts.MaxFactor <= 0 {
+		opts.MaxFactor = 10
+	}
+	if opts.MinRate <= 0 {
+		opts.MinRate = 1
+	}
+
+	h.mu.Lock()
+	oldCfg, oldProv := *h.opts.Config, h.prov
+	enableFileWatcher := h.opts.EnableFileWatcher
+	res := CanaryResult{Baseline: max(h.rate(), opts.MinRate)}
+	h.mu.Unlock()
+
+	h.useConfig(cfg, prov)
+
+	h.mu.Lock()
+	canary, start, startCnt := h.opts.Config, h.appliedAt, h.appliedCnt
+	h.mu.Unlock()
+
+	budget := uint64(res.Baseline * opts.MaxFactor * opts.Probation.Seconds())
+	ticker := time.NewTicker(min(opts.Probation/10, time.Second))
+	defer ticker.Stop()
+	deadline := time.After(opts.Probation)
+probation:
+	for {
+		select {
+		case <-ticker.C:
+			if h.handled.Load()-startCnt > budget {
+				break probation
+			}
+		case <-deadline:
+			break probation
+		}
+	}
+
+	res.Records = h.handled.Load() - startCnt
+	res.Rate = float64(res.Records) / max(time.Since(start).Seconds(), 1e-3)
+	res.Accepted = res.Records <= budget
+	if res.Accepted {
+		return res
+	}
+
+	h.mu.Lock()
+	replaced := h.opts.Config != canary
+	h.mu.Unlock()
+	if replaced {
+		// The config was replaced in the meantime, e.g. by UseConfig, so there is nothing to roll back.
+		return res
+	}
+	if enableFileWatcher {
+		h.UseConfigFile()
+	} else {
+		h.useConfig(oldCfg, oldProv)
+	}
+	slog.New(h.slogh).Warn("slogscope: canary config rolled back",
+		"records", res.Records,
+		"rate", fmt.Sprintf("%.1f/s", res.Rate),
+		"baseline", fmt.Sprintf("%.1f/s", res.Baseline),
+		"max_factor", opts.MaxFactor,
+	)
+	return res
+}
+
+// rate returns the records per second handed to the wrapped slog.Handler since the current config was applied.
+// The caller must hold ss.mu.
+func (ss *slogscope) rate() float64 {
+	elapsed := time.Since(ss.appliedAt).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(ss.handled.Load()-ss.appliedCnt) / elapsed
+}
@@ -0,0 +1,53 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_UseConfigCanary(t *testing.T) {
+	errorCfg := slogscope.Config{LogLevel: slogscope.LogLevelError}
+	debugCfg := slogscope.Config{LogLevel: slogscope.LogLevelDebug}
+	opts := slogscope.CanaryOptions{Probation: 300 * time.Millisecond, MaxFactor: 10, MinRate: 1}
+
+	t.Run("test config is accepted", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &errorCfg})
+		res := h.UseConfigCanary(debugCfg, opts)
+		assert.True(t, res.Accepted)
+		assert.Equal(t, uint64(0), res.Records)
+		assert.Equal(t, debugCfg, h.GetConfig())
+	})
+
+	t.Run("test config is rolled back if the volume exceeds the baseline", func(t *testing.T) {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &errorCfg})
+		l := slog.New(h)
+
+		stop, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				case <-time.After(time.Millisecond):
+					l.Debug("noisy message")
+				}
+			}
+		}()
+		res := h.UseConfigCanary(debugCfg, opts)
+		close(stop)
+		<-done
+
+		assert.False(t, res.Accepted)
+		assert.Greater(t, res.Records, uint64(3))
+		assert.Equal(t, float64(1), res.Baseline)
+		assert.Equal(t, errorCfg, h.GetConfig())
+		assert.Contains(t, out.String(), `msg="slogscope: canary config rolled back"`)
+	})
+}
@@ -0,0 +1,63 @@
+package slogscope
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// CaptureToFile temporarily writes all records of the given package at DEBUG level to the file at path in JSON format,
+// independent of the configured log levels and in addition to the wrapped slog.Handler. Records are appended if the
+// file already exists. Capturing stops after d amount of time has elapsed.
+// The package is given by its name or by the trailing part of its path (e.g. "pkg/db").
+func (h *Handler) CaptureToFile(pkg, path string, d time.Duration) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	remove := h.taps.add(&tap{pkg: pkg, level: slog.LevelDebug, h: slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})})
+	h.logger.Debug(fmt.Sprintf("started capturing package=%q to file=%q for %s", pkg, path, d))
+
+	time.AfterFunc(d, func() {
+		remove()
+		if err := f.Close(); err != nil {
+			h.logger.Debug(fmt.Sprintf("error closing capture file (%s): %s", path, err.Error()))
+		}
+		h.logger.Debug(fmt.Sprintf("stopped capturing package=%q to file=%q", pkg, path))
+	})
+	return nil
+}
+
+// DebugSnapshot captures all records of all packages at DEBUG level for d amount of time and writes them
+// gzip-compressed in JSON format to w. It blocks until the snapshot is complete. Like CaptureToFile, the snapshot
+// is independent of the configured log levels, so the wrapped slog.Handler is not flooded with DEBUG records.
+// The first record of the snapshot contains the effective worker settings (see Tuning).
+func (h *Handler) DebugSnapshot(d time.Duration, w io.Writer) error {
+	return h.debugSnapshot(context.Background(), d, w)
+}
+
+// debugSnapshot is DebugSnapshot, which stops early if ctx is done.
+func (h *Handler) debugSnapshot(ctx context.Context, d time.Duration, w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	jh := slog.NewJSONHandler(zw, &slog.HandlerOptions{Level: slog.LevelDebug})
+	header := slog.NewRecord(time.Now(), slog.LevelInfo, "slogscope debug snapshot", 0)
+	header.AddAttrs(slog.Any("tuning", h.Tuning()), slog.Duration("duration", d))
+	if err := jh.Handle(ctx, header); err != nil {
+		return err
+	}
+	remove := h.taps.add(&tap{level: slog.LevelDebug, h: jh})
+	h.logger.Debug(fmt.Sprintf("started debug snapshot for %s", d))
+
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+	remove()
+	h.logger.Debug("stopped debug snapshot")
+	return zw.Close()
+}
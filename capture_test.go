@@ -0,0 +1,88 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CaptureToFile(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &newCfg})
+	l := slog.New(h)
+	file := filepath.Join(t.TempDir(), "capture.log")
+
+	assert.NoError(t, h.CaptureToFile("apperia-de/slogscope_test", file, 100*time.Millisecond))
+	l.Debug("captured message")
+	time.Sleep(150 * time.Millisecond)
+	l.Debug("ignored message")
+
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"captured message"`)
+	assert.NotContains(t, string(data), "ignored message")
+	// The wrapped handler must not receive DEBUG records, since the configured log level is ERROR.
+	assert.Empty(t, out.String())
+
+	t.Run("test invalid path", func(t *testing.T) {
+		assert.Error(t, h.CaptureToFile("apperia-de/slogscope_test", filepath.Join(t.TempDir(), "missing", "capture.log"), time.Second))
+	})
+}
+
+func TestHandler_DebugSnapshot(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &newCfg})
+	l := slog.New(h)
+
+	readSnapshot := func(r io.Reader) string {
+		zr, err := gzip.NewReader(r)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("test snapshot contains debug records", func(t *testing.T) {
+		var snapshot bytes.Buffer
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			l.Debug("snapshot message")
+		}()
+		assert.NoError(t, h.DebugSnapshot(100*time.Millisecond, &snapshot))
+		l.Debug("ignored message")
+
+		data := readSnapshot(&snapshot)
+		assert.Contains(t, data, `"msg":"snapshot message"`)
+		assert.NotContains(t, data, "ignored message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test snapshot endpoint", func(t *testing.T) {
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/snapshot?duration=50ms")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/gzip", resp.Header.Get("Content-Type"))
+		// Without records, the snapshot only contains the header with the worker settings.
+		lines := strings.Split(strings.TrimSpace(readSnapshot(resp.Body)), "\n")
+		assert.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `"msg":"slogscope debug snapshot","tuning":{"gomaxprocs":`)
+
+		resp, err = http.Get(srv.URL + "/snapshot?duration=1h")
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,199 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Environment variables used for sharing the Config with child processes (see Handler.StartCommand).
+const (
+	envConfig   = "SLOGSCOPE_CONFIG"    // JSON snapshot of the Config at the time the child process was started.
+	envConfigFD = "SLOGSCOPE_CONFIG_FD" // File descriptor of the pipe the child process receives config changes from.
+)
+
+// children contains the config pipes of all child processes started via Handler.StartCommand.
+type children struct {
+	mu     sync.Mutex
+	pipes  []*childPipe
+	closed bool // Set by close, configs are no longer shared with child processes afterwards.
+}
+
+// childPipe is the write end of the config pipe of a child process. A dedicated goroutine writes the configs, so a
+// child process, which stops reading, doesn't block config changes of the parent process.
+type childPipe struct {
+	w      *os.File
+	latest chan []byte // Latest config not yet written to the pipe.
+}
+
+// StartCommand starts cmd like cmd.Start() and shares the effective Config of the Handler (see EffectiveConfig) with
+// the child process, so the child process uses the same log levels, e.g. including HandlerOptions.Verbosity and the
+// rollout selected for the parent process. The child process inherits a snapshot of the Config via the environment and
+// receives every further config change via a pipe until the Handler is closed, so verbosity changes apply across the
+// whole process tree.
+// A Handler created by NewHandler within the child process picks up the shared Config automatically,
+// as long as no HandlerOptions.Config is given. On Windows, where os/exec doesn't support cmd.ExtraFiles, the child
+// process only inherits the snapshot and doesn't receive further config changes.
+// It is not available with the build tag slogscope_minimal.
+func (h *Handler) StartCommand(cmd *exec.Cmd) error {
+	data, err := json.Marshal(h.EffectiveConfig())
+	if err != nil {
+		return err
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, envConfig+"="+string(data))
+	if runtime.GOOS == "windows" {
+		if err = cmd.Start(); err != nil {
+			return err
+		}
+		h.logger.Debug(fmt.Sprintf("shared config snapshot with child process (pid=%d)", cmd.Process.Pid))
+		return nil
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	// File descriptors 0-2 are stdin, stdout and stderr, followed by the entries of cmd.ExtraFiles.
+	cmd.Env = append(cmd.Env, envConfigFD+"="+strconv.Itoa(3+len(cmd.ExtraFiles)))
+	cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+
+	if err = cmd.Start(); err != nil {
+		_ = r.Close()
+		_ = w.Close()
+		return err
+	}
+	// The read end is only needed by the child process.
+	_ = r.Close()
+
+	h.children.add(w)
+	h.logger.Debug(fmt.Sprintf("sharing config with child process (pid=%d)", cmd.Process.Pid))
+	return nil
+}
+
+// add starts writing configs to the pipe of a child process.
+func (c *children) add(w *os.File) {
+	p := &childPipe{w: w, latest: make(chan []byte, 1)}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		_ = w.Close()
+		return
+	}
+	c.pipes = append(c.pipes, p)
+	c.mu.Unlock()
+
+	go func() {
+		// The child process receives EOF as soon as the pipe is closed.
+		defer w.Close()
+		for data := range p.latest {
+			if _, err := w.Write(data); err != nil {
+				// The child process has exited.
+				c.mu.Lock()
+				c.pipes = slices.DeleteFunc(c.pipes, func(other *childPipe) bool { return other == p })
+				c.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// close stops sharing configs with all child processes and closes their pipes.
+func (c *children) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for _, p := range c.pipes {
+		close(p.latest)
+		// Closing the write end also unblocks a write to a child process, which doesn't read the pipe.
+		_ = p.w.Close()
+	}
+	c.pipes = nil
+}
+
+// broadcast sends the config returned by effective to all child processes without blocking. A config not yet written
+// to a child process is replaced, so a slow child process only receives the latest config.
+func (c *children) broadcast(effective func() Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pipes) == 0 {
+		return
+	}
+	data, err := json.Marshal(effective())
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	for _, p := range c.pipes {
+		for sent := false; !sent; {
+			select {
+			case p.latest <- data:
+				sent = true
+			default:
+				// Drop the pending config in favor of the latest one.
+				select {
+				case <-p.latest:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// inheritConfig uses the Config shared by a parent process, if present, and returns the pipe for receiving
+// further config changes. The environment variables are removed, so they are not inherited by grandchildren
+// unintentionally.
+func (ss *slogscope) inheritConfig() *os.File {
+	data, ok := os.LookupEnv(envConfig)
+	if !ok {
+		return nil
+	}
+	fd, fdErr := strconv.Atoi(os.Getenv(envConfigFD))
+	_ = os.Unsetenv(envConfig)
+	_ = os.Unsetenv(envConfigFD)
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		ss.logger.Debug(fmt.Sprintf("error unmarshalling inherited config: %s", err.Error()))
+		return nil
+	}
+	ss.opts.Config = &cfg
+	ss.prov = provenance{source: "parent process"}
+	ss.logger.Debug("using config inherited from parent process")
+
+	if fdErr != nil {
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "slogscope-config")
+}
+
+// followConfig applies every config received from the parent process until the pipe is closed.
+func (h *Handler) followConfig(r *os.File) {
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var cfg Config
+		if err := json.Unmarshal(scanner.Bytes(), &cfg); err != nil {
+			h.logger.Debug(fmt.Sprintf("error unmarshalling config received from parent process: %s", err.Error()))
+			continue
+		}
+		h.useConfig(cfg, provenance{source: "parent process"})
+	}
+}
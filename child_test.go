@@ -0,0 +1,185 @@
+//go:build !slogscope_minimal
+
+package slogscope_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperChildProcess is not a real test, but the child process started by TestHandler_StartCommand.
+// It prints the inherited log level and waits until the log level changes to ERROR.
+func TestHelperChildProcess(t *testing.T) {
+	if os.Getenv("SLOGSCOPE_HELPER_PROCESS") != "1" {
+		t.Skip("helper process for TestHandler_StartCommand")
+	}
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), nil)
+	fmt.Printf("config: %s\n", h.GetConfig().LogLevel)
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if lvl := h.GetConfig().LogLevel; lvl == slogscope.LogLevelError {
+			fmt.Printf("config: %s\n", lvl)
+			return
+		}
+	}
+	t.Fatal("config change not received")
+}
+
+func TestHandler_StartCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("config changes are not shared with child processes on Windows")
+	}
+	h := setupHandlerWithConfig(oldCfg)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperChildProcess$")
+	cmd.Env = append(os.Environ(), "SLOGSCOPE_HELPER_PROCESS=1")
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, h.StartCommand(cmd))
+
+	var levels []string
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if lvl, ok := strings.CutPrefix(scanner.Text(), "config: "); ok {
+			levels = append(levels, lvl)
+			if len(levels) == 1 {
+				// The child process has inherited the initial config, now change it.
+				h.UseConfig(newCfg)
+			}
+		}
+	}
+	assert.NoError(t, cmd.Wait())
+	assert.Equal(t, []string{slogscope.LogLevelDebug, slogscope.LogLevelError}, levels)
+}
+
+// TestHelperIdleChildProcess is not a real test, but the child process started by
+// TestHandler_StartCommandIdleChild. It never reads the config pipe.
+func TestHelperIdleChildProcess(t *testing.T) {
+	if os.Getenv("SLOGSCOPE_HELPER_PROCESS") != "idle" {
+		t.Skip("helper process for TestHandler_StartCommandIdleChild")
+	}
+	time.Sleep(10 * time.Second)
+}
+
+func TestHandler_StartCommandIdleChild(t *testing.T) {
+	h := setupHandlerWithConfig(oldCfg)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperIdleChildProcess$")
+	cmd.Env = append(os.Environ(), "SLOGSCOPE_HELPER_PROCESS=idle")
+	assert.NoError(t, h.StartCommand(cmd))
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	// Configs exceeding the pipe buffer block writes to the pipe, as the child process doesn't read them.
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo}
+	for i := range 5000 {
+		cfg.Packages = append(cfg.Packages, slogscope.Package{Name: fmt.Sprintf("github.com/foo/bar%d", i), LogLevel: slogscope.LogLevelDebug})
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 5 {
+			h.UseConfig(cfg)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config changes are blocked by the child process")
+	}
+	assert.Len(t, h.GetConfig().Packages, 5000)
+}
+
+// TestHelperEffectiveChildProcess is not a real test, but the child process started by
+// TestHandler_StartCommandEffectiveConfig. It prints the inherited config and the first config change.
+func TestHelperEffectiveChildProcess(t *testing.T) {
+	if os.Getenv("SLOGSCOPE_HELPER_PROCESS") != "effective" {
+		t.Skip("helper process for TestHandler_StartCommandEffectiveConfig")
+	}
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), nil)
+	inherited := h.GetConfig()
+	data, _ := json.Marshal(inherited)
+	fmt.Printf("config: %s\n", data)
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cfg := h.GetConfig(); cfg.LogLevel != inherited.LogLevel {
+			data, _ = json.Marshal(cfg)
+			fmt.Printf("config: %s\n", data)
+			return
+		}
+	}
+	t.Fatal("config change not received")
+}
+
+func TestHandler_StartCommandEffectiveConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("config changes are not shared with child processes on Windows")
+	}
+	rollout := &slogscope.Rollout{Percent: 100, Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn}}}
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Config:    &slogscope.Config{LogLevel: slogscope.LogLevelInfo, Rollout: rollout},
+		Verbosity: 1,
+	})
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperEffectiveChildProcess$")
+	cmd.Env = append(os.Environ(), "SLOGSCOPE_HELPER_PROCESS=effective")
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, h.StartCommand(cmd))
+
+	var configs []slogscope.Config
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "config: "); ok {
+			var cfg slogscope.Config
+			assert.NoError(t, json.Unmarshal([]byte(data), &cfg))
+			configs = append(configs, cfg)
+			if len(configs) == 1 {
+				h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelWarn, Rollout: rollout})
+			}
+		}
+	}
+	assert.NoError(t, cmd.Wait())
+
+	// The child processes receive the levels resolved by the parent process, not the rollout or verbosity.
+	packages := []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn}}
+	assert.Equal(t, []slogscope.Config{
+		{LogLevel: slogscope.LogLevelDebug, Packages: packages},
+		{LogLevel: slogscope.LogLevelInfo, Packages: packages},
+	}, configs)
+}
+
+func TestHandler_StartCommandClose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("config changes are not shared with child processes on Windows")
+	}
+	h := setupHandlerWithConfig(oldCfg)
+	before := runtime.NumGoroutine()
+
+	for range 3 {
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		assert.NoError(t, h.StartCommand(cmd))
+		assert.NoError(t, cmd.Wait())
+	}
+	assert.Equal(t, before+3, runtime.NumGoroutine())
+
+	// Closing the Handler stops the goroutines writing to the pipes of the exited child processes.
+	// assert.Eventually is not used, as it runs the condition in goroutines of its own.
+	assert.NoError(t, h.Close())
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, before, runtime.NumGoroutine())
+}
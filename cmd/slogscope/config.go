@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// runConfig prints the current config of a running service.
+func runConfig(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	format := fs.String("f", "yaml", "output format (yaml, json, toml or env)")
+	_ = fs.Parse(args)
+
+	data, err := getConfig(baseURL, *format)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// runImport fetches the current config from one instance and applies it to one or more other instances.
+// If no target URLs are given as arguments, the config is applied to the instance given via -u.
+func runImport(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	from := fs.String("from", "", "URL of the slogscope admin endpoints of the instance to import the config from")
+	_ = fs.Parse(args)
+
+	if *from == "" {
+		return fmt.Errorf("missing -from")
+	}
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{baseURL}
+	}
+
+	data, err := getConfig(*from, "json")
+	if err != nil {
+		return fmt.Errorf("%s: %w", *from, err)
+	}
+	for _, target := range targets {
+		if err = putConfig(target, data); err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+		fmt.Printf("applied config of %s to %s\n", *from, target)
+	}
+	return nil
+}
+
+// getConfig returns the config of the instance in the given format.
+func getConfig(baseURL, format string) ([]byte, error) {
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/config?format=" + url.QueryEscape(format))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
+
+// putConfig applies the JSON encoded config to the instance.
+func putConfig(baseURL string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, strings.TrimSuffix(baseURL, "/")+"/config", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
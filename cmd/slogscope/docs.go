@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// runDocs prints or writes the documentation of the current config of a running service.
+func runDocs(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("docs", flag.ExitOnError)
+	format := fs.String("f", "markdown", "output format (markdown or html)")
+	out := fs.String("o", "", "output file (default: stdout)")
+	_ = fs.Parse(args)
+
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/docs?format=" + url.QueryEscape(*format))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if *out == "" {
+		_, err = io.Copy(os.Stdout, resp.Body)
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// historyEntry mirrors slogscope.HistoryEntry as returned by the GET /history endpoint.
+type historyEntry struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	Config struct {
+		LogLevel string `json:"log_level"`
+		Packages []any  `json:"packages"`
+	} `json:"config"`
+}
+
+// runHistory lists the recently applied configs of a running service.
+func runHistory(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/history")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var history []historyEntry
+	if err = json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "N\tAPPLIED\tLOG LEVEL\tPACKAGES\tSOURCE")
+	for i, e := range history {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n", i, e.Time.Format(time.DateTime), e.Config.LogLevel, len(e.Config.Packages), e.Source)
+	}
+	return tw.Flush()
+}
+
+// runRollback reapplies a previous config of a running service.
+func runRollback(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	n := fs.Int("n", 1, "number of the history entry to reapply (see history)")
+	_ = fs.Parse(args)
+
+	resp, err := http.Post(fmt.Sprintf("%s/rollback?n=%d", strings.TrimSuffix(baseURL, "/"), *n), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	fmt.Printf("rolled back to history entry %d\n", *n)
+	return nil
+}
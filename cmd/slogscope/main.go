@@ -0,0 +1,73 @@
+// Command slogscope is a client for the admin endpoints of a running service using slogscope.Handler.AdminHandler().
+//
+// Usage:
+//
+//	slogscope [-u URL] <command> [flags]
+//
+// The URL of the admin endpoints defaults to the environment variable SLOGSCOPE_URL or, if not set,
+// to http://localhost:8080/debug/slogscope.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const defaultURL = "http://localhost:8080/debug/slogscope"
+
+// command is a subcommand of the slogscope CLI.
+type command struct {
+	usage string
+	run   func(baseURL string, args []string) error
+}
+
+var commands = map[string]command{
+	"config":      {usage: "Print the current config of a running service", run: runConfig},
+	"docs":        {usage: "Print the documentation of the config of a running service (-f markdown|html -o file)", run: runDocs},
+	"history":     {usage: "List the recently applied configs of a running service", run: runHistory},
+	"import":      {usage: "Apply the config of another running instance (-from URL [target URLs...])", run: runImport},
+	"maintenance": {usage: "Show or toggle the maintenance mode of a running service ([on|off])", run: runMaintenance},
+	"rollback":    {usage: "Reapply a previous config of a running service (-n entry)", run: runRollback},
+	"selftest":    {usage: "Verify the logging pipeline of a running service, fails if it doesn't pass", run: runSelfTest},
+	"snapshot":    {usage: "Download a compressed debug snapshot of all packages (-d duration -o file)", run: runSnapshot},
+	"tail":        {usage: "Stream records of a running service", run: runTail},
+	"top":         {usage: "Show observed packages and change their log levels interactively", run: runTop},
+}
+
+func main() {
+	fs := flag.NewFlagSet("slogscope", flag.ExitOnError)
+	baseURL := fs.String("u", envOrDefault("SLOGSCOPE_URL", defaultURL), "URL of the slogscope admin endpoints")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: slogscope [-u URL] <command> [flags]\n\nCommands:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(fs.Output(), "  %-12s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintf(fs.Output(), "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(*baseURL, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "slogscope %s: %s\n", fs.Arg(0), err.Error())
+		os.Exit(1)
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// runMaintenance reports, activates (on) or deactivates (off) the maintenance mode of a running service.
+func runMaintenance(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("maintenance", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	url := strings.TrimSuffix(baseURL, "/") + "/maintenance"
+	var (
+		resp *http.Response
+		err  error
+	)
+	switch fs.Arg(0) {
+	case "":
+		resp, err = http.Get(url)
+	case "on", "off":
+		body, _ := json.Marshal(map[string]bool{"enabled": fs.Arg(0) == "on"})
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = http.DefaultClient.Do(req)
+	default:
+		return fmt.Errorf("invalid argument %q: expected on or off", fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var mode struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&mode); err != nil {
+		return err
+	}
+	if mode.Enabled {
+		fmt.Println("maintenance mode: on")
+	} else {
+		fmt.Println("maintenance mode: off")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// selfTestReport mirrors slogscope.SelfTestReport as returned by the POST /selftest endpoint.
+type selfTestReport struct {
+	ID       string        `json:"id"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration"`
+	Records  []struct {
+		Level  string   `json:"level"`
+		Logged bool     `json:"logged"`
+		Sinks  []string `json:"sinks"`
+		Error  string   `json:"error"`
+	} `json:"records"`
+	Sinks []struct {
+		Name    string `json:"name"`
+		Checked bool   `json:"checked"`
+		Error   string `json:"error"`
+	} `json:"sinks"`
+}
+
+// runSelfTest runs the self-test of a running service and fails, if it doesn't pass, e.g. in deployment smoke tests.
+func runSelfTest(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	resp, err := http.Post(strings.TrimSuffix(baseURL, "/")+"/selftest", "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var report selfTestReport
+	if err = json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LEVEL\tLOGGED\tSINKS\tERROR")
+	for _, r := range report.Records {
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%s\n", r.Level, r.Logged, strings.Join(r.Sinks, ","), r.Error)
+	}
+	if len(report.Sinks) > 0 {
+		fmt.Fprintln(tw, "\nSINK\tCHECKED\tERROR")
+		for _, s := range report.Sinks {
+			fmt.Fprintf(tw, "%s\t%t\t%s\n", s.Name, s.Checked, s.Error)
+		}
+	}
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+	if !report.Passed {
+		return fmt.Errorf("self-test %s failed", report.ID)
+	}
+	fmt.Printf("\nself-test %s passed in %s\n", report.ID, report.Duration)
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// runSnapshot downloads a gzip-compressed debug snapshot of all packages to a file.
+func runSnapshot(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	d := fs.Duration("d", 30*time.Second, "duration of the snapshot")
+	out := fs.String("o", "", "output file (default: slogscope-snapshot-<time>.jsonl.gz)")
+	_ = fs.Parse(args)
+
+	if *out == "" {
+		*out = fmt.Sprintf("slogscope-snapshot-%s.jsonl.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	q := url.Values{}
+	q.Set("duration", d.String())
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/snapshot?" + q.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote debug snapshot of %s to %s\n", d, *out)
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// runTail streams the records matching the given filter to stdout until the connection is closed.
+func runTail(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	pkg := fs.String("p", "", "package name or trailing part of the package path, e.g. pkg/db")
+	level := fs.String("l", "DEBUG", "minimum log level")
+	regex := fs.String("r", "", "regular expression the record message must match")
+	_ = fs.Parse(args)
+
+	q := url.Values{}
+	q.Set("package", *pkg)
+	q.Set("level", *level)
+	q.Set("regex", *regex)
+
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/tail?" + q.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			fmt.Fprintln(os.Stdout, data)
+		}
+	}
+	return scanner.Err()
+}
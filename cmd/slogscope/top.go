@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// packageInfo mirrors slogscope.PackageInfo as returned by the GET /packages endpoint.
+type packageInfo struct {
+	Name        string `json:"name"`
+	LogLevel    string `json:"log_level"`
+	Observed    uint64 `json:"observed"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+}
+
+// top is the state of the interactive terminal UI.
+type top struct {
+	baseURL  string
+	ttl      time.Duration
+	packages []packageInfo
+	rates    map[string]float64
+	selected int
+	status   string
+}
+
+// runTop starts an interactive terminal UI showing the observed packages, their log call rates and current log levels.
+// The log level of the selected package can be raised or lowered temporarily via keybindings.
+func runTop(baseURL string, args []string) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	ttl := fs.Duration("ttl", 5*time.Minute, "duration of temporary log level changes")
+	interval := fs.Duration("i", time.Second, "refresh interval")
+	_ = fs.Parse(args)
+
+	// Switch the terminal into raw mode, so single key presses can be read without waiting for a newline.
+	if err := stty("raw", "-echo"); err != nil {
+		return fmt.Errorf("unable to switch terminal into raw mode: %w", err)
+	}
+	defer func() {
+		_ = stty("-raw", "echo")
+		fmt.Print("\033[?25h\r\n")
+	}()
+	fmt.Print("\033[?25l")
+
+	t := &top{baseURL: strings.TrimSuffix(baseURL, "/"), ttl: *ttl, rates: map[string]float64{}}
+	keyCh := make(chan string)
+	go readKeys(keyCh)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	t.refresh(*interval)
+	for {
+		t.render()
+		select {
+		case key, ok := <-keyCh:
+			if !ok || !t.handleKey(key) {
+				return nil
+			}
+		case <-ticker.C:
+			t.refresh(*interval)
+		}
+	}
+}
+
+// refresh fetches the current package list and computes the log call rates since the last refresh.
+func (t *top) refresh(interval time.Duration) {
+	resp, err := http.Get(t.baseURL + "/packages")
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	var packages []packageInfo
+	if err = json.NewDecoder(resp.Body).Decode(&packages); err != nil {
+		t.status = err.Error()
+		return
+	}
+
+	prev := map[string]uint64{}
+	for _, p := range t.packages {
+		prev[p.Name] = p.Observed
+	}
+	for _, p := range packages {
+		if n, ok := prev[p.Name]; ok {
+			t.rates[p.Name] = float64(p.Observed-n) / interval.Seconds()
+		}
+	}
+	t.packages = packages
+	t.selected = min(t.selected, max(len(packages)-1, 0))
+}
+
+// handleKey processes a key press and reports whether the UI should keep running.
+func (t *top) handleKey(key string) bool {
+	switch key {
+	case "q", "\x03":
+		return false
+	case "k", "\033[A":
+		t.selected = max(t.selected-1, 0)
+	case "j", "\033[B":
+		t.selected = min(t.selected+1, max(len(t.packages)-1, 0))
+	case "+":
+		t.bump(-4)
+	case "-":
+		t.bump(4)
+	}
+	return true
+}
+
+// bump temporarily changes the log level of the selected package by delta.
+// A negative delta makes the package more verbose, e.g. -4 changes INFO to DEBUG.
+func (t *top) bump(delta int) {
+	if len(t.packages) == 0 {
+		return
+	}
+	p := t.packages[t.selected]
+
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(p.LogLevel)); err != nil {
+		t.status = err.Error()
+		return
+	}
+	lvl = slog.Level(int(lvl) + delta)
+
+	body, _ := json.Marshal(map[string]string{"package": p.Name, "log_level": lvl.String(), "ttl": t.ttl.String()})
+	resp, err := http.Post(t.baseURL+"/overrides", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.status = fmt.Sprintf("unexpected response: %s", resp.Status)
+		return
+	}
+	t.packages[t.selected].LogLevel = lvl.String()
+	t.status = fmt.Sprintf("set %s to %s for %s", p.Name, lvl, t.ttl)
+}
+
+// render draws the complete UI. Lines are terminated with \r\n, since the terminal is in raw mode.
+func (t *top) render() {
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "slogscope - %s\r\n\r\n", t.baseURL)
+	fmt.Fprintf(&b, "\033[7m  %-70s %-10s %10s\033[0m\r\n", "PACKAGE", "LEVEL", "CALLS/s")
+	for i, p := range t.packages {
+		cursor := " "
+		if i == t.selected {
+			cursor = ">"
+		}
+		fmt.Fprintf(&b, "%s %-70s %-10s %10.1f\r\n", cursor, p.Name, p.LogLevel, t.rates[p.Name])
+	}
+	if len(t.packages) > 0 {
+		if p := t.packages[t.selected]; p.Description != "" {
+			fmt.Fprintf(&b, "\r\n%s: %s\r\n", p.Name, p.Description)
+		}
+		if p := t.packages[t.selected]; p.Source != "" {
+			fmt.Fprintf(&b, "\r\nsource: %s\r\n", p.Source)
+		}
+	}
+	fmt.Fprintf(&b, "\r\n[j/k] select  [+] more verbose  [-] less verbose (for %s)  [q] quit\r\n", t.ttl)
+	if t.status != "" {
+		fmt.Fprintf(&b, "%s\r\n", t.status)
+	}
+	fmt.Print(b.String())
+}
+
+// readKeys sends every key press read from stdin to the channel. Escape sequences (e.g. arrow keys) are sent as one key.
+func readKeys(keyCh chan<- string) {
+	buf := make([]byte, 8)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keyCh)
+			return
+		}
+		keyCh <- string(buf[:n])
+	}
+}
+
+// stty runs the stty command for the terminal connected to stdin.
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,35 @@
+package slogscope
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EnterCommand applies the package rules declared by a subcommand of a CLI on top of the current config, e.g. DEBUG
+// for the database packages of a migrate command. The returned function reverts to the state before and must be
+// called when the subcommand has finished (see CommandFunc).
+func (h *Handler) EnterCommand(name string, packages ...Package) (exit func()) {
+	_, _, exit = h.applyTemporarily(&temporaryConfig{}, func(cfg Config, prov provenance) (Config, provenance) {
+		cfg.Packages = mergePackages(slices.Clone(cfg.Packages), packages)
+		for _, p := range packages {
+			prov = prov.with(p.Name, fmt.Sprintf("command %s", name))
+		}
+		return cfg, prov
+	})
+	return exit
+}
+
+// CommandFunc wraps the run function of a CLI subcommand, so the given package rules apply while it executes and
+// are reverted afterward (see Handler.EnterCommand). The signature matches cobra.Command.RunE without depending on
+// cobra, e.g.:
+//
+//	migrateCmd.RunE = slogscope.CommandFunc(h, "migrate", []slogscope.Package{
+//		{Name: "github.com/foo/bar/db", LogLevel: slogscope.LogLevelDebug},
+//	}, runMigrate)
+func CommandFunc[C any](h *Handler, name string, packages []Package, run func(cmd C, args []string) error) func(cmd C, args []string) error {
+	return func(cmd C, args []string) error {
+		exit := h.EnterCommand(name, packages...)
+		defer exit()
+		return run(cmd, args)
+	}
+}
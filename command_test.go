@@ -0,0 +1,38 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// command is a stand-in for cobra.Command.
+type command struct{ name string }
+
+func TestCommandFunc(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelError}
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{Config: &cfg})
+	l := slog.New(h)
+
+	run := slogscope.CommandFunc(h, "migrate", []slogscope.Package{
+		{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug},
+	}, func(cmd *command, args []string) error {
+		l.Debug("running " + cmd.name)
+		assert.Equal(t, "command migrate", h.ExplainDecision("github.com/apperia-de/slogscope_test", slog.LevelDebug).Source)
+		return errors.New("migration failed")
+	})
+
+	err := run(&command{name: "migrate"}, nil)
+	assert.EqualError(t, err, "migration failed")
+	assert.Contains(t, buf.String(), "running migrate")
+
+	buf.Reset()
+	l.Debug("after migrate")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, cfg, h.GetConfig())
+}
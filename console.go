@@ -0,0 +1,150 @@
+package slogscope
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// packageColors contains the ANSI 256 colors used for tagging console output by package.
+var packageColors = []int{33, 39, 45, 63, 70, 76, 99, 112, 136, 141, 166, 172, 178, 203, 207, 213}
+
+// ConsoleHandlerOptions are options for a console handler created by NewConsoleHandler.
+type ConsoleHandlerOptions struct {
+	Level      slog.Leveler // Minimum log level, if the handler is used without slogscope (default: INFO).
+	NoColor    bool         // Disables ANSI colors, e.g. if the output is not a terminal.
+	TimeFormat string       // Time layout of the record time (default: "15:04:05.000").
+}
+
+// consoleHandler is a human friendly slog.Handler for development, which tags every line with the package it was
+// logged from. The package tag is colored by a stable hash of the package name, so interleaved output of
+// different subsystems is visually separable.
+type consoleHandler struct {
+	opts   ConsoleHandlerOptions
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  string // Preformatted attributes added via WithAttrs.
+	prefix string // Group prefix for attribute keys added via WithGroup.
+}
+
+// NewConsoleHandler creates a new slog.Handler for human friendly development console output.
+func NewConsoleHandler(w io.Writer, opts *ConsoleHandlerOptions) slog.Handler {
+	h := &consoleHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	if h.opts.TimeFormat == "" {
+		h.opts.TimeFormat = "15:04:05.000"
+	}
+	return h
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.opts.Level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, rec slog.Record) error {
+	var b strings.Builder
+	if !rec.Time.IsZero() {
+		b.WriteString(rec.Time.Format(h.opts.TimeFormat))
+		b.WriteByte(' ')
+	}
+	b.WriteString(h.colorize(fmt.Sprintf("%-5s", rec.Level.String()), levelColor(rec.Level)))
+	if pkgName := getPackageName(rec.PC); pkgName != "" {
+		b.WriteByte(' ')
+		b.WriteString(h.colorize("["+pkgName+"]", "38;5;"+strconv.Itoa(packageColor(pkgName))))
+	}
+	b.WriteByte(' ')
+	b.WriteString(rec.Message)
+	b.WriteString(h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		h.appendAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr appends the attribute as " key=value" to b. Groups are flattened using dotted keys.
+func (h *consoleHandler) appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	val := a.Value.String()
+	if val == "" || strings.ContainsAny(val, " \t\n\"=") {
+		val = strconv.Quote(val)
+	}
+	b.WriteByte(' ')
+	b.WriteString(h.colorize(prefix+a.Key+"=", "2"))
+	b.WriteString(val)
+}
+
+// colorize wraps s into the given ANSI color sequence, unless colors are disabled.
+func (h *consoleHandler) colorize(s string, color string) string {
+	if h.opts.NoColor {
+		return s
+	}
+	return "\033[" + color + "m" + s + "\033[0m"
+}
+
+// levelColor returns the ANSI color for the given log level.
+func levelColor(lvl slog.Level) string {
+	switch {
+	case lvl >= slog.LevelError:
+		return "31"
+	case lvl >= slog.LevelWarn:
+		return "33"
+	case lvl >= slog.LevelInfo:
+		return "32"
+	}
+	return "90"
+}
+
+// packageColor returns a stable ANSI 256 color for the package name.
+func packageColor(pkgName string) int {
+	f := fnv.New32a()
+	_, _ = f.Write([]byte(pkgName))
+	return packageColors[f.Sum32()%uint32(len(packageColors))]
+}
@@ -0,0 +1,44 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConsoleHandler(t *testing.T) {
+	t.Run("test output is tagged by package", func(t *testing.T) {
+		var out bytes.Buffer
+		l := slog.New(slogscope.NewConsoleHandler(&out, &slogscope.ConsoleHandlerOptions{NoColor: true, TimeFormat: "-"}))
+		l.With("user", "john doe").WithGroup("req").Info("request handled", "status", 200)
+
+		assert.Equal(t, "- INFO  [github.com/apperia-de/slogscope_test] request handled user=\"john doe\" req.status=200\n", out.String())
+	})
+
+	t.Run("test package color is stable", func(t *testing.T) {
+		var out bytes.Buffer
+		l := slog.New(slogscope.NewConsoleHandler(&out, nil))
+		l.Info("first message")
+		l.Warn("second message")
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 2)
+		tag := lines[0][strings.Index(lines[0], "\033[38;5;"):strings.Index(lines[0], "]")]
+		assert.Contains(t, lines[1], tag)
+	})
+
+	t.Run("test console handler wrapped by slogscope", func(t *testing.T) {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slogscope.NewConsoleHandler(&out, &slogscope.ConsoleHandlerOptions{NoColor: true}), &slogscope.HandlerOptions{Config: &newCfg})
+		l := slog.New(h)
+		l.Info("info message")
+		l.Error("error message")
+
+		assert.NotContains(t, out.String(), "info message")
+		assert.Contains(t, out.String(), "ERROR [github.com/apperia-de/slogscope_test] error message")
+	})
+}
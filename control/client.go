@@ -0,0 +1,94 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	slogscopepb "github.com/apperia-de/slogscope/proto"
+	"google.golang.org/grpc"
+)
+
+// Packages is the result of Client.GetPackages.
+type Packages struct {
+	LogLevel string                  // Global log level of the effective config.
+	Packages []slogscope.PackageInfo // Configured and observed packages (see slogscope.Handler.GetPackages).
+}
+
+// ConfigChange is a config streamed by Client.WatchConfig.
+type ConfigChange struct {
+	Config []byte    // Effective config encoded in Format.
+	Format string    // Format of the config, e.g. slogscope.FormatJSON.
+	Source string    // Source of the applied config, e.g. "file slogscope.yml" or "UseConfig".
+	Time   time.Time // Time the config was applied, zero if unknown.
+}
+
+// Client calls the ControlService of a remote service.
+type Client struct {
+	client slogscopepb.ControlServiceClient
+}
+
+// NewClient returns a Client calling the ControlService via the given connection, e.g. created by grpc.NewClient.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{client: slogscopepb.NewControlServiceClient(cc)}
+}
+
+// GetPackages returns the global log level and all configured and observed packages of the service.
+func (c *Client) GetPackages(ctx context.Context) (Packages, error) {
+	resp, err := c.client.GetPackages(ctx, &slogscopepb.GetPackagesRequest{})
+	if err != nil {
+		return Packages{}, err
+	}
+	res := Packages{LogLevel: resp.GetLogLevel()}
+	for _, p := range resp.GetPackages() {
+		res.Packages = append(res.Packages, fromPackageInfo(p))
+	}
+	return res, nil
+}
+
+// SetPackageLevel sets the log level of a package of the service and returns its state afterward. If ttl is greater
+// than zero, the previous config is restored after ttl (see slogscope.Handler.UsePackageLevelTemporarily).
+func (c *Client) SetPackageLevel(ctx context.Context, pkg, level string, ttl time.Duration) (slogscope.PackageInfo, error) {
+	req := &slogscopepb.SetPackageLevelRequest{Package: pkg, LogLevel: level}
+	if ttl > 0 {
+		req.Ttl = ttl.String()
+	}
+	resp, err := c.client.SetPackageLevel(ctx, req)
+	if err != nil {
+		return slogscope.PackageInfo{}, err
+	}
+	return fromPackageInfo(resp), nil
+}
+
+// WatchConfig sends the effective config of the service in the given format (default: json) to ch, followed by the
+// effective config after every change, until ctx is canceled or the stream fails.
+func (c *Client) WatchConfig(ctx context.Context, format string, ch chan<- ConfigChange) error {
+	stream, err := c.client.WatchConfig(ctx, &slogscopepb.WatchConfigRequest{Format: format})
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, io.EOF) {
+			return errors.New("stream closed by server")
+		}
+		if err != nil {
+			return err
+		}
+
+		change := ConfigChange{Config: msg.GetConfig(), Format: msg.GetFormat(), Source: msg.GetSource()}
+		if msg.GetTimeUnixNano() != 0 {
+			change.Time = time.Unix(0, msg.GetTimeUnixNano())
+		}
+		select {
+		case ch <- change:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package control provides the gRPC ControlService defined in proto/control.proto, which lets operators query and
+// change the log levels of a running service remotely and streams every config change to its clients.
+//
+// Register the service next to the services of an existing grpc.Server, e.g.:
+//
+//	srv := grpc.NewServer()
+//	control.Register(srv, handler)
+//	lis, err := net.Listen("tcp", ":8443")
+//	// ...
+//	log.Fatal(srv.Serve(lis))
+package control
+
+import (
+	"context"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	slogscopepb "github.com/apperia-de/slogscope/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchBufferSize is the number of config changes buffered per WatchConfig stream before changes are dropped.
+const watchBufferSize = 16
+
+// Server implements the ControlService for a slogscope.Handler.
+type Server struct {
+	slogscopepb.UnimplementedControlServiceServer
+	h *slogscope.Handler
+}
+
+// NewServer returns a Server controlling the given Handler.
+func NewServer(h *slogscope.Handler) *Server {
+	return &Server{h: h}
+}
+
+// Register registers a Server controlling the given Handler with the grpc.Server s.
+func Register(s grpc.ServiceRegistrar, h *slogscope.Handler) {
+	slogscopepb.RegisterControlServiceServer(s, NewServer(h))
+}
+
+// GetPackages returns the global log level and all configured and observed packages.
+func (s *Server) GetPackages(context.Context, *slogscopepb.GetPackagesRequest) (*slogscopepb.GetPackagesResponse, error) {
+	res := &slogscopepb.GetPackagesResponse{LogLevel: s.h.EffectiveConfig().LogLevel}
+	for _, p := range s.h.GetPackages() {
+		res.Packages = append(res.Packages, toPackageInfo(p))
+	}
+	return res, nil
+}
+
+// SetPackageLevel sets the log level of a package, permanently or for the ttl of the request.
+func (s *Server) SetPackageLevel(_ context.Context, req *slogscopepb.SetPackageLevelRequest) (*slogscopepb.PackageInfo, error) {
+	name, level := req.GetPackage(), req.GetLogLevel()
+	if name == "" || level == "" {
+		return nil, status.Error(codes.InvalidArgument, "package and log_level are required")
+	}
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Packages: []slogscope.Package{{Name: name, LogLevel: level}}}
+	if err := cfg.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if ttl := req.GetTtl(); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ttl: %q", ttl)
+		}
+		s.h.UsePackageLevelTemporarily(name, level, d)
+	} else {
+		s.h.SetPackageLevel(name, level)
+	}
+
+	info := slogscope.PackageInfo{Name: name, LogLevel: level}
+	for _, p := range s.h.GetPackages() {
+		if p.Name == name {
+			info = p
+			break
+		}
+	}
+	return toPackageInfo(info), nil
+}
+
+// WatchConfig streams the effective config as ConfigChange followed by the effective config after every change,
+// until the client cancels the call.
+func (s *Server) WatchConfig(req *slogscopepb.WatchConfigRequest, stream grpc.ServerStreamingServer[slogscopepb.ConfigChange]) error {
+	format := req.GetFormat()
+	if format == "" {
+		format = slogscope.FormatJSON
+	}
+	if _, err := s.h.ExportConfig(format); err != nil || format == slogscope.FormatEnv {
+		return status.Errorf(codes.InvalidArgument, "unsupported config format: %q", format)
+	}
+
+	ch := make(chan slogscope.HistoryEntry, watchBufferSize)
+	stop := s.h.WatchConfig(ch)
+	defer stop()
+
+	send := func(entry slogscope.HistoryEntry) error {
+		data, err := s.h.ExportConfig(format)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		change := &slogscopepb.ConfigChange{Config: data, Format: format, Source: entry.Source}
+		if !entry.Time.IsZero() {
+			change.TimeUnixNano = entry.Time.UnixNano()
+		}
+		return stream.Send(change)
+	}
+
+	var current slogscope.HistoryEntry
+	if history := s.h.History(); len(history) > 0 {
+		current = history[0]
+	}
+	if err := send(current); err != nil {
+		return err
+	}
+	for {
+		select {
+		case entry := <-ch:
+			if err := send(entry); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// toPackageInfo converts the PackageInfo to its message.
+func toPackageInfo(p slogscope.PackageInfo) *slogscopepb.PackageInfo {
+	return &slogscopepb.PackageInfo{
+		Name:        p.Name,
+		LogLevel:    p.LogLevel,
+		Observed:    p.Observed,
+		Description: p.Description,
+		Source:      p.Source,
+	}
+}
+
+// fromPackageInfo converts the message to a PackageInfo.
+func fromPackageInfo(p *slogscopepb.PackageInfo) slogscope.PackageInfo {
+	return slogscope.PackageInfo{
+		Name:        p.GetName(),
+		LogLevel:    p.GetLogLevel(),
+		Observed:    p.GetObserved(),
+		Description: p.GetDescription(),
+		Source:      p.GetSource(),
+	}
+}
@@ -0,0 +1,99 @@
+package control_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/control"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestControlService(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn, Description: "noisy"}},
+		},
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	control.Register(srv, h)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	c := control.NewClient(conn)
+	ctx := context.Background()
+
+	t.Run("test get packages", func(t *testing.T) {
+		res, err := c.GetPackages(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, slogscope.LogLevelInfo, res.LogLevel)
+		assert.Equal(t, []slogscope.PackageInfo{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn, Description: "noisy", Source: "HandlerOptions.Config"},
+		}, res.Packages)
+	})
+
+	t.Run("test set package level", func(t *testing.T) {
+		info, err := c.SetPackageLevel(ctx, "github.com/foo/bar", slogscope.LogLevelDebug, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, slogscope.LogLevelDebug, info.LogLevel)
+		assert.Equal(t, "SetPackageLevel", info.Source)
+
+		info, err = c.SetPackageLevel(ctx, "github.com/foo/baz", slogscope.LogLevelError, 100*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, slogscope.LogLevelError, info.LogLevel)
+		assert.Eventually(t, func() bool {
+			return len(h.GetConfig().Packages) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test invalid arguments", func(t *testing.T) {
+		_, err := c.SetPackageLevel(ctx, "github.com/foo/bar", "VERBOSE", 0)
+		assert.ErrorContains(t, err, `packages[0].log_level: invalid log level "VERBOSE"`)
+		_, err = c.SetPackageLevel(ctx, "", slogscope.LogLevelDebug, 0)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorContains(t, err, "package and log_level are required")
+	})
+
+	t.Run("test watch config", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ch := make(chan control.ConfigChange)
+		errCh := make(chan error, 1)
+		go func() { errCh <- c.WatchConfig(ctx, slogscope.FormatYAML, ch) }()
+
+		change := <-ch
+		assert.Equal(t, slogscope.FormatYAML, change.Format)
+		assert.Contains(t, string(change.Config), "log_level: DEBUG")
+
+		h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelError})
+		select {
+		case change = <-ch:
+			assert.Equal(t, "UseConfig", change.Source)
+			assert.Contains(t, string(change.Config), "log_level: ERROR")
+			assert.WithinDuration(t, time.Now(), change.Time, time.Second)
+		case <-time.After(time.Second):
+			t.Fatal("config change was not streamed")
+		}
+
+		cancel()
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("test unsupported format", func(t *testing.T) {
+		err := c.WatchConfig(ctx, "xml", make(chan control.ConfigChange))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorContains(t, err, `unsupported config format: "xml"`)
+	})
+}
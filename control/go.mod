@@ -0,0 +1,28 @@
+module github.com/apperia-de/slogscope/control
+
+go 1.23
+
+require (
+	github.com/apperia-de/slogscope v1.1.0
+	github.com/apperia-de/slogscope/proto v1.1.0
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/grpc v1.72.2
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/apperia-de/slogscope => ..
+	github.com/apperia-de/slogscope/proto => ../proto
+)
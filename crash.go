@@ -0,0 +1,85 @@
+package slogscope
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// defaultFinalFlushTimeout is the maximum time a final flush may block, if DeliveryOptions.FinalFlushTimeout is not
+// set.
+const defaultFinalFlushTimeout = 2 * time.Second
+
+// flushWithin blocks until all queued records are either acknowledged or written to the dead letter file, but at most
+// for the given timeout. It reports whether all records were flushed.
+func (d *deliverer) flushWithin(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		d.flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// finalFlush flushes the queued records on a crash path, which must not block forever (see
+// DeliveryOptions.FinalFlushTimeout).
+func (d *deliverer) finalFlush(cause string) {
+	if d.queues == nil {
+		return
+	}
+	timeout := d.opts.FinalFlushTimeout
+	if timeout <= 0 {
+		timeout = defaultFinalFlushTimeout
+	}
+	if !d.flushWithin(timeout) {
+		d.logger.Debug(fmt.Sprintf("final flush on %s timed out after %s", cause, timeout))
+	}
+}
+
+// FlushOnPanic flushes the records queued by the asynchronous delivery mode (see DeliveryOptions.QueueSize), if the
+// calling goroutine panics, and continues panicking afterwards. The last records before a crash are usually the most
+// important ones, so defer it at the top of main and of long-running goroutines:
+//
+//	func main() {
+//		h := slogscope.NewHandler(...)
+//		defer h.FlushOnPanic()
+//		// ...
+//	}
+//
+// The flush is bounded by DeliveryOptions.FinalFlushTimeout. Fatal runtime errors, e.g. concurrent map writes, and
+// os.Exit, e.g. via log.Fatal, don't run deferred functions, so records queued at that time may be lost.
+func (h *Handler) FlushOnPanic() {
+	if r := recover(); r != nil {
+		h.delivery.finalFlush("panic")
+		panic(r)
+	}
+}
+
+// flushOnSignal flushes the queued records as soon as the process receives SIGINT or SIGTERM and raises the signal
+// again afterwards, so the default action of the signal, i.e. the termination of the process, still applies (see
+// DeliveryOptions.FlushOnSignal).
+func (h *Handler) flushOnSignal() {
+	sigCh := make(chan os.Signal, 1)
+	if !notifyTermination(sigCh) {
+		h.logger.Debug("signals are not supported on this platform! -> flush on signal is disabled.")
+		return
+	}
+	go func() {
+		sig := <-sigCh
+		h.delivery.finalFlush(sig.String())
+		stopNotify(sigCh)
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(sig)
+		}
+		if err != nil {
+			// Some platforms, e.g. Windows, can't raise signals. Exit like the default action of the signal would.
+			os.Exit(2)
+		}
+	}()
+}
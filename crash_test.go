@@ -0,0 +1,57 @@
+package slogscope_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowHandler writes the messages of all records after a delay, like a handler of a slow network sink.
+type slowHandler struct {
+	mu  sync.Mutex
+	w   io.Writer
+	lag time.Duration
+}
+
+func (h *slowHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *slowHandler) Handle(_ context.Context, rec slog.Record) error {
+	time.Sleep(h.lag)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(h.w, rec.Message)
+	return err
+}
+
+func (h *slowHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *slowHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestHandler_FlushOnPanic(t *testing.T) {
+	var out strings.Builder
+	sh := &slowHandler{w: &out, lag: 20 * time.Millisecond}
+	h := slogscope.NewHandler(sh, &slogscope.HandlerOptions{
+		Config:   &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+		Delivery: &slogscope.DeliveryOptions{QueueSize: 10, Workers: 1},
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer h.FlushOnPanic()
+		l := slog.New(h)
+		for i := range 5 {
+			l.Info(fmt.Sprintf("record %d", i))
+		}
+		panic("boom")
+	})
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	assert.Equal(t, "record 0\nrecord 1\nrecord 2\nrecord 3\nrecord 4\n", out.String())
+}
@@ -0,0 +1,67 @@
+//go:build unix && !slogscope_minimal
+
+package slogscope_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperFlushProcess isn't a real test. It logs to stdout via a slow handler and waits for a termination signal.
+func TestHelperFlushProcess(t *testing.T) {
+	if os.Getenv("SLOGSCOPE_FLUSH_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	h := slogscope.NewHandler(&slowHandler{w: os.Stdout, lag: 20 * time.Millisecond}, &slogscope.HandlerOptions{
+		Config:   &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+		Delivery: &slogscope.DeliveryOptions{QueueSize: 10, Workers: 1, FlushOnSignal: true},
+	})
+	l := slog.New(h)
+	for i := range 5 {
+		l.Info(fmt.Sprintf("record %d", i))
+	}
+	fmt.Println("ready")
+	time.Sleep(10 * time.Second)
+	os.Exit(0)
+}
+
+func TestHandler_FlushOnSignal(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperFlushProcess$")
+	cmd.Env = append(os.Environ(), "SLOGSCOPE_FLUSH_HELPER=1")
+	cmd.Dir = t.TempDir()
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Start())
+
+	var lines []string
+	sc := bufio.NewScanner(stdout)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+		if sc.Text() == "ready" {
+			assert.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+		}
+	}
+	err = cmd.Wait()
+
+	var exitErr *exec.ExitError
+	if assert.True(t, errors.As(err, &exitErr), "process must be terminated by the signal") {
+		ws := exitErr.Sys().(syscall.WaitStatus)
+		assert.True(t, ws.Signaled())
+		assert.Equal(t, syscall.SIGTERM, ws.Signal())
+	}
+	out := strings.Join(lines, "\n")
+	for i := range 5 {
+		assert.Contains(t, out, fmt.Sprintf("record %d", i))
+	}
+}
@@ -0,0 +1,112 @@
+package slogscope
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultDecisionCacheSize is the number of cached decisions, if HandlerOptions.DecisionCacheSize is not set.
+const defaultDecisionCacheSize = 4096
+
+// decisionKey identifies a log call site checked by Handler.Enabled.
+type decisionKey struct {
+	pc     uintptr // Program counter of the call site as returned by runtime.Callers.
+	level  slog.Level
+	groups string // Groups of the handler (see MatchGroup).
+}
+
+// cachedDecision is the result of Handler.Enabled for a decisionKey, apart from the taps, which may change at any time.
+type cachedDecision struct {
+	gen        uint64 // Generation of the cache, which the decision was made in.
+	pkgName    string // Package of the caller (see slogscope.scope).
+	enabled    bool   // Whether the log level of the matching rule or the global log level enables the record.
+	first      bool   // Whether the record may be enabled for the first occurrences of its message (see Package.First).
+	unresolved bool   // Whether the caller can't be resolved to a Go package (see Config.FallbackScope).
+}
+
+// decisionCache caches the decisions of Handler.Enabled by call site and log level, so resolving the caller and
+// matching the package rules happens once per call site and config. All decisions are invalidated as soon as the
+// config is applied or a scope is registered. If the cache is full, it is cleared.
+type decisionCache struct {
+	capacity      int // Maximum number of cached decisions, the cache is disabled if it is negative.
+	entries       sync.Map
+	size          atomic.Int64
+	gen           atomic.Uint64
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// newDecisionCache returns a decisionCache for the given number of decisions (see HandlerOptions.DecisionCacheSize).
+func newDecisionCache(capacity int) *decisionCache {
+	if capacity == 0 {
+		capacity = defaultDecisionCacheSize
+	}
+	return &decisionCache{capacity: capacity}
+}
+
+// load returns the decision for the key, if it was made in the current generation.
+func (c *decisionCache) load(key decisionKey) (*cachedDecision, bool) {
+	if c.capacity < 0 {
+		return nil, false
+	}
+	if v, ok := c.entries.Load(key); ok {
+		if d := v.(*cachedDecision); d.gen == c.gen.Load() {
+			c.hits.Add(1)
+			return d, true
+		}
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+// generation returns the current generation, which must be read before making a decision to be stored.
+func (c *decisionCache) generation() uint64 {
+	return c.gen.Load()
+}
+
+// store caches the decision. Decisions made in a previous generation are discarded.
+func (c *decisionCache) store(key decisionKey, d *cachedDecision) {
+	if c.capacity < 0 || d.gen != c.gen.Load() {
+		return
+	}
+	if _, loaded := c.entries.Swap(key, d); loaded {
+		return
+	}
+	if c.size.Add(1) > int64(c.capacity) {
+		c.clear()
+	}
+}
+
+// invalidate discards all cached decisions, e.g. after the config was applied.
+func (c *decisionCache) invalidate() {
+	c.gen.Add(1)
+	c.invalidations.Add(1)
+	c.clear()
+}
+
+func (c *decisionCache) clear() {
+	c.entries.Clear()
+	c.size.Store(0)
+}
+
+// DecisionCacheStats contains the counters of the decision cache of Handler.Enabled (see
+// HandlerOptions.DecisionCacheSize).
+type DecisionCacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Entries       int64  `json:"entries"`       // Number of cached decisions.
+	Capacity      int    `json:"capacity"`      // Maximum number of cached decisions, negative if the cache is disabled.
+	Invalidations uint64 `json:"invalidations"` // Number of invalidations, e.g. due to config changes.
+}
+
+func (c *decisionCache) stats() DecisionCacheStats {
+	return DecisionCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Entries:       c.size.Load(),
+		Capacity:      c.capacity,
+		Invalidations: c.invalidations.Load(),
+	}
+}
@@ -0,0 +1,67 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_DecisionCache(t *testing.T) {
+	newHandler := func(buf *bytes.Buffer, size int) *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+			Config:            &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+			DecisionCacheSize: size,
+		})
+	}
+
+	t.Run("test decisions are cached by call site", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := newHandler(&buf, 0)
+		l := slog.New(h)
+		for range 10 {
+			l.Debug("debug message")
+		}
+		stats := h.Stats().DecisionCache
+		assert.Equal(t, uint64(9), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, int64(1), stats.Entries)
+		assert.Equal(t, 4096, stats.Capacity)
+		assert.Empty(t, buf.String())
+		assert.Equal(t, uint64(10), h.GetPackages()[0].Observed)
+	})
+
+	t.Run("test config changes invalidate decisions", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := newHandler(&buf, 0)
+		l := slog.New(h)
+		logDebug := func() { l.Debug("debug message") }
+
+		logDebug()
+		assert.Empty(t, buf.String())
+		before := h.Stats().DecisionCache.Invalidations
+
+		h.SetPackageLevel("github.com/apperia-de/slogscope_test", slogscope.LogLevelDebug)
+		logDebug()
+		assert.Contains(t, buf.String(), "debug message")
+		assert.Equal(t, before+1, h.Stats().DecisionCache.Invalidations)
+	})
+
+	t.Run("test full cache is cleared", func(t *testing.T) {
+		h := newHandler(&bytes.Buffer{}, 2)
+		l := slog.New(h)
+		l.Debug("first call site")
+		l.Debug("second call site")
+		assert.Equal(t, int64(2), h.Stats().DecisionCache.Entries)
+		l.Debug("third call site")
+		assert.Equal(t, int64(0), h.Stats().DecisionCache.Entries)
+	})
+
+	t.Run("test disabled cache", func(t *testing.T) {
+		h := newHandler(&bytes.Buffer{}, -1)
+		slog.New(h).Debug("debug message")
+		assert.Equal(t, slogscope.DecisionCacheStats{Capacity: -1, Invalidations: 1}, h.Stats().DecisionCache)
+	})
+}
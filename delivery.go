@@ -0,0 +1,316 @@
+package slogscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errQueueFull = errors.New("delivery queue is full")
+
+// deliverer implements the at-least-once delivery mode for the wrapped slog.Handler.
+// A record counts as acknowledged as soon as the wrapped slog.Handler returns without an error.
+type deliverer struct {
+	opts    DeliveryOptions
+	queues  []chan delivery // Queues of the shards, nil for synchronous delivery.
+	pending pendingCounter  // Tracks queued records which are not yet acknowledged or dead-lettered.
+	mu      sync.Mutex      // Serializes writes to the dead letter file.
+	logger  *slog.Logger
+
+	deadlineExceeded atomic.Uint64 // Number of synchronous deliveries cut short by the deadline of the caller.
+
+	// drop enables dropping records, which can't be queued or delivered, instead of writing them to the dead letter
+	// file (see SinkQueue). Dropped records are counted.
+	drop    bool
+	dropped atomic.Uint64
+}
+
+// pendingCounter counts records in flight. Unlike sync.WaitGroup, records may be added while another goroutine waits
+// for the counter to drop to zero, so flushing is well-defined while logging continues.
+type pendingCounter struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	n    int
+}
+
+func (c *pendingCounter) add() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *pendingCounter) done() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n--; c.n == 0 && c.cond != nil {
+		c.cond.Broadcast()
+	}
+}
+
+// wait blocks until no records are in flight.
+func (c *pendingCounter) wait() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cond == nil {
+		c.cond = sync.NewCond(&c.mu)
+	}
+	for c.n > 0 {
+		c.cond.Wait()
+	}
+}
+
+// delivery is a queued record together with the handler it has to be delivered to.
+type delivery struct {
+	ctx context.Context
+	h   slog.Handler
+	rec slog.Record
+}
+
+// newDeliverer returns a new deliverer and starts its workers if asynchronous delivery is enabled.
+// Without manual overrides, the number of workers and shards scales with GOMAXPROCS.
+func newDeliverer(opts DeliveryOptions, logger *slog.Logger) *deliverer {
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+	if opts.DeadLetterFile == "" {
+		opts.DeadLetterFile = defaultDeadLetterFile
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Shards <= 0 || opts.Shards > opts.Workers {
+		// Every shard needs at least one worker.
+		opts.Shards = opts.Workers
+	}
+
+	d := &deliverer{opts: opts, logger: logger}
+	if opts.QueueSize > 0 {
+		// The queue size is split across all shards.
+		size := max((opts.QueueSize+opts.Shards-1)/opts.Shards, 1)
+		d.queues = make([]chan delivery, opts.Shards)
+		for i := range d.queues {
+			d.queues[i] = make(chan delivery, size)
+		}
+		for i := 0; i < opts.Workers; i++ {
+			go d.work(d.queues[i%opts.Shards])
+		}
+	}
+	return d
+}
+
+// SinkQueue sends the records of a sink handler in the background, e.g. to Sentry or a webhook, so logging doesn't
+// block on the network. A single worker sends the records in order. Records, which don't fit into the queue or can't
+// be sent after the retries (see DeliveryOptions.MaxRetries), are dropped and counted. Sink handlers using a SinkQueue
+// implement QueuedSink, so Handler.Flush waits for their queued records and Handler.Stats reports their drops.
+type SinkQueue struct {
+	d *deliverer
+}
+
+// QueuedSink is implemented by sink handlers sending their records via a SinkQueue (see HandlerOptions.Sinks).
+type QueuedSink interface {
+	SinkQueue() *SinkQueue
+}
+
+// NewSinkQueue returns a SinkQueue for the given number of records (default: 100).
+func NewSinkQueue(size int) *SinkQueue {
+	if size <= 0 {
+		size = defaultSinkQueueSize
+	}
+	d := newDeliverer(DeliveryOptions{QueueSize: size, Workers: 1}, slog.New(NewNilHandler()))
+	d.drop = true
+	return &SinkQueue{d: d}
+}
+
+// Send queues the record, which is sent by calling send from the worker of the queue. It returns an error if the
+// record is dropped, because the queue is full.
+func (q *SinkQueue) Send(ctx context.Context, rec slog.Record, send func(ctx context.Context) error) error {
+	return q.d.deliver(ctx, sendFunc(send), rec, "")
+}
+
+// Flush blocks until all queued records are either sent or dropped.
+func (q *SinkQueue) Flush() {
+	q.d.flush()
+}
+
+// Dropped returns the number of dropped records.
+func (q *SinkQueue) Dropped() uint64 {
+	return q.d.droppedCount()
+}
+
+// Close stops the worker after the queued records are sent. Records must not be sent afterward.
+func (q *SinkQueue) Close() error {
+	q.d.close()
+	return nil
+}
+
+// sendFunc is a slog.Handler sending an already prepared record, e.g. an encoded request body, so it can be delivered
+// by the deliverer of a SinkQueue.
+type sendFunc func(ctx context.Context) error
+
+func (f sendFunc) Enabled(context.Context, slog.Level) bool        { return true }
+func (f sendFunc) Handle(ctx context.Context, _ slog.Record) error { return f(ctx) }
+func (f sendFunc) WithAttrs([]slog.Attr) slog.Handler              { return f }
+func (f sendFunc) WithGroup(string) slog.Handler                   { return f }
+
+// deliver hands the record over to h. In asynchronous mode, the record is queued to the shard of its package and
+// deliver returns immediately. Records of the same package keep their order, if every shard has a single worker.
+func (d *deliverer) deliver(ctx context.Context, h slog.Handler, rec slog.Record, pkgName string) error {
+	if d.queues == nil {
+		if d.opts.RespectDeadline && ctx.Done() != nil {
+			return d.sendWithin(ctx, h, rec)
+		}
+		return d.send(ctx, h, rec)
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(pkgName))
+	queue := d.queues[hash.Sum32()%uint32(len(d.queues))]
+
+	d.pending.add()
+	select {
+	case queue <- delivery{ctx: context.WithoutCancel(ctx), h: h, rec: rec.Clone()}:
+		return nil
+	default:
+		d.pending.done()
+		return d.deadLetter(rec, errQueueFull)
+	}
+}
+
+// work delivers the records of a queue.
+func (d *deliverer) work(queue <-chan delivery) {
+	for dl := range queue {
+		_ = d.send(dl.ctx, dl.h, dl.rec)
+		d.pending.done()
+	}
+}
+
+// send tries to deliver the record with bounded retries and writes it to the dead letter file if all attempts fail.
+func (d *deliverer) send(ctx context.Context, h slog.Handler, rec slog.Record) error {
+	var err error
+	wait := d.opts.RetryInterval
+	for attempt := 0; attempt <= d.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			d.logger.Debug(fmt.Sprintf("retry delivery of record (attempt %d/%d): %s", attempt, d.opts.MaxRetries, err.Error()))
+			time.Sleep(wait)
+			wait *= 2
+		}
+		if err = h.Handle(ctx, rec); err == nil {
+			return nil
+		}
+	}
+	return d.deadLetter(rec, err)
+}
+
+// sendWithin delivers the record like send, but returns as soon as ctx is done. Retries, which would end after the
+// deadline of ctx, aren't attempted and the record is written to the dead letter file instead. An attempt still
+// running when ctx is done is left to finish in the background, so the record is dead-lettered if it fails.
+func (d *deliverer) sendWithin(ctx context.Context, h slog.Handler, rec slog.Record) error {
+	rec = rec.Clone()
+	deadline, hasDeadline := ctx.Deadline()
+	var err error
+	wait := d.opts.RetryInterval
+	for attempt := 0; attempt <= d.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if hasDeadline && time.Until(deadline) < wait {
+				d.deadlineExceeded.Add(1)
+				return d.deadLetter(rec, fmt.Errorf("%w: %w", context.DeadlineExceeded, err))
+			}
+			d.logger.Debug(fmt.Sprintf("retry delivery of record (attempt %d/%d): %s", attempt, d.opts.MaxRetries, err.Error()))
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				d.deadlineExceeded.Add(1)
+				return d.deadLetter(rec, fmt.Errorf("%w: %w", ctx.Err(), err))
+			}
+			wait *= 2
+		}
+
+		// Whoever claims the attempt first decides about the record: the attempt reports its result to the caller,
+		// or the caller abandons the attempt, which then dead-letters the record itself on failure.
+		var claimed atomic.Bool
+		done := make(chan error, 1)
+		d.pending.add()
+		go func() {
+			defer d.pending.done()
+			err := h.Handle(context.WithoutCancel(ctx), rec)
+			if claimed.CompareAndSwap(false, true) {
+				done <- err
+				return
+			}
+			if err != nil {
+				_ = d.deadLetter(rec, fmt.Errorf("%w: %w", ctx.Err(), err))
+			}
+		}()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			if claimed.CompareAndSwap(false, true) {
+				d.deadlineExceeded.Add(1)
+				d.logger.Debug(fmt.Sprintf("delivery of record continues in the background: %s", ctx.Err().Error()))
+				return nil
+			}
+			err = <-done
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return d.deadLetter(rec, err)
+}
+
+// deadLetter appends an undeliverable record to the dead letter file or, in drop mode, drops it.
+func (d *deliverer) deadLetter(rec slog.Record, cause error) error {
+	if d.drop {
+		d.dropped.Add(1)
+		return cause
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, err := os.OpenFile(d.opts.DeadLetterFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+	defer f.Close()
+
+	rec = rec.Clone()
+	rec.AddAttrs(slog.String("delivery_error", cause.Error()))
+	if err = slog.NewJSONHandler(f, nil).Handle(context.Background(), rec); err != nil {
+		return errors.Join(cause, err)
+	}
+	d.logger.Debug(fmt.Sprintf("record written to dead letter file (%s): %s", d.opts.DeadLetterFile, cause.Error()))
+	return nil
+}
+
+// deadlineExceededCount returns the number of synchronous deliveries cut short by the deadline of the caller.
+func (d *deliverer) deadlineExceededCount() uint64 {
+	return d.deadlineExceeded.Load()
+}
+
+// flush blocks until all queued records are either acknowledged or written to the dead letter file.
+func (d *deliverer) flush() {
+	d.pending.wait()
+}
+
+// droppedCount returns the number of records dropped in drop mode.
+func (d *deliverer) droppedCount() uint64 {
+	return d.dropped.Load()
+}
+
+// close stops the workers after the queued records are delivered. Records must not be delivered afterward.
+func (d *deliverer) close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+}
@@ -0,0 +1,268 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingHandler is a slog.Handler which fails for the first n calls of Handle.
+type failingHandler struct {
+	mu    sync.Mutex
+	n     int
+	calls int
+	buf   bytes.Buffer
+}
+
+func (h *failingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *failingHandler) Handle(ctx context.Context, rec slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.n {
+		return errors.New("sink unavailable")
+	}
+	return slog.NewTextHandler(&h.buf, nil).Handle(ctx, rec)
+}
+
+func (h *failingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *failingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestHandler_Delivery(t *testing.T) {
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo}
+
+	t.Run("test record is delivered after a retry", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 2}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{MaxRetries: 3, RetryInterval: time.Millisecond, DeadLetterFile: dlq},
+		})
+		slog.New(h).Info("audit message")
+
+		assert.Equal(t, 3, fh.calls)
+		assert.Contains(t, fh.buf.String(), "audit message")
+		assert.NoFileExists(t, dlq)
+	})
+
+	t.Run("test undeliverable record is written to the dead letter file", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 10}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{MaxRetries: 2, RetryInterval: time.Millisecond, DeadLetterFile: dlq},
+		})
+		slog.New(h).Info("audit message")
+
+		assert.Equal(t, 3, fh.calls)
+		data, err := os.ReadFile(dlq)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"msg":"audit message"`)
+		assert.Contains(t, string(data), `"delivery_error":"sink unavailable"`)
+	})
+
+	t.Run("test asynchronous delivery with flush", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 1}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config: &cfg,
+			Delivery: &slogscope.DeliveryOptions{
+				RetryInterval:  time.Millisecond,
+				DeadLetterFile: dlq,
+				QueueSize:      10,
+			},
+		})
+		l := slog.New(h)
+		l.Info("first message")
+		l.Info("second message")
+		h.Flush()
+
+		assert.Contains(t, fh.buf.String(), "first message")
+		assert.Contains(t, fh.buf.String(), "second message")
+		assert.NoFileExists(t, dlq)
+	})
+
+	t.Run("test flush while logging continues", func(t *testing.T) {
+		fh := &failingHandler{}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{DeadLetterFile: filepath.Join(t.TempDir(), "test.dlq"), QueueSize: 1000, Workers: 1},
+		})
+		l := slog.New(h)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					l.Info("message")
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					h.Flush()
+				}
+			}()
+		}
+		wg.Wait()
+		h.Flush()
+
+		fh.mu.Lock()
+		defer fh.mu.Unlock()
+		assert.Equal(t, 400, fh.calls)
+	})
+}
+
+func TestHandler_DeliveryPerPackage(t *testing.T) {
+	t.Run("test durable package with best-effort default", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 1}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Delivery: slogscope.DeliveryBestEffort,
+				Packages: []slogscope.Package{
+					{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo, Delivery: slogscope.DeliveryDurable},
+				},
+			},
+			Delivery: &slogscope.DeliveryOptions{RetryInterval: time.Millisecond, DeadLetterFile: dlq},
+		})
+		slog.New(h).Info("audit message")
+
+		assert.Equal(t, 2, fh.calls)
+		assert.Contains(t, fh.buf.String(), "audit message")
+	})
+
+	t.Run("test best-effort package with durable default", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 1}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Packages: []slogscope.Package{
+					{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo, Delivery: slogscope.DeliveryBestEffort},
+				},
+			},
+			Delivery: &slogscope.DeliveryOptions{RetryInterval: time.Millisecond, DeadLetterFile: dlq},
+		})
+		slog.New(h).Info("best-effort message")
+
+		assert.Equal(t, 1, fh.calls)
+		assert.Empty(t, fh.buf.String())
+		assert.NoFileExists(t, dlq)
+	})
+}
+
+func TestHandler_Tuning(t *testing.T) {
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo}
+
+	t.Run("test synchronous delivery has no workers", func(t *testing.T) {
+		h := slogscope.NewHandler(slog.NewTextHandler(io.Discard, nil), &slogscope.HandlerOptions{Config: &cfg})
+		assert.Equal(t, slogscope.Tuning{GOMAXPROCS: runtime.GOMAXPROCS(0)}, h.Tuning())
+	})
+
+	t.Run("test workers and shards default to GOMAXPROCS", func(t *testing.T) {
+		h := slogscope.NewHandler(slog.NewTextHandler(io.Discard, nil), &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{QueueSize: 1024},
+		})
+		tuning := h.Tuning()
+		assert.Equal(t, runtime.GOMAXPROCS(0), tuning.DeliveryWorkers)
+		assert.Equal(t, runtime.GOMAXPROCS(0), tuning.DeliveryShards)
+		assert.GreaterOrEqual(t, tuning.DeliveryQueueSize, 1024)
+	})
+
+	t.Run("test overridden workers and shards", func(t *testing.T) {
+		h := slogscope.NewHandler(slog.NewTextHandler(io.Discard, nil), &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{QueueSize: 8, Workers: 4, Shards: 2},
+		})
+		assert.Equal(t, slogscope.Tuning{
+			GOMAXPROCS:        runtime.GOMAXPROCS(0),
+			DeliveryWorkers:   4,
+			DeliveryShards:    2,
+			DeliveryQueueSize: 8,
+		}, h.Tuning())
+	})
+}
+
+func TestHandler_DeliveryDeadline(t *testing.T) {
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo}
+
+	t.Run("test slow delivery continues in the background", func(t *testing.T) {
+		var out strings.Builder
+		h := slogscope.NewHandler(&slowHandler{w: &out, lag: 200 * time.Millisecond}, &slogscope.HandlerOptions{
+			Config:   &cfg,
+			Delivery: &slogscope.DeliveryOptions{DeadLetterFile: filepath.Join(t.TempDir(), "test.dlq"), RespectDeadline: true},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		slog.New(h).InfoContext(ctx, "slow message")
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+		assert.Equal(t, uint64(1), h.Stats().DeadlineExceeded)
+
+		h.Flush()
+		assert.Equal(t, "slow message\n", out.String())
+	})
+
+	t.Run("test retries exceeding the deadline are dead-lettered", func(t *testing.T) {
+		dlq := filepath.Join(t.TempDir(), "test.dlq")
+		fh := &failingHandler{n: 10}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config: &cfg,
+			Delivery: &slogscope.DeliveryOptions{
+				MaxRetries:      3,
+				RetryInterval:   time.Second,
+				DeadLetterFile:  dlq,
+				RespectDeadline: true,
+			},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		slog.New(h).InfoContext(ctx, "audit message")
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, 1, fh.calls)
+		assert.Equal(t, uint64(1), h.Stats().DeadlineExceeded)
+		data, err := os.ReadFile(dlq)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"msg":"audit message"`)
+		assert.Contains(t, string(data), `"delivery_error":"context deadline exceeded: sink unavailable"`)
+	})
+
+	t.Run("test contexts without deadline are delivered as usual", func(t *testing.T) {
+		fh := &failingHandler{n: 1}
+		h := slogscope.NewHandler(fh, &slogscope.HandlerOptions{
+			Config: &cfg,
+			Delivery: &slogscope.DeliveryOptions{
+				RetryInterval:   time.Millisecond,
+				DeadLetterFile:  filepath.Join(t.TempDir(), "test.dlq"),
+				RespectDeadline: true,
+			},
+		})
+		slog.New(h).Info("audit message")
+
+		assert.Equal(t, 2, fh.calls)
+		assert.Contains(t, fh.buf.String(), "audit message")
+		assert.Zero(t, h.Stats().DeadlineExceeded)
+	})
+}
@@ -0,0 +1,233 @@
+package slogscope
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Available formats for Handler.ExportDocs.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// docSection is a section of the config documentation, rendered as a table.
+type docSection struct {
+	title  string
+	intro  string
+	header []string
+	rows   [][]string
+}
+
+// ExportDocs renders the logging policy in force as Markdown or HTML document (FormatMarkdown or FormatHTML), so
+// teams can publish it straight from the source of truth, e.g. in a wiki or on an internal status page. Like
+// ExportConfig, it is based on EffectiveConfig and documents the global settings, the log levels in use including
+// custom levels like DEBUG-4, the package rules with their descriptions and sources (see ExplainDecision), the sinks,
+// the field renames and the quiet hours. Sink options are left out, since they may contain credentials.
+func (h *Handler) ExportDocs(format string) ([]byte, error) {
+	sections := h.docSections()
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "md":
+		return renderMarkdownDocs(sections), nil
+	case FormatHTML:
+		return renderHTMLDocs(sections), nil
+	}
+	return nil, fmt.Errorf("unsupported documentation format: %q", format)
+}
+
+// docSections returns the sections of the documentation of the effective config.
+func (h *Handler) docSections() []docSection {
+	cfg := h.EffectiveConfig()
+	h.mu.Lock()
+	globalSource, durable := h.globalSource, h.settings.Load().durable
+	_, sources := h.resolveSources(*h.opts.Config)
+	h.mu.Unlock()
+
+	delivery := DeliveryBestEffort
+	if durable {
+		delivery = DeliveryDurable
+	}
+	global := docSection{
+		title:  "Global settings",
+		header: []string{"Setting", "Value"},
+		rows: [][]string{
+			{"Log level", cfg.LogLevel},
+			{"Delivery", delivery},
+			{"Source", globalSource},
+		},
+	}
+	if cfg.SchemaVersion != "" {
+		global.rows = append(global.rows, []string{"Schema version", cfg.SchemaVersion})
+	}
+	if cfg.FallbackScope != "" {
+		global.rows = append(global.rows, []string{"Fallback scope", cfg.FallbackScope})
+	}
+	if cfg.TestPackages {
+		global.rows = append(global.rows, []string{"Test packages", "rules apply to external test packages"})
+	}
+	sections := []docSection{global, docLevels(cfg)}
+
+	if len(cfg.Packages) > 0 {
+		rules := docSection{
+			title:  "Package rules",
+			intro:  "Rules are matched by priority and specificity. A name prefixed with ! excludes the matching packages from less specific rules.",
+			header: []string{"Rule", "Match", "Log level", "Functions", "Delivery", "Expires", "Description", "Source"},
+		}
+		for _, p := range cfg.Packages {
+			match := p.Match
+			if match == "" {
+				match = MatchGlob
+			}
+			var functions []string
+			for _, f := range p.Functions {
+				functions = append(functions, fmt.Sprintf("%s: %s", f.Name, h.GetLogLevel(f.LogLevel)))
+			}
+			for _, r := range p.Receivers {
+				functions = append(functions, fmt.Sprintf("%s.*: %s", r.Name, h.GetLogLevel(r.LogLevel)))
+			}
+			rules.rows = append(rules.rows, []string{
+				p.Name, match, p.LogLevel, strings.Join(functions, ", "), p.Delivery, p.Expires, p.Description, sources[p.Name],
+			})
+		}
+		sections = append(sections, rules)
+	}
+	if len(cfg.Sinks) > 0 {
+		sinks := docSection{
+			title:  "Sinks",
+			intro:  "Sinks receive the records of the given packages in addition to the regular log output, independent of the package log levels.",
+			header: []string{"Sink", "Type", "Log level", "Packages"},
+		}
+		for _, s := range cfg.Sinks {
+			lvl, pkgs := LogLevelError, "all"
+			if s.LogLevel != "" {
+				lvl = h.GetLogLevel(s.LogLevel).String()
+			}
+			if len(s.Packages) > 0 {
+				pkgs = strings.Join(s.Packages, ", ")
+			}
+			sinks.rows = append(sinks.rows, []string{s.Name, s.Type, lvl, pkgs})
+		}
+		sections = append(sections, sinks)
+	}
+	if len(cfg.Renames) > 0 {
+		renames := docSection{
+			title:  "Field renames",
+			intro:  "Attributes are moved to new keys in the given order. Dots descend into groups.",
+			header: []string{"From", "To"},
+		}
+		for _, r := range cfg.Renames {
+			renames.rows = append(renames.rows, []string{r.From, r.To})
+		}
+		sections = append(sections, renames)
+	}
+	if len(cfg.QuietHours) > 0 {
+		quiet := docSection{
+			title:  "Quiet hours",
+			intro:  "During quiet hours, all packages log at the given log level or above.",
+			header: []string{"From", "To", "Log level", "Time zone"},
+		}
+		for _, q := range cfg.QuietHours {
+			lvl, loc := LogLevelWarn, "local time"
+			if q.LogLevel != "" {
+				lvl = h.GetLogLevel(q.LogLevel).String()
+			}
+			if q.Location != "" {
+				loc = q.Location
+			}
+			quiet.rows = append(quiet.rows, []string{q.From, q.To, lvl, loc})
+		}
+		sections = append(sections, quiet)
+	}
+	return sections
+}
+
+// docLevels returns the section listing the standard log levels and all custom levels used by the config, e.g.
+// DEBUG-4, together with their numeric slog.Level.
+func docLevels(cfg Config) docSection {
+	standard := []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+	levels := slices.Clone(standard)
+	use := func(level string) {
+		if lvl, ok := parseLogLevel(level); ok && !slices.Contains(levels, lvl) {
+			levels = append(levels, lvl)
+		}
+	}
+	use(cfg.LogLevel)
+	for _, p := range cfg.Packages {
+		use(p.LogLevel)
+		for _, f := range slices.Concat(p.Functions, p.Receivers) {
+			use(f.LogLevel)
+		}
+	}
+	for _, s := range cfg.Sinks {
+		use(s.LogLevel)
+	}
+	slices.Sort(levels)
+
+	section := docSection{
+		title:  "Log levels",
+		intro:  "Records are emitted, if their level is at or above the log level of their package.",
+		header: []string{"Level", "Value", "Kind"},
+	}
+	for _, lvl := range levels {
+		kind := "standard"
+		if !slices.Contains(standard, lvl) {
+			kind = "custom"
+		}
+		section.rows = append(section.rows, []string{lvl.String(), fmt.Sprintf("%d", int(lvl)), kind})
+	}
+	return section
+}
+
+// renderMarkdownDocs renders the sections as Markdown document.
+func renderMarkdownDocs(sections []docSection) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Logging policy\n")
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, s := range sections {
+		fmt.Fprintf(&buf, "\n## %s\n\n", s.title)
+		if s.intro != "" {
+			fmt.Fprintf(&buf, "%s\n\n", s.intro)
+		}
+		fmt.Fprintf(&buf, "| %s |\n", strings.Join(s.header, " | "))
+		fmt.Fprintf(&buf, "|%s\n", strings.Repeat(" --- |", len(s.header)))
+		for _, row := range s.rows {
+			cells := make([]string, len(row))
+			for i, c := range row {
+				cells[i] = cell.Replace(c)
+			}
+			fmt.Fprintf(&buf, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return buf.Bytes()
+}
+
+// renderHTMLDocs renders the sections as standalone HTML document.
+func renderHTMLDocs(sections []docSection) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Logging policy</title>\n</head>\n<body>\n<h1>Logging policy</h1>\n")
+	for _, s := range sections {
+		fmt.Fprintf(&buf, "<h2>%s</h2>\n", html.EscapeString(s.title))
+		if s.intro != "" {
+			fmt.Fprintf(&buf, "<p>%s</p>\n", html.EscapeString(s.intro))
+		}
+		buf.WriteString("<table>\n<tr>")
+		for _, c := range s.header {
+			fmt.Fprintf(&buf, "<th>%s</th>", html.EscapeString(c))
+		}
+		buf.WriteString("</tr>\n")
+		for _, row := range s.rows {
+			buf.WriteString("<tr>")
+			for _, c := range row {
+				fmt.Fprintf(&buf, "<td>%s</td>", html.EscapeString(c))
+			}
+			buf.WriteString("</tr>\n")
+		}
+		buf.WriteString("</table>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,61 @@
+package slogscope_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ExportDocs(t *testing.T) {
+	requireFullBuild(t)
+
+	filename := filepath.Join(t.TempDir(), "slogscope.yml")
+	assert.NoError(t, os.WriteFile(filename, []byte(`log_level: INFO
+packages:
+  - name: github.com/foo/bar/db
+    log_level: DEBUG-4
+    description: "query tracing | issue #42"
+    functions:
+      - name: migrate
+        log_level: WARN
+  - name: "!github.com/foo/bar/db/mock"
+    log_level: ERROR
+sinks:
+  - name: pager
+    type: webhook
+    options:
+      url: https://secret.example.com/hook
+`), 0644))
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: filename})
+
+	t.Run("test markdown", func(t *testing.T) {
+		data, err := h.ExportDocs(slogscope.FormatMarkdown)
+		assert.NoError(t, err)
+		doc := string(data)
+		assert.Contains(t, doc, "# Logging policy\n")
+		assert.Contains(t, doc, "| Log level | INFO |\n")
+		assert.Contains(t, doc, "| Source | file "+filename+" |\n")
+		assert.Contains(t, doc, "| DEBUG-4 | -8 | custom |\n")
+		assert.Contains(t, doc, "| INFO | 0 | standard |\n")
+		assert.Contains(t, doc, "| github.com/foo/bar/db | glob | DEBUG-4 | migrate: WARN |  |  | query tracing \\| issue #42 | file "+filename+":3 |\n")
+		assert.Contains(t, doc, "| !github.com/foo/bar/db/mock | glob | ERROR |")
+		assert.Contains(t, doc, "| pager | webhook | ERROR | all |\n")
+		assert.NotContains(t, doc, "secret.example.com")
+	})
+
+	t.Run("test html", func(t *testing.T) {
+		data, err := h.ExportDocs(slogscope.FormatHTML)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "<h2>Package rules</h2>")
+		assert.Contains(t, string(data), "<td>query tracing | issue #42</td>")
+		assert.Contains(t, string(data), "<td>!github.com/foo/bar/db/mock</td>")
+	})
+
+	t.Run("test unsupported format", func(t *testing.T) {
+		_, err := h.ExportDocs("pdf")
+		assert.EqualError(t, err, `unsupported documentation format: "pdf"`)
+	})
+}
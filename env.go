@@ -0,0 +1,133 @@
+package slogscope
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Environment variables used for the env config format.
+const (
+	envLogLevel = "SLOGSCOPE_LOG_LEVEL" // Global log level, e.g. SLOGSCOPE_LOG_LEVEL=INFO
+	envDelivery = "SLOGSCOPE_DELIVERY"  // Global delivery guarantee, e.g. SLOGSCOPE_DELIVERY=durable
+	envPackages = "SLOGSCOPE_PACKAGES"  // Package log levels, e.g. SLOGSCOPE_PACKAGES=github.com/foo/bar=DEBUG,github.com/foo/baz=ERROR
+	envPackage  = "SLOGSCOPE_PKG_"      // Prefix for a single package log level, e.g. SLOGSCOPE_PKG_github.com/foo/bar=DEBUG
+	envProfile  = "SLOGSCOPE_PROFILE"   // Active config profile, if HandlerOptions.Profile is not set.
+	envService  = "SLOGSCOPE_SERVICE"   // Service ID selecting the section of Config.Services, if HandlerOptions.ServiceID is not set.
+)
+
+// NewConfigFromEnv builds a Config from the environment variables SLOGSCOPE_LOG_LEVEL, SLOGSCOPE_DELIVERY,
+// SLOGSCOPE_PACKAGES and SLOGSCOPE_PKG_<package name>. Package log levels given via SLOGSCOPE_PKG_<package name>
+// take precedence over those given via SLOGSCOPE_PACKAGES. Without SLOGSCOPE_LOG_LEVEL, the log level defaults to INFO.
+//
+// Example:
+//
+//	SLOGSCOPE_LOG_LEVEL=INFO
+//	SLOGSCOPE_PACKAGES=github.com/foo/bar=DEBUG,github.com/foo/baz=ERROR
+//	SLOGSCOPE_PKG_github.com/foo/qux=WARN
+func NewConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		LogLevel: os.Getenv(envLogLevel),
+		Delivery: os.Getenv(envDelivery),
+	}
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = defaultLogLevel
+	}
+
+	if spec := os.Getenv(envPackages); spec != "" {
+		for _, entry := range strings.Split(spec, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, level, ok := strings.Cut(entry, "=")
+			if !ok || name == "" || level == "" {
+				return nil, fmt.Errorf("invalid package spec %q in %s: expected <package name>=<log level>", entry, envPackages)
+			}
+			cfg.Packages = append(cfg.Packages, Package{Name: strings.TrimSpace(name), LogLevel: strings.TrimSpace(level)})
+		}
+	}
+
+	var pkgs []Package
+	for _, kv := range os.Environ() {
+		key, level, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(key, envPackage); ok && name != "" {
+			pkgs = append(pkgs, Package{Name: name, LogLevel: level})
+		}
+	}
+	slices.SortFunc(pkgs, func(a, b Package) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	cfg.Packages = mergePackages(cfg.Packages, pkgs)
+
+	return cfg, nil
+}
+
+// marshalEnv returns the config as environment variable assignments, one per line.
+// The env format is limited to the global settings and the log levels of the packages.
+func marshalEnv(cfg Config) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s=%s\n", envLogLevel, cfg.LogLevel)
+	if cfg.Delivery != "" {
+		fmt.Fprintf(&b, "%s=%s\n", envDelivery, cfg.Delivery)
+	}
+	if len(cfg.Packages) > 0 {
+		specs := make([]string, len(cfg.Packages))
+		for i, p := range cfg.Packages {
+			specs[i] = p.Name + "=" + p.LogLevel
+		}
+		fmt.Fprintf(&b, "%s=%s\n", envPackages, strings.Join(specs, ","))
+	}
+	return []byte(b.String())
+}
+
+// expandEnv replaces the placeholders ${VAR} and ${VAR:default} with the value of the environment variable VAR.
+// If VAR is not set or empty, the default value or, without default, an empty string is used.
+// Placeholders are parsed without regular expressions, so they are available in the minimal build as well.
+func expandEnv(data []byte) []byte {
+	var out []byte
+	for {
+		i := bytes.Index(data, []byte("${"))
+		if i < 0 {
+			return append(out, data...)
+		}
+		name, def, n, ok := parseEnvVar(data[i+2:])
+		if !ok {
+			out = append(out, data[:i+2]...)
+			data = data[i+2:]
+			continue
+		}
+		out = append(out, data[:i]...)
+		if v := os.Getenv(name); v != "" {
+			out = append(out, v...)
+		} else {
+			out = append(out, def...)
+		}
+		data = data[i+2+n:]
+	}
+}
+
+// parseEnvVar parses the remainder of a placeholder following "${", i.e. "VAR}" or "VAR:default}", and returns the
+// variable name, the default value and the number of bytes consumed including the closing brace.
+func parseEnvVar(b []byte) (name string, def []byte, n int, ok bool) {
+	j := 0
+	for j < len(b) && (b[j] == '_' || 'a' <= b[j] && b[j] <= 'z' || 'A' <= b[j] && b[j] <= 'Z' || j > 0 && '0' <= b[j] && b[j] <= '9') {
+		j++
+	}
+	if j == 0 || j == len(b) {
+		return "", nil, 0, false
+	}
+	switch b[j] {
+	case '}':
+		return string(b[:j]), nil, j + 1, true
+	case ':':
+		k := bytes.IndexByte(b[j+1:], '}')
+		if k < 0 {
+			return "", nil, 0, false
+		}
+		return string(b[:j]), b[j+1 : j+1+k], j + k + 2, true
+	}
+	return "", nil, 0, false
+}
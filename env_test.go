@@ -0,0 +1,78 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigFromEnv(t *testing.T) {
+	t.Run("test config is built from environment variables", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_LOG_LEVEL", slogscope.LogLevelWarn)
+		t.Setenv("SLOGSCOPE_PACKAGES", "github.com/foo/bar=DEBUG, github.com/foo/baz=ERROR")
+		t.Setenv("SLOGSCOPE_PKG_github.com/foo/baz", slogscope.LogLevelInfo)
+
+		cfg, err := slogscope.NewConfigFromEnv()
+		assert.NoError(t, err)
+		assert.Equal(t, &slogscope.Config{
+			LogLevel: slogscope.LogLevelWarn,
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelInfo},
+			},
+		}, cfg)
+	})
+
+	t.Run("test default log level", func(t *testing.T) {
+		cfg, err := slogscope.NewConfigFromEnv()
+		assert.NoError(t, err)
+		assert.Equal(t, &slogscope.Config{LogLevel: slogscope.LogLevelInfo}, cfg)
+	})
+
+	t.Run("test invalid package spec", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_PACKAGES", "github.com/foo/bar")
+		_, err := slogscope.NewConfigFromEnv()
+		assert.Error(t, err)
+	})
+
+	t.Run("test handler uses config from environment", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_LOG_LEVEL", slogscope.LogLevelError)
+		t.Setenv("SLOGSCOPE_PKG_github.com/apperia-de/slogscope_test", slogscope.LogLevelDebug)
+
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{ConfigFromEnv: true})
+		slog.New(h).Debug("debug message")
+		assert.Contains(t, out.String(), "debug message")
+		assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+	})
+}
+
+func TestHandler_ConfigFileEnvExpansion(t *testing.T) {
+	requireFullBuild(t)
+
+	file := filepath.Join(t.TempDir(), "slogscope.yml")
+	assert.NoError(t, os.WriteFile(file, []byte(`log_level: ${TEST_LOG_LEVEL:INFO}
+packages:
+  - name: ${TEST_PACKAGE}
+    log_level: ${TEST_PACKAGE_LEVEL:WARN}
+`), 0644))
+
+	t.Setenv("TEST_PACKAGE", "github.com/foo/bar")
+	t.Setenv("TEST_PACKAGE_LEVEL", slogscope.LogLevelDebug)
+	cfg, err := slogscope.NewFileProvider(file).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug}},
+	}, cfg)
+
+	t.Setenv("TEST_LOG_LEVEL", slogscope.LogLevelError)
+	cfg, err = slogscope.NewFileProvider(file).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, slogscope.LogLevelError, cfg.LogLevel)
+}
@@ -0,0 +1,58 @@
+package slogscope
+
+import (
+	"fmt"
+	"time"
+)
+
+// parseExpires parses the expiry date of a package rule, which is either a date (e.g. 2025-08-01, which expires at
+// the beginning of the day in local time) or a timestamp in RFC 3339 format.
+func parseExpires(expires string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, expires, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, expires)
+	if err != nil {
+		return t, fmt.Errorf("invalid expiry date %q: expected YYYY-MM-DD or RFC 3339 timestamp", expires)
+	}
+	return t, nil
+}
+
+// activePackages returns all package rules, which are not expired yet. A warning is emitted once via the wrapped
+// slog.Handler for every expired rule. The configuration is reapplied as soon as the next rule expires.
+// The caller must hold ss.mu.
+func (ss *slogscope) activePackages(packages []Package) []Package {
+	if ss.expiryTimer != nil {
+		ss.expiryTimer.Stop()
+		ss.expiryTimer = nil
+	}
+
+	now := ss.now()
+	var next time.Time
+	active := make([]Package, 0, len(packages))
+	for _, p := range packages {
+		if p.Expires == "" {
+			active = append(active, p)
+			continue
+		}
+		expires, err := parseExpires(p.Expires)
+		if err != nil {
+			ss.logger.Debug(fmt.Sprintf("%s for package=%q -> rule never expires.", err.Error(), p.Name))
+			active = append(active, p)
+			continue
+		}
+		if !expires.After(now) {
+			ss.warnOnce("expired_rule:"+p.Name+"@"+p.Expires, "slogscope: ignoring expired package rule", "package", p.Name, "log_level", p.LogLevel, "expires", p.Expires)
+			continue
+		}
+		if next.IsZero() || expires.Before(next) {
+			next = expires
+		}
+		active = append(active, p)
+	}
+
+	if !next.IsZero() {
+		ss.expiryTimer = ss.reconfigureAt(next)
+	}
+	return active
+}
@@ -0,0 +1,81 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer, which may be written by the timers of the Handler while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func TestHandler_Expires(t *testing.T) {
+	t.Run("test expired rules are ignored with a warning", func(t *testing.T) {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelError,
+				Packages: []slogscope.Package{
+					{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug, Expires: "2020-01-01"},
+					{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug, Expires: "2999-01-01"},
+				},
+			},
+		})
+		assert.Contains(t, out.String(), "level=WARN")
+		assert.Contains(t, out.String(), "package=github.com/apperia-de/slogscope_test")
+		assert.Equal(t, []slogscope.PackageInfo{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug, Source: "HandlerOptions.Config"}}, h.GetPackages())
+
+		out.Reset()
+		slog.New(h).Debug("debug message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test rules expire at runtime", func(t *testing.T) {
+		var out syncBuffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelError,
+				Packages: []slogscope.Package{{
+					Name:     "github.com/apperia-de/slogscope_test",
+					LogLevel: slogscope.LogLevelDebug,
+					Expires:  time.Now().Add(100 * time.Millisecond).Format(time.RFC3339Nano),
+				}},
+			},
+		})
+		l := slog.New(h)
+		l.Debug("debug message")
+		assert.Contains(t, out.String(), "debug message")
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Contains(t, out.String(), "ignoring expired package rule")
+		out.Reset()
+		l.Debug("debug message")
+		assert.Empty(t, out.String())
+	})
+}
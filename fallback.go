@@ -0,0 +1,65 @@
+package slogscope
+
+import (
+	"slices"
+	"strings"
+)
+
+// defaultFallbackScope is the package name of records without a resolvable caller, if Config.FallbackScope is not set.
+const defaultFallbackScope = "unknown"
+
+// isUnresolvedPackage reports whether the package name of a caller could not be resolved to a sensible Go package,
+// e.g. for stripped frames or callbacks from C code via cgo.
+func isUnresolvedPackage(pkgName string) bool {
+	return pkgName == "" || pkgName == "?" || pkgName == "runtime" || strings.HasPrefix(pkgName, "_cgo")
+}
+
+// Stats contains diagnostic counters of the Handler.
+type Stats struct {
+	UnresolvedCallers uint64 `json:"unresolved_callers"` // Number of log calls attributed to Config.FallbackScope.
+	// UnavailableSinks describes the sinks of the config, which are disabled, because neither HandlerOptions.Sinks
+	// contains their handler nor their sink type is registered (see RegisterSinkType).
+	UnavailableSinks []string `json:"unavailable_sinks,omitempty"`
+	// Warnings contains the number of occurrences of internal warnings by key, e.g. "unknown_log_level:TRACE" or
+	// "nil_context". Every warning is emitted only on its first occurrence.
+	Warnings map[string]uint64 `json:"warnings,omitempty"`
+	// DroppedAttrs contains the number of attributes dropped by Package.AllowedKeys by package name.
+	DroppedAttrs map[string]uint64 `json:"dropped_attrs,omitempty"`
+	// DecisionCache contains the counters of the decision cache (see HandlerOptions.DecisionCacheSize).
+	DecisionCache DecisionCacheStats `json:"decision_cache"`
+	// DeadlineExceeded is the number of synchronous deliveries cut short by the deadline of the caller's context
+	// (see DeliveryOptions.RespectDeadline).
+	DeadlineExceeded uint64 `json:"deadline_exceeded"`
+	// SinkDrops contains the number of records dropped by sinks sending their records via a SinkQueue, e.g. Sentry and
+	// webhook sinks, by sink name, because their queue was full or the records couldn't be sent.
+	SinkDrops map[string]uint64 `json:"sink_drops,omitempty"`
+}
+
+// Stats returns the diagnostic counters of the Handler.
+func (h *Handler) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return Stats{
+		UnresolvedCallers: h.unresolved.Load(),
+		UnavailableSinks:  slices.Clone(h.unavailableSinks),
+		Warnings:          h.warnings.snapshot(),
+		DroppedAttrs:      h.droppedAttrsSnapshot(),
+		DecisionCache:     h.decisions.stats(),
+		DeadlineExceeded:  h.delivery.deadlineExceededCount(),
+		SinkDrops:         h.sinkDrops(),
+	}
+}
+
+// sinkDrops returns the number of dropped records of all configured sinks sending their records via a queue.
+func (h *Handler) sinkDrops() map[string]uint64 {
+	var drops map[string]uint64
+	for name, sh := range h.taps.sinkHandlers() {
+		if qs, ok := sh.(QueuedSink); ok {
+			if drops == nil {
+				drops = make(map[string]uint64)
+			}
+			drops[name] = qs.SinkQueue().Dropped()
+		}
+	}
+	return drops
+}
@@ -0,0 +1,59 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_FallbackScope(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel:      slogscope.LogLevelError,
+		FallbackScope: "cgo",
+		Packages:      []slogscope.Package{{Name: "cgo", LogLevel: slogscope.LogLevelDebug}},
+	}})
+
+	// Without any frames above the caller, the package of the caller can't be resolved.
+	done := make(chan bool)
+	go func() {
+		done <- h.Enabled(context.Background(), slog.LevelDebug)
+	}()
+	assert.True(t, <-done)
+	assert.Equal(t, uint64(1), h.Stats().UnresolvedCallers)
+
+	// Records without program counter are attributed to the fallback scope as well.
+	assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelDebug, "record without caller", 0)))
+	assert.Contains(t, out.String(), "record without caller")
+}
+
+func TestHandler_StatsWarnings(t *testing.T) {
+	var out bytes.Buffer
+	cfg := slogscope.Config{
+		LogLevel: "TRACE",
+		Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: "VERBOSE"}},
+	}
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &cfg})
+	h.UseConfig(cfg)
+	for range 3 {
+		//nolint:staticcheck // A nil context is the misconfiguration under test.
+		assert.NoError(t, h.Handle(nil, slog.NewRecord(time.Now(), slog.LevelError, "message", 0)))
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"unknown_log_level:TRACE":   2,
+		"unknown_log_level:VERBOSE": 2,
+		"nil_context":               3,
+		// Made-up packages are reported as well.
+		"unmatched_rule:github.com/foo/bar": 2,
+	}, h.Stats().Warnings)
+	assert.Equal(t, 1, strings.Count(out.String(), `log_level=TRACE replacement=INFO`))
+	assert.Equal(t, 1, strings.Count(out.String(), `log_level=VERBOSE replacement=INFO`))
+	assert.Equal(t, 1, strings.Count(out.String(), "Handle was called with a nil context"))
+}
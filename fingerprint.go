@@ -0,0 +1,50 @@
+package slogscope
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strconv"
+)
+
+// FingerprintKey is the attribute key of the fingerprint (see HandlerOptions.Fingerprint).
+const FingerprintKey = "fingerprint"
+
+// fingerprint returns the fingerprint attribute for the record logged from the given package.
+func (ss *slogscope) fingerprint(pkgName string, rec slog.Record) slog.Attr {
+	template := MessageTemplate
+	if ss.opts.Fingerprint.Template != nil {
+		template = ss.opts.Fingerprint.Template
+	}
+
+	f := fnv.New64a()
+	_, _ = fmt.Fprintf(f, "%s\x00%s\x00%s", pkgName, template(rec.Message), errorType(rec))
+	return slog.String(FingerprintKey, strconv.FormatUint(f.Sum64(), 16))
+}
+
+// fingerprintLevel returns the minimum log level of records getting a fingerprint.
+func (ss *slogscope) fingerprintLevel() slog.Level {
+	if ss.opts.Fingerprint.Level != nil {
+		return ss.opts.Fingerprint.Level.Level()
+	}
+	return slog.LevelWarn
+}
+
+// errorType returns the type of the innermost error of the first error attribute of the record,
+// or an empty string if the record does not contain an error.
+func errorType(rec slog.Record) string {
+	var typ string
+	rec.Attrs(func(a slog.Attr) bool {
+		err, ok := a.Value.Resolve().Any().(error)
+		if !ok {
+			return true
+		}
+		for next := errors.Unwrap(err); next != nil; next = errors.Unwrap(err) {
+			err = next
+		}
+		typ = fmt.Sprintf("%T", err)
+		return false
+	})
+	return typ
+}
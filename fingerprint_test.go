@@ -0,0 +1,78 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	requireFullBuild(t)
+
+	assert.Equal(t, `user ? not found in "?"`, slogscope.MessageTemplate(`user 42 not found in "eu-west"`))
+	assert.Equal(t, "request ? from ? took ?ms", slogscope.MessageTemplate("request 0x1f from 10.0.0.1 took 12.5ms"))
+	assert.Equal(t, "order ? failed", slogscope.MessageTemplate("order 123e4567-e89b-12d3-a456-426614174000 failed"))
+	assert.Equal(t, "user42 added", slogscope.MessageTemplate("user42 added"))
+}
+
+func TestHandler_Fingerprint(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{
+		Config:      &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+		Fingerprint: &slogscope.FingerprintOptions{},
+	})
+	l := slog.New(h)
+
+	fingerprint := func(log func()) any {
+		out.Reset()
+		log()
+		var rec map[string]any
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &rec))
+		return rec["fingerprint"]
+	}
+
+	t.Run("test same template and error type have the same fingerprint", func(t *testing.T) {
+		requireFullBuild(t)
+		fp1 := fingerprint(func() { l.Warn("user 1 not found", "error", fmt.Errorf("lookup: %w", fs.ErrNotExist)) })
+		fp2 := fingerprint(func() { l.Warn("user 2 not found", "error", fs.ErrNotExist) })
+		assert.NotEmpty(t, fp1)
+		assert.Equal(t, fp1, fp2)
+	})
+
+	t.Run("test different error types have different fingerprints", func(t *testing.T) {
+		fp1 := fingerprint(func() { l.Error("user 1 not found", "error", errors.New("not found")) })
+		fp2 := fingerprint(func() { l.Error("user 1 not found", "error", context.DeadlineExceeded) })
+		assert.NotEqual(t, fp1, fp2)
+	})
+
+	t.Run("test records below the log level have no fingerprint", func(t *testing.T) {
+		assert.Nil(t, fingerprint(func() { l.Info("user 1 not found") }))
+	})
+
+	t.Run("test custom template extraction", func(t *testing.T) {
+		h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+			Fingerprint: &slogscope.FingerprintOptions{
+				Level: slog.LevelInfo,
+				Template: func(msg string) string {
+					before, _, _ := strings.Cut(msg, ":")
+					return before
+				},
+			},
+		})
+		l := slog.New(h)
+		fp1 := fingerprint(func() { l.Info("cache miss: a") })
+		fp2 := fingerprint(func() { l.Info("cache miss: b") })
+		assert.NotEmpty(t, fp1)
+		assert.Equal(t, fp1, fp2)
+	})
+}
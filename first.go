@@ -0,0 +1,43 @@
+package slogscope
+
+import "sync"
+
+// maxFirstMessages limits the number of distinct messages counted per package for Package.First, so messages with
+// variable content, e.g. formatted IDs, can't exhaust the memory. Further messages are filtered as usual.
+const maxFirstMessages = 1000
+
+// occurrences counts the records of every distinct message of a package (see Package.First).
+type occurrences struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// take counts an occurrence of the message and reports whether it is one of the first n occurrences.
+func (o *occurrences) take(msg string, n int) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	c, ok := o.counts[msg]
+	if !ok && len(o.counts) >= maxFirstMessages {
+		return false
+	}
+	if c >= n {
+		return false
+	}
+	o.counts[msg] = c + 1
+	return true
+}
+
+// firstOccurrence reports whether the rule p, which may be nil, has a policy emitting the first records of every
+// distinct message regardless of the log level (see Package.First) and whether the message is one of these records.
+// The occurrences are counted by the package name, so they survive config reloads.
+func (ss *slogscope) firstOccurrence(p *pkg, pkgName, msg string) (policy, first bool) {
+	if p == nil || p.first <= 0 {
+		return false, false
+	}
+	v, ok := ss.occurrences.Load(pkgName)
+	if !ok {
+		v, _ = ss.occurrences.LoadOrStore(pkgName, &occurrences{counts: map[string]int{}})
+	}
+	return true, v.(*occurrences).take(msg, p.first)
+}
@@ -0,0 +1,40 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_FirstOccurrences(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelWarn, First: 2},
+			},
+		},
+	})
+	l := slog.New(h)
+
+	for range 5 {
+		l.Debug("connecting to database")
+		l.Info("config loaded")
+		l.Error("connection failed")
+	}
+	h.UseConfig(h.GetConfig())
+	l.Debug("connecting to database")
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "connecting to database"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "config loaded"))
+	assert.Equal(t, 5, strings.Count(buf.String(), "connection failed"))
+	assert.Empty(t, h.Stats().Warnings)
+
+	cfg := slogscope.Config{Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn, First: -1}}}
+	assert.ErrorContains(t, cfg.Validate(), "packages[0].first: must not be negative, got -1")
+}
@@ -0,0 +1,62 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// minimalBuild reports whether the package is built with the build tag slogscope_minimal.
+const minimalBuild = false
+
+// unmarshalYAML decodes YAML data into v. It is not available with the build tag slogscope_minimal.
+func unmarshalYAML(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
+// marshalYAML encodes v as YAML. It is not available with the build tag slogscope_minimal.
+func marshalYAML(v any) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+// compilePackageRegex compiles the name of a rule with MatchRegex, anchored to match whole package names only.
+// It is not available with the build tag slogscope_minimal.
+func compilePackageRegex(expr string) (stringMatcher, error) {
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package regex %q: %w", expr, err)
+	}
+	return re, nil
+}
+
+var (
+	quotedRe = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	numberRe = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]*[0-9][0-9a-fA-F]*([.:-][0-9a-fA-F]+)*`)
+)
+
+// MessageTemplate is the default template extraction strategy for fingerprints (see FingerprintOptions).
+// It replaces quoted strings and numeric tokens (numbers, hex values, UUIDs, IP addresses) with placeholders,
+// e.g. `user 42 not found in "eu-west"` becomes `user ? not found in "?"`.
+func MessageTemplate(msg string) string {
+	msg = quotedRe.ReplaceAllString(msg, `"?"`)
+	return numberRe.ReplaceAllString(msg, "?")
+}
+
+// listImports lists the root package and all packages it depends on via the go list command, one package per line
+// with its import path, module path and imports separated by tabs (see ImportTree).
+// It is not available with the build tag slogscope_minimal.
+func listImports(root string) (string, error) {
+	cmd := exec.Command("go", "list", "-e", "-deps", "-f", "{{.ImportPath}}\t{{with .Module}}{{.Path}}{{end}}\t{{join .Imports \" \"}}", root)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error listing imports of %s: %w: %s", root, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,18 @@
+//go:build !slogscope_minimal
+
+package slogscope_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+)
+
+// requireFullBuild skips tests, which need features excluded by the minimal build. All are available in this build.
+func requireFullBuild(*testing.T) {}
+
+// newAdminServer returns a test server exposing the admin endpoints of h, which are not available in the minimal build.
+func newAdminServer(_ *testing.T, h *slogscope.Handler) *httptest.Server {
+	return httptest.NewServer(h.AdminHandler())
+}
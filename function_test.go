@@ -0,0 +1,81 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func logFromOtherFunction(l *slog.Logger, msg string) {
+	l.Debug(msg)
+}
+
+func TestHandler_FunctionLevels(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{
+				Name:      "github.com/apperia-de/slogscope_test",
+				LogLevel:  slogscope.LogLevelInfo,
+				Functions: []slogscope.Function{{Name: "TestHandler_FunctionLevels", LogLevel: slogscope.LogLevelDebug}},
+			}},
+		},
+	})
+	l := slog.New(h)
+
+	l.Debug("debug from function")
+	func() { l.Debug("debug from closure") }()
+	logFromOtherFunction(l, "debug from other function")
+
+	assert.Contains(t, buf.String(), "debug from function")
+	assert.Contains(t, buf.String(), "debug from closure")
+	assert.NotContains(t, buf.String(), "debug from other function")
+	assert.Empty(t, h.Stats().Warnings)
+
+	cfg := slogscope.Config{Packages: []slogscope.Package{{
+		Name:      "github.com/foo/bar",
+		LogLevel:  slogscope.LogLevelInfo,
+		Functions: []slogscope.Function{{LogLevel: "LOUD"}},
+	}}}
+	assert.EqualError(t, cfg.Validate(), `invalid config (2 problems): packages[0].functions[0].name: must not be empty; `+
+		`packages[0].functions[0].log_level: invalid log level "LOUD": expected DEBUG, INFO, WARN or ERROR with an optional offset, e.g. DEBUG-2`)
+}
+
+type worker struct{ l *slog.Logger }
+
+func (w *worker) run(msg string) {
+	w.l.Debug(msg)
+	func() { w.l.Debug(msg + " from closure") }()
+}
+
+type scheduler struct{ l *slog.Logger }
+
+func (s scheduler) run(msg string) { s.l.Debug(msg) }
+
+func TestHandler_ReceiverLevels(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{
+				Name:      "github.com/apperia-de/slogscope_test",
+				LogLevel:  slogscope.LogLevelInfo,
+				Receivers: []slogscope.Function{{Name: "(*worker)", LogLevel: slogscope.LogLevelDebug}},
+			}},
+		},
+	})
+	l := slog.New(h)
+
+	(&worker{l: l}).run("debug from worker")
+	scheduler{l: l}.run("debug from scheduler")
+	logFromOtherFunction(l, "debug from function")
+
+	assert.Contains(t, buf.String(), "debug from worker")
+	assert.Contains(t, buf.String(), "debug from worker from closure")
+	assert.NotContains(t, buf.String(), "debug from scheduler")
+	assert.NotContains(t, buf.String(), "debug from function")
+}
@@ -0,0 +1,66 @@
+package slogscope
+
+import (
+	"path"
+	"strings"
+)
+
+// Available scopes for records logged from generated code (see Config.Generated).
+const (
+	GeneratedScopePackage   = "package"   // Generated code is treated like any other code of its package (default).
+	GeneratedScopeParent    = "parent"    // Records of generated packages, e.g. protobuf packages, are attributed to the parent package.
+	GeneratedScopeDedicated = "generated" // Records of generated code are attributed to the dedicated package name "generated".
+)
+
+// generatedPackageName is the package name of records logged from generated code with GeneratedScopeDedicated.
+const generatedPackageName = "generated"
+
+// isGeneratedFile reports whether the file name matches one of the common markers of generated code.
+func isGeneratedFile(file string) bool {
+	name := path.Base(file)
+	return strings.HasPrefix(name, "zz_generated") ||
+		strings.HasSuffix(name, ".pb.go") ||
+		strings.HasSuffix(name, ".pb.gw.go") ||
+		strings.HasSuffix(name, ".gen.go") ||
+		strings.HasSuffix(name, "_gen.go") ||
+		strings.HasSuffix(name, "_generated.go")
+}
+
+// isGeneratedPackageFile reports whether the generated file usually lives in a package of its own, like the code
+// generated by protoc, in contrast to e.g. zz_generated.deepcopy.go living next to hand-written code.
+func isGeneratedPackageFile(file string) bool {
+	name := path.Base(file)
+	return strings.HasSuffix(name, ".pb.go") || strings.HasSuffix(name, ".pb.gw.go")
+}
+
+// scope returns the package name, which is used for matching the package rules of a record logged from the given
+// file of the given package (see Config.Generated, Config.FallbackScope and Handler.RegisterScope).
+func (ss *slogscope) scope(pkgName, file string) string {
+	st := ss.settings.Load()
+	if isUnresolvedPackage(pkgName) {
+		return st.fallbackScope
+	}
+	if name, ok := ss.scopes.Load(pkgName); ok {
+		return name.(string)
+	}
+	if st.generated == "" || st.generated == GeneratedScopePackage || !isGeneratedFile(file) {
+		return pkgName
+	}
+	if st.generated == GeneratedScopeDedicated {
+		return generatedPackageName
+	}
+	if isGeneratedPackageFile(file) && strings.Contains(pkgName, "/") {
+		return path.Dir(pkgName)
+	}
+	return pkgName
+}
+
+// scopeOf returns the package name (see scope), the function name and the file path for a program counter, e.g.
+// slog.Record.PC.
+func (ss *slogscope) scopeOf(pc uintptr) (pkgName, funcName, file string) {
+	if pc == 0 {
+		return ss.settings.Load().fallbackScope, "", ""
+	}
+	c := ss.callerOf(pc)
+	return ss.scope(c.pkgName, c.file), c.funcName, c.file
+}
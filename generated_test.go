@@ -0,0 +1,50 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Generated(t *testing.T) {
+	newLogger := func(generated string) (*slog.Logger, *bytes.Buffer) {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+			LogLevel:  slogscope.LogLevelInfo,
+			Generated: generated,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelError},
+			},
+		}})
+		return slog.New(h), &out
+	}
+
+	t.Run("test generated code is treated like its package by default", func(t *testing.T) {
+		l, out := newLogger("")
+		logFromGeneratedCode(l, "generated message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test generated code within a hand-written package is not moved to the parent package", func(t *testing.T) {
+		l, out := newLogger(slogscope.GeneratedScopeParent)
+		logFromGeneratedCode(l, "generated message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test generated code is attributed to the dedicated scope", func(t *testing.T) {
+		l, out := newLogger(slogscope.GeneratedScopeDedicated)
+		logFromGeneratedCode(l, "generated message")
+		assert.Contains(t, out.String(), "generated message")
+
+		out.Reset()
+		l.Info("hand-written message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test invalid scope", func(t *testing.T) {
+		assert.Error(t, slogscope.Config{Generated: "elsewhere"}.Validate())
+	})
+}
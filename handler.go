@@ -2,16 +2,25 @@ package slogscope
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
-	"regexp"
+	"os"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Handler struct {
 	*slogscope
+	next slog.Handler // Derived handler of the wrapped slog.Handler (see WithAttrs and WithGroup), nil for the root.
+	// scopeName is the explicit scope name used instead of the package of the caller (see Scope).
+	scopeName string
+	groups    string // Groups of the handler joined by dots for matching rules (see MatchGroup).
 }
 
 // NewHandler creates a new slog.Handler
@@ -23,7 +32,11 @@ func NewHandler(h slog.Handler, opts *HandlerOptions) *Handler {
 	}
 
 	if o.ConfigFile == "" {
-		o.ConfigFile = defaultConfigFile
+		o.ConfigFile = searchConfigFile()
+	}
+
+	if o.InstanceID == "" {
+		o.InstanceID = defaultInstanceID()
 	}
 
 	logger := slog.New(NewNilHandler())
@@ -43,10 +56,53 @@ func NewHandler(h slog.Handler, opts *HandlerOptions) *Handler {
 		}
 	}
 
-	ss := &slogscope{logger: logger, slogh: h, opts: &o}
-	defer ss.initHandler()
+	var deliveryOpts DeliveryOptions
+	if o.Delivery != nil {
+		deliveryOpts = *o.Delivery
+	}
+
+	ss := &slogscope{
+		logger:    logger,
+		slogh:     h,
+		opts:      &o,
+		delivery:  newDeliverer(deliveryOpts, logger),
+		decisions: newDecisionCache(o.DecisionCacheSize),
+		closeCh:   make(chan struct{}),
+	}
+
+	// A Config shared by a parent process (see StartCommand) takes precedence over the config file.
+	var parentPipe *os.File
+	if ss.opts.Config != nil {
+		ss.prov = provenance{source: "HandlerOptions.Config"}
+	} else {
+		parentPipe = ss.inheritConfig()
+	}
+
+	if ss.opts.Config == nil && ss.opts.ConfigReader != nil {
+		cfg, err := NewConfigFromReader(ss.opts.ConfigReader)
+		if err != nil {
+			// The reader replaces the config file, so neither load nor create one.
+			logger.Debug(fmt.Sprintf("error reading config: %s! -> fallback to the default config.", err.Error()))
+			ss.opts.Config = &Config{LogLevel: defaultLogLevel}
+			ss.prov = provenance{source: "default config"}
+		} else {
+			ss.opts.Config = cfg
+			ss.prov = provenance{source: "HandlerOptions.ConfigReader"}
+		}
+	}
+
+	if ss.opts.Config == nil && ss.opts.ConfigFromEnv {
+		cfg, err := NewConfigFromEnv()
+		if err != nil {
+			logger.Debug(fmt.Sprintf("error building config from environment: %s", err.Error()))
+		} else {
+			ss.prov = envProvenance(*cfg)
+		}
+		ss.opts.Config = cfg
+	}
 
-	// We load the HandlerOptions.Config from a config file if no HandlerOptions.Config is provided.
+	// We load the HandlerOptions.Config from the config provider (by default the config file)
+	// if no HandlerOptions.Config is provided.
 	if ss.opts.Config == nil && ss.opts.ConfigFile != "" {
 		ss.loadConfig()
 	}
@@ -56,92 +112,665 @@ func NewHandler(h slog.Handler, opts *HandlerOptions) *Handler {
 	}
 	ssHndl.h = ssHndl
 
+	if parentPipe != nil {
+		go ssHndl.followConfig(parentPipe)
+	}
+
+	ss.initHandler()
+	ssHndl.restoreSessions()
+	if ss.opts.ReloadOnSIGHUP {
+		ssHndl.reloadOnSIGHUP()
+	}
+	if ss.opts.DebugOnSIGUSR1 > 0 {
+		ssHndl.debugOnSIGUSR1(ss.opts.DebugOnSIGUSR1)
+	}
+	if deliveryOpts.FlushOnSignal && deliveryOpts.QueueSize > 0 {
+		ssHndl.flushOnSignal()
+	}
+	if ss.opts.WarmUp {
+		if _, err := ssHndl.WarmUp(); err != nil {
+			logger.Debug(fmt.Sprintf("%s! -> warm-up is skipped.", err.Error()))
+		}
+	}
 	return ssHndl
 }
 
 func (h *Handler) Enabled(_ context.Context, lvl slog.Level) bool {
-	cInfo := getCallerInfo(5)
-	if v, ok := h.pkgMap.Load(cInfo.PackageName); ok {
-		p := v.(*pkg)
-		if lvl >= p.logLevel {
-			h.logger.Debug(fmt.Sprintf("use package log level=%q for package=%q", lvl, p.name))
-			return true
-		}
-		return false
+	if h.scopeName != "" {
+		h.observe(h.scopeName)
+		enabled, first := h.decide(h.scopeName, "", "", lvl)
+		return enabled || first || h.taps.enabled(h.scopeName, lvl)
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(5, pcs[:])
+	key := decisionKey{pc: pcs[0], level: lvl, groups: h.groups}
+	d, ok := h.decisions.load(key)
+	if !ok {
+		d = &cachedDecision{gen: h.decisions.generation()}
+		c := h.callerOf(pcs[0])
+		d.unresolved = isUnresolvedPackage(c.pkgName)
+		d.pkgName = h.scope(c.pkgName, c.file)
+		d.enabled, d.first = h.decide(d.pkgName, c.funcName, c.file, lvl)
+		h.decisions.store(key, d)
+	}
+	if d.unresolved {
+		h.unresolved.Add(1)
+	}
+	h.observe(d.pkgName)
+	return d.enabled || d.first || h.taps.enabled(d.pkgName, lvl)
+}
+
+// decide reports whether the log level of the rule matching the caller or, if there is none, the global log level
+// enables records of the given log level, and whether they may be enabled for the first occurrences of their message.
+// The message is known in Handle only (see Package.First).
+func (h *Handler) decide(pkgName, funcName, file string, lvl slog.Level) (enabled, first bool) {
+	if p, ok := h.callerRule(pkgName, file, h.groups); ok {
+		h.logger.Debug(fmt.Sprintf("use package log level=%q for package=%q", p.logLevel, p.name))
+		return lvl >= p.funcLevel(funcName), p.first > 0
 	}
-	h.logger.Debug(fmt.Sprintf("use global log level=%q for package=%q", h.logLvl, cInfo.PackageName))
-	return lvl >= h.logLvl
+	logLvl := h.settings.Load().logLvl
+	h.logger.Debug(fmt.Sprintf("use global log level=%q for package=%q", logLvl, pkgName))
+	return lvl >= logLvl, false
 }
 
 func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
-	return h.slogh.Handle(ctx, rec)
+	if ctx == nil {
+		h.warnOnce("nil_context", "slogscope: Handle was called with a nil context", "hint", "pass context.Background() or the context of the request")
+		ctx = context.Background()
+	}
+	pkgName, funcName, file := h.scopeName, "", ""
+	if pkgName == "" {
+		pkgName, funcName, file = h.scopeOf(rec.PC)
+	}
+	rule, ok := h.callerRule(pkgName, file, h.groups)
+	h.checkLevel(rule, pkgName, rec)
+	rec = h.allowKeys(rule, pkgName, rec)
+	st := h.settings.Load()
+	if st.normalizer != nil {
+		rec = st.normalizer.normalize(rec)
+	}
+	if st.schemaVersion != "" {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.String(schemaVersionAttrKey, st.schemaVersion))
+	}
+	if len(st.metadata) > 0 {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.Attr{Key: "instance", Value: slog.GroupValue(st.metadata...)})
+	}
+	if st.buildInfo && rec.Level >= st.buildInfoLvl {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.Attr{Key: "build", Value: slog.GroupValue(getBuildAttrs()...)})
+	}
+	if ok && rule.runtime && rec.Level >= slog.LevelError {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.Attr{Key: "runtime", Value: slog.GroupValue(h.runtime.get()...)})
+	}
+	if st.overrideSession != "" {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.String(overrideAttrKey, st.overrideSession))
+	}
+	if h.opts.Fingerprint != nil && rec.Level >= h.fingerprintLevel() {
+		rec = rec.Clone()
+		rec.AddAttrs(h.fingerprint(pkgName, rec))
+	}
+	if st.renamer != nil {
+		rec = st.renamer.rename(rec)
+	}
+	tapped := h.taps.cnt.Load() > 0
+	if tapped {
+		h.taps.handle(ctx, pkgName, rec)
+	}
+	lvl, durable := st.logLvl, st.durable
+	if ok {
+		lvl, durable = rule.funcLevel(funcName), rule.durable
+	}
+	policy, first := h.firstOccurrence(rule, pkgName, rec.Message)
+	if rec.Level < lvl && !first {
+		// Records may only have been enabled for a tap or for the first occurrences of their message.
+		if !tapped && !policy {
+			h.warnBypass(pkgName, rec.Level, lvl)
+		}
+		return nil
+	}
+	h.handled.Add(1)
+	if h.mirrors.cnt.Load() > 0 {
+		h.mirrors.handle(ctx, pkgName, rec)
+	}
+	if durable {
+		return h.delivery.deliver(ctx, h.handler(), rec, pkgName)
+	}
+	return h.handler().Handle(ctx, rec)
 }
 
+// WithAttrs returns a Handler for the wrapped slog.Handler with the given attributes, which is still scoped by the
+// same config.
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h.slogh.WithAttrs(attrs)
+	if len(attrs) == 0 {
+		return h
+	}
+	return &Handler{slogscope: h.slogscope, next: h.handler().WithAttrs(attrs), scopeName: h.scopeName, groups: h.groups}
 }
 
+// WithGroup returns a Handler for the wrapped slog.Handler with the given group, which is still scoped by the
+// same config. The group is retained for matching rules (see MatchGroup).
 func (h *Handler) WithGroup(name string) slog.Handler {
-	return h.slogh.WithGroup(name)
+	if name == "" {
+		return h
+	}
+	groups := name
+	if h.groups != "" {
+		groups = h.groups + "." + name
+	}
+	return &Handler{slogscope: h.slogscope, next: h.handler().WithGroup(name), scopeName: h.scopeName, groups: groups}
+}
+
+// handler returns the wrapped slog.Handler, which is derived from the one given to NewHandler by WithAttrs and
+// WithGroup.
+func (h *Handler) handler() slog.Handler {
+	if h.next != nil {
+		return h.next
+	}
+	return h.slogh
+}
+
+// warnBypass emits a one-time warning for a record below the log level of its package, which was passed to Handle
+// without being enabled by Enabled, e.g. by a wrapping slog.Handler not calling Enabled. The record is dropped, so
+// the scoping still applies.
+func (h *Handler) warnBypass(pkgName string, lvl, pkgLvl slog.Level) {
+	h.warnOnce("bypassed_enabled", "slogscope: record below the package log level was passed to Handle without Enabled",
+		"package", pkgName,
+		"level", lvl,
+		"log_level", pkgLvl,
+		"hint", "wrapping handlers must call Enabled of the slogscope.Handler before Handle and derive handlers via its WithAttrs and WithGroup",
+	)
+}
+
+// Flush blocks until all records queued by the asynchronous delivery mode (see DeliveryOptions.QueueSize)
+// are either delivered or written to the dead letter file. It also waits for the records queued by the configured
+// sinks, which send their records via a SinkQueue.
+func (h *Handler) Flush() {
+	h.delivery.flush()
+	for _, sh := range h.taps.sinkHandlers() {
+		if qs, ok := sh.(QueuedSink); ok {
+			qs.SinkQueue().Flush()
+		}
+	}
+}
+
+// Close stops the file watcher and the signal handlers (see HandlerOptions.ReloadOnSIGHUP and
+// HandlerOptions.DebugOnSIGUSR1), reverts an active debug session started by SIGUSR1 and stops sharing config changes
+// with child processes (see StartCommand). The Handler keeps handling records with its current config afterwards.
+// Closing a Handler more than once has no effect.
+func (h *Handler) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.closeCh)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.doneCh != nil {
+			close(h.doneCh)
+			h.doneCh = nil
+		}
+		h.children.close()
+	})
+	return nil
 }
 
 // GetConfig returns the current configuration, which may be adjusted and then used with UseConfig(cfg Config).
 func (h *Handler) GetConfig() Config {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return *h.opts.Config
 }
 
+// EffectiveConfig returns the configuration currently in force: the service section, the active profile, rollout,
+// maintenance mode, verbosity (see HandlerOptions.Verbosity) and quiet hours are merged into it, expired package rules
+// are removed and log levels are normalized, e.g. "debug" to "DEBUG" or "" to "INFO".
+// In contrast to GetConfig, the sections selecting settings per instance, e.g. the rollout, are resolved for this
+// instance and removed, so it can be diffed against the state expected for this instance.
+func (h *Handler) EffectiveConfig() Config {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.effectiveConfig()
+}
+
+// effectiveConfig returns the configuration currently in force (see Handler.EffectiveConfig).
+// The caller must hold ss.mu.
+func (ss *slogscope) effectiveConfig() Config {
+	cfg, _, _ := withQuietHours(ss.applyMaintenance(ss.applyRollout(ss.applyProfile(ss.applyService(*ss.opts.Config)))).WithVerbosity(ss.opts.Verbosity), ss.now())
+	cfg.Rollout, cfg.Profiles, cfg.Maintenance, cfg.Services = nil, nil, nil, nil
+	lvl, _ := parseLogLevel(cfg.LogLevel)
+	cfg.LogLevel = lvl.String()
+
+	packages := make([]Package, 0, len(cfg.Packages))
+	for _, p := range cfg.Packages {
+		if p.Expires != "" {
+			if expires, err := parseExpires(p.Expires); err == nil && !expires.After(ss.now()) {
+				continue
+			}
+		}
+		lvl, _ := parseLogLevel(p.LogLevel)
+		p.LogLevel = lvl.String()
+		packages = append(packages, p)
+	}
+	if len(packages) > 0 {
+		cfg.Packages = packages
+	} else {
+		cfg.Packages = nil
+	}
+	return cfg
+}
+
+// ExportConfig returns the effective configuration (see EffectiveConfig) as a document of the current ConfigVersion
+// encoded in the given format, which can be one of FormatYAML, FormatJSON, FormatTOML or FormatEnv.
+func (h *Handler) ExportConfig(format string) ([]byte, error) {
+	cfg := h.EffectiveConfig()
+	cfg.Version = ConfigVersion
+	switch strings.ToLower(format) {
+	case FormatYAML, "yml":
+		return marshalYAML(cfg)
+	case FormatJSON:
+		return json.MarshalIndent(cfg, "", "  ")
+	case FormatTOML:
+		return marshalTOML(cfg)
+	case FormatEnv:
+		return marshalEnv(cfg), nil
+	}
+	return nil, fmt.Errorf("unsupported config format: %q", format)
+}
+
+// GetPackages returns all configured and observed packages sorted by name, together with their effective log level
+// and the number of log calls observed from them.
+func (h *Handler) GetPackages() []PackageInfo {
+	infos := map[string]*PackageInfo{}
+	h.pkgMap.Range(func(k, v any) bool {
+		p := v.(*pkg)
+		infos[p.name] = &PackageInfo{Name: p.name, LogLevel: p.logLevel.String(), Description: p.description, Source: p.source}
+		return true
+	})
+	h.observed.Range(func(k, v any) bool {
+		name := k.(string)
+		if _, ok := infos[name]; !ok {
+			infos[name] = &PackageInfo{Name: name, LogLevel: h.levelFor(name).String()}
+		}
+		infos[name].Observed = v.(*atomic.Uint64).Load()
+		return true
+	})
+
+	packages := make([]PackageInfo, 0, len(infos))
+	for _, info := range infos {
+		packages = append(packages, *info)
+	}
+	slices.SortFunc(packages, func(a, b PackageInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return packages
+}
+
+// overrideAttrKey is the key of the attribute naming the active override session (see HandlerOptions.AnnotateOverrides).
+const overrideAttrKey = "slogscope.override"
+
+// schemaVersionAttrKey is the key of the attribute containing Config.SchemaVersion.
+const schemaVersionAttrKey = "schema_version"
+
+// defaultOverrideSession is the name of override sessions started without a name.
+const defaultOverrideSession = "temporary"
+
+// UsePackageLevelTemporarily sets the log level of a single package and reverts to the previous configuration
+// after revert amount of time has elapsed (see UseConfigTemporarily). The returned function reverts earlier.
+func (h *Handler) UsePackageLevelTemporarily(name, level string, revert time.Duration) (cancel func()) {
+	return h.usePackageLevelTemporarily(name, level, revert, "UsePackageLevelTemporarily", defaultOverrideSession)
+}
+
+// usePackageLevelTemporarily is UsePackageLevelTemporarily, recording origin as the source of the package rule and
+// session as the name of the override session.
+func (h *Handler) usePackageLevelTemporarily(name, level string, revert time.Duration, origin, session string) func() {
+	source := fmt.Sprintf("%s until %s", origin, time.Now().Add(revert).Format(time.RFC3339))
+	return h.updateTemporarily(revert, func(cfg Config, prov provenance) (Config, provenance) {
+		prov = prov.with(name, source)
+		prov.session = session
+		return withPackageLevel(cfg, name, level), prov
+	})
+}
+
+// SetPackageLevelFor sets the log level of a single package for the given duration, e.g. DEBUG for the payments
+// package during an incident. In contrast to UsePackageLevelTemporarily, only the package rule is reverted afterward,
+// even while temporary configs applied after it are still active, so changes of other packages in the meantime are
+// kept. If the package rule was changed in the meantime as well, it isn't reverted. If the config file was watched,
+// it is reloaded as soon as no temporary config is active anymore. The returned function reverts earlier.
+func (h *Handler) SetPackageLevelFor(name, level string, d time.Duration) (cancel func()) {
+	return h.setPackageLevelFor(name, level, d, fmt.Sprintf("SetPackageLevelFor until %s", time.Now().Add(d).Format(time.RFC3339)), defaultOverrideSession)
+}
+
+// setPackageLevelFor is SetPackageLevelFor, recording source as the source of the package rule and session as the name
+// of the override session. The override session is persisted until it reverts (see HandlerOptions.SessionStore). The
+// returned function reverts immediately.
+func (h *Handler) setPackageLevelFor(name, level string, d time.Duration, source, session string) func() {
+	update := func(cfg Config, prov provenance) (Config, provenance) {
+		prov = prov.with(name, source)
+		prov.session = session
+		return withPackageLevel(cfg, name, level), prov
+	}
+	return h.useTemporarily(&temporaryConfig{pkg: name, level: level}, update, d, func(cfg Config, _ provenance) OverrideSession {
+		return OverrideSession{
+			Name:    session,
+			Source:  source,
+			Sources: map[string]string{name: source},
+			Config:  cfg,
+			Package: name,
+			Expires: time.Now().Add(d),
+		}
+	})
+}
+
+// SetPackageLevel sets the log level of a single package, keeping the rest of the current configuration. Like
+// UseConfig, it disables any active file watcher and the change is persisted (see HandlerOptions.PersistChanges).
+func (h *Handler) SetPackageLevel(name, level string) {
+	h.setPackageLevel(name, level, "SetPackageLevel")
+}
+
+// setPackageLevel is SetPackageLevel, recording origin as the source of the package rule.
+func (h *Handler) setPackageLevel(name, level, origin string) {
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	cfg := h.updateConfig(func(cfg Config, prov provenance) (Config, provenance) {
+		return withPackageLevel(cfg, name, level), prov.with(name, origin)
+	})
+	h.persist(cfg)
+}
+
+// SetGlobalLevel sets the global log level, keeping the package rules and the rest of the current configuration. Like
+// UseConfig, it disables any active file watcher and the change is persisted (see HandlerOptions.PersistChanges).
+// A log level of the active service, profile, rollout or maintenance section still takes precedence.
+func (h *Handler) SetGlobalLevel(level slog.Level) {
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	cfg := h.updateConfig(func(cfg Config, prov provenance) (Config, provenance) {
+		prov = prov.withGlobal("SetGlobalLevel", cfg.Packages)
+		cfg.LogLevel = level.String()
+		return cfg, prov
+	})
+	h.persist(cfg)
+}
+
+// updateConfig applies update to the current config, e.g. to change the log level of a single package, and disables
+// any active file watcher. While temporary configs are active, update is applied to each of them and to the config
+// before them as well, so the change is kept when they are reverted (see UseConfigTemporarily). It returns the updated
+// config without the temporary configs, which is the one to persist. The caller must hold h.updateMu.
+func (h *Handler) updateConfig(update func(Config, provenance) (Config, provenance)) Config {
+	h.mu.Lock()
+	cfg, prov := update(*h.opts.Config, h.prov)
+	permanent := cfg
+	for i, t := range h.temporaries {
+		t.cfg, t.prov = update(t.cfg, t.prov)
+		t.base, t.baseProv = update(t.base, t.baseProv)
+		if i == 0 {
+			// The change disables the file watcher, so reverting restores the updated config instead of reloading.
+			t.fileWatcher = false
+			permanent = t.base
+		}
+	}
+	h.opts.EnableFileWatcher = false
+	h.opts.Config = &cfg
+	h.prov = prov
+	h.mu.Unlock()
+
+	h.initHandler()
+	h.logger.Debug(fmt.Sprintf("using config: %#v", cfg))
+	return permanent
+}
+
+// GetGlobalLevel returns the global log level in force, which applies to all packages without a package rule.
+func (h *Handler) GetGlobalLevel() slog.Level {
+	return h.settings.Load().logLvl
+}
+
+// withPackageLevel returns the config with the log level of the package rule replaced or, if there is none, with a
+// package rule added.
+func withPackageLevel(cfg Config, name, level string) Config {
+	cfg.Packages = slices.Clone(cfg.Packages)
+	if i := slices.IndexFunc(cfg.Packages, func(p Package) bool { return p.Name == name }); i >= 0 {
+		cfg.Packages[i].LogLevel = level
+	} else {
+		cfg.Packages = append(cfg.Packages, Package{Name: name, LogLevel: level})
+	}
+	return cfg
+}
+
 // UseConfig takes a new Config and immediately applies it to the current configuration.
-// It also disables any active file watcher.
+// It also disables any active file watcher and replaces any active temporary configs, so reverting them later doesn't
+// restore the config before them (see UseConfigTemporarily).
 func (h *Handler) UseConfig(cfg Config) {
+	h.useConfig(cfg, provenance{source: "UseConfig"})
+	h.persist(cfg)
+}
+
+// useConfig is UseConfig, recording prov as the provenance of the config.
+func (h *Handler) useConfig(cfg Config, prov provenance) {
 	h.mu.Lock()
+	h.temporaries = nil
 	h.opts.EnableFileWatcher = false
 	h.opts.Config = &cfg
+	h.prov = prov
 	h.mu.Unlock()
 
 	h.initHandler()
-	h.logger.Debug(fmt.Sprintf("using config: %#v", *h.opts.Config))
+	h.logger.Debug(fmt.Sprintf("using config: %#v", cfg))
 }
 
 // UseConfigTemporarily takes a new Config and immediately applies it to the current configuration.
 // In contrast to UseConfig(cfg	Config), this function automatically reverts to the state before calling the method,
-// after revert amount of time has elapsed.
-func (h *Handler) UseConfigTemporarily(cfg Config, revert time.Duration) {
-	h.mu.Lock()
-	oldCfg := h.GetConfig()
-	enableFileWatcher := h.opts.EnableFileWatcher
+// after revert amount of time has elapsed. The returned function reverts earlier.
+// Nested temporary configs are reverted in LIFO order: a temporary config reverted while another one applied after it
+// is still active is only reverted together with that one, so reverting never resurrects an already reverted config.
+// Package rules of SetPackageLevelFor applied after it don't keep it active, they are carried over instead.
+// Changes of a part of the config in the meantime, e.g. via SetPackageLevel or SetGlobalLevel, apply to the active
+// temporary configs and are kept when they are reverted, while UseConfig, UseConfigFile and Reload replace them.
+func (h *Handler) UseConfigTemporarily(cfg Config, revert time.Duration) (cancel func()) {
+	return h.useConfigTemporarily(cfg, revert, provenance{
+		source:  "UseConfigTemporarily until " + time.Now().Add(revert).Format(time.RFC3339),
+		session: defaultOverrideSession,
+	})
+}
 
+// useConfigTemporarily is UseConfigTemporarily, recording prov as the provenance of the config. The override session
+// is persisted until it reverts (see HandlerOptions.SessionStore). The returned function reverts immediately.
+func (h *Handler) useConfigTemporarily(cfg Config, revert time.Duration, prov provenance) func() {
+	return h.updateTemporarily(revert, func(Config, provenance) (Config, provenance) {
+		return cfg, prov
+	})
+}
+
+// updateTemporarily is useConfigTemporarily for the config and provenance returned by update, which is applied to the
+// current ones while holding h.updateMu, so concurrent temporary changes build on each other (see applyTemporarily).
+func (h *Handler) updateTemporarily(revert time.Duration, update func(Config, provenance) (Config, provenance)) func() {
+	return h.useTemporarily(&temporaryConfig{}, update, revert, func(cfg Config, prov provenance) OverrideSession {
+		return OverrideSession{
+			Name:    prov.session,
+			Source:  prov.source,
+			Sources: prov.packages,
+			Config:  cfg,
+			Expires: time.Now().Add(revert),
+		}
+	})
+}
+
+// useTemporarily applies the temporary config t built by update (see applyTemporarily) and persists the override
+// session returned by session for it, until both are reverted after revert amount of time. The returned function
+// reverts immediately.
+func (h *Handler) useTemporarily(t *temporaryConfig, update func(Config, provenance) (Config, provenance), revert time.Duration, session func(Config, provenance) OverrideSession) func() {
+	cfg, prov, revertConfig := h.applyTemporarily(t, update)
+	endSession := h.startSession(session(cfg, prov))
+	revertNow := sync.OnceFunc(func() {
+		endSession()
+		revertConfig()
+	})
+	timer := time.AfterFunc(revert, revertNow)
+	return func() {
+		timer.Stop()
+		revertNow()
+	}
+}
+
+// temporaryConfig is a temporary config on the stack of active temporary configs (see UseConfigTemporarily).
+type temporaryConfig struct {
+	cfg  Config
+	prov provenance
+	// pkg is the package elevated to level by SetPackageLevelFor, whose rule is reverted alone, empty if the whole
+	// config is reverted.
+	pkg   string
+	level string
+	// State before the temporary config was applied, which is restored when the stack becomes empty.
+	base        Config
+	baseProv    provenance
+	fileWatcher bool
+	reverted    bool
+}
+
+// applyTemporarily applies the config of t like useConfig and returns it together with a function reverting it. The
+// config and provenance of t are built by update from the current ones, which happens under h.updateMu, so concurrent
+// calls don't start from the same config and lose each other's changes. Once all temporary configs are reverted, the
+// state before is restored, which is the config file if it was watched or, otherwise, the previous config.
+func (h *Handler) applyTemporarily(t *temporaryConfig, update func(Config, provenance) (Config, provenance)) (Config, provenance, func()) {
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	h.mu.Lock()
+	t.base, t.baseProv, t.fileWatcher = *h.opts.Config, h.prov, h.opts.EnableFileWatcher
+	t.cfg, t.prov = update(t.base, t.baseProv)
+	h.temporaries = append(h.temporaries, t)
 	h.opts.EnableFileWatcher = false
+	cfg, prov := t.cfg, t.prov
 	h.opts.Config = &cfg
+	h.prov = prov
 	h.mu.Unlock()
 
 	h.initHandler()
+	h.logger.Debug(fmt.Sprintf("using config: %#v", cfg))
 
-	go func() {
-		<-time.After(revert)
-		if enableFileWatcher {
-			h.UseConfigFile()
-		} else {
-			h.UseConfig(oldCfg)
+	return cfg, prov, sync.OnceFunc(func() {
+		h.revertTemporarily(t)
+	})
+}
+
+// revertTemporarily reverts the temporary config t in LIFO order: if temporary configs applied after t are still
+// active, t is reverted together with them. Otherwise, the last active temporary config is applied again or, if
+// there is none, the state before the first one is restored. The package rules of SetPackageLevelFor are reverted
+// right away instead and don't keep the temporary configs applied before them active, they are carried over.
+func (h *Handler) revertTemporarily(t *temporaryConfig) {
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	h.mu.Lock()
+	if !slices.Contains(h.temporaries, t) {
+		h.mu.Unlock()
+		h.logger.Debug("temporary config was replaced in the meantime, e.g. by UseConfig.")
+		return
+	}
+	t.reverted = true
+	bottom := h.temporaries[0]
+	base, baseProv, fileWatcher := bottom.base, bottom.baseProv, bottom.fileWatcher
+
+	var kept []*temporaryConfig
+	held := false
+	for _, u := range slices.Backward(h.temporaries) {
+		if u.reverted && (u.pkg != "" || !held) {
+			// Temporary configs applied after a package rule of SetPackageLevelFor may contain it as well.
+			for _, v := range kept {
+				if v.pkg == "" {
+					v.cfg, v.prov = u.restorePackageLevel(v.cfg, v.prov)
+				}
+			}
+			continue
 		}
-		h.logger.Debug(fmt.Sprintf("reverted config to original: %#v", oldCfg))
-	}()
-	h.logger.Debug(fmt.Sprintf("using config: %#v", *h.opts.Config))
+		kept = append(kept, u)
+		held = held || u.pkg == ""
+	}
+	if len(kept) == len(h.temporaries) {
+		h.mu.Unlock()
+		h.logger.Debug("temporary config is reverted together with the temporary configs applied after it.")
+		return
+	}
+	slices.Reverse(kept)
+
+	// Apply the remaining package rules of SetPackageLevelFor on top of the temporary configs before them again.
+	cfg, prov := base, baseProv
+	for _, u := range kept {
+		u.base, u.baseProv = cfg, prov
+		if u.pkg != "" {
+			elevated := slices.ContainsFunc(u.cfg.Packages, func(p Package) bool { return p.Name == u.pkg && p.LogLevel == u.level })
+			source, session := u.prov.packages[u.pkg], u.prov.session
+			u.cfg, u.prov = cfg, prov
+			if elevated {
+				// Otherwise, the package rule was changed in the meantime.
+				u.cfg, u.prov = withPackageLevel(cfg, u.pkg, u.level), prov.with(u.pkg, source)
+			}
+			u.prov.session = session
+		}
+		cfg, prov = u.cfg, u.prov
+	}
+	h.temporaries = kept
+	if len(kept) > 0 {
+		kept[0].fileWatcher = fileWatcher
+		h.opts.Config = &cfg
+		h.prov = prov
+		h.mu.Unlock()
+
+		h.initHandler()
+		h.logger.Debug(fmt.Sprintf("reverted config to temporary config: %#v", cfg))
+		return
+	}
+	h.mu.Unlock()
+
+	if fileWatcher {
+		h.UseConfigFile()
+	} else {
+		h.useConfig(base, baseProv)
+	}
+	h.logger.Debug(fmt.Sprintf("reverted config to original: %#v", base))
+}
+
+// restorePackageLevel returns the config with the package rule of the temporary config t of SetPackageLevelFor
+// replaced by the rule of the config before t or, if there was none, removed. A rule with a log level other than the
+// one set by t was changed in the meantime and is kept.
+func (t *temporaryConfig) restorePackageLevel(cfg Config, prov provenance) (Config, provenance) {
+	j := slices.IndexFunc(cfg.Packages, func(p Package) bool { return p.Name == t.pkg })
+	if j < 0 || cfg.Packages[j].LogLevel != t.level {
+		return cfg, prov
+	}
+	cfg.Packages = slices.Clone(cfg.Packages)
+	if i := slices.IndexFunc(t.base.Packages, func(p Package) bool { return p.Name == t.pkg }); i >= 0 {
+		cfg.Packages[j].LogLevel = t.base.Packages[i].LogLevel
+		source, ok := t.baseProv.packages[t.pkg]
+		if !ok {
+			source = t.baseProv.source
+		}
+		return cfg, prov.with(t.pkg, source)
+	}
+	cfg.Packages = slices.Delete(cfg.Packages, j, j+1)
+	return cfg, prov.without(t.pkg)
 }
 
 // UseConfigFile takes a filename as an argument that will be used for watching a config file for changes.
-// If no such filename is given, the Handler uses the already existing ConfigFile from the HandlerOptions or,
-// if not present, falls back to the default config file (specified via defaultConfigFile).
+// If no such filename is given, the Handler uses the already existing ConfigSource, ConfigProvider or ConfigFile from the
+// HandlerOptions or, if not present, falls back to the default config file (specified via defaultConfigFile).
 func (h *Handler) UseConfigFile(cfgFile ...string) {
 	h.mu.Lock()
 	if len(cfgFile) == 1 && cfgFile[0] != "" {
 		h.opts.ConfigFile = cfgFile[0]
+		h.opts.ConfigFiles = nil
+		h.opts.ConfigProvider = nil
+		h.opts.ConfigSource = nil
 	}
 
+	h.temporaries = nil
 	h.opts.EnableFileWatcher = true
 	h.mu.Unlock()
 
 	h.loadConfig().initHandler()
-	h.logger.Debug(fmt.Sprintf("using config file (%s): %#v", h.opts.ConfigFile, *h.opts.Config))
+	h.logger.Debug(fmt.Sprintf("using config file: %#v", h.GetConfig()))
 }
 
 // GetLogLevel converts string log levels to slog.Level representation.
@@ -150,35 +779,37 @@ func (h *Handler) UseConfigFile(cfgFile ...string) {
 // defined by the log/slog package.
 // Example: DEBUG-2 or ERROR+4
 func (h *Handler) GetLogLevel(level string) slog.Level {
+	slogLevel, _ := parseLogLevel(level)
+	return slogLevel
+}
+
+// parseLogLevel parses a log level like "DEBUG" or "ERROR+2" case-insensitively and reports whether it is valid.
+// An unknown level name results in the default log level, an invalid offset is ignored.
+func parseLogLevel(level string) (slog.Level, bool) {
 	levelMap := map[string]slog.Level{
 		LogLevelDebug: slog.LevelDebug,
 		LogLevelInfo:  slog.LevelInfo,
 		LogLevelWarn:  slog.LevelWarn,
 		LogLevelError: slog.LevelError,
 	}
-	level = strings.ToUpper(level)
-	matches := regexp.MustCompile(`([a-zA-Z]+)(([+\-])(\d+))?`).FindStringSubmatch(level)
-
-	slogLevel := levelMap[defaultLogLevel]
-	if len(matches) != 5 {
-		//ss.logger.Debug(fmt.Sprintf("invalid log level: %q! -> fallback to log level %q", level, defaultLogLevel))
-		return slogLevel
+	level = strings.ToUpper(strings.TrimSpace(level))
+	name, offset := level, ""
+	if i := strings.IndexAny(level, "+-"); i >= 0 {
+		name, offset = level[:i], level[i:]
 	}
 
-	slogLevel, ok := levelMap[matches[1]]
+	slogLevel, ok := levelMap[name]
 	if !ok {
-		//ss.logger.Debug(fmt.Sprintf("invalid log level: %q! -> fallback to log level %q", level, defaultLogLevel))
-		return slogLevel
+		return levelMap[defaultLogLevel], false
 	}
-
-	if matches[4] != "" {
-		nb, _ := strconv.Atoi(matches[4])
-		if matches[3] == "-" {
-			return slog.Level(int(slogLevel) - nb)
-		}
-		return slog.Level(int(slogLevel) + nb)
+	if offset == "" {
+		return slogLevel, true
 	}
-	return slogLevel
+	nb, err := strconv.Atoi(offset)
+	if err != nil || offset[1] < '0' || offset[1] > '9' {
+		return slogLevel, false
+	}
+	return slogLevel + slog.Level(nb), true
 }
 
 type nilHandler struct{}
@@ -1,8 +1,16 @@
 package slogscope_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"testing"
 	"testing/slogtest"
 	"time"
@@ -18,7 +26,8 @@ func TestNewHandler(t *testing.T) {
 	})
 
 	t.Run("wrapped slog.Handler must not be of type *slogscope.Handler", func(t *testing.T) {
-		testFunc := func() { slogscope.NewHandler(slogscope.NewHandler(slogscope.NewNilHandler(), nil), nil) }
+		inner := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: filepath.Join(t.TempDir(), "slogscope.yml")})
+		testFunc := func() { slogscope.NewHandler(inner, nil) }
 		assert.Panics(t, testFunc, "wrapped handler of type *slogscope.Handler should have raised a panic")
 	})
 
@@ -56,6 +65,8 @@ func TestNewHandler(t *testing.T) {
 	})
 
 	t.Run("test slogscope.Handler with a wrapped slog.JSONHandler with Config from config file (slogscope.test_config.yml)", func(t *testing.T) {
+		requireFullBuild(t)
+
 		buf.Reset()
 		h := slogscope.NewHandler(slog.NewJSONHandler(&buf, nil), &slogscope.HandlerOptions{
 			EnableFileWatcher: false,
@@ -71,6 +82,8 @@ func TestNewHandler(t *testing.T) {
 	})
 
 	t.Run("test default config file is missing", func(t *testing.T) {
+		requireFullBuild(t)
+
 		h := slogscope.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelDebug,
 		}), &slogscope.HandlerOptions{
@@ -79,16 +92,35 @@ func TestNewHandler(t *testing.T) {
 		})
 		cfg := h.GetConfig()
 		assert.Equal(t, "INFO", cfg.LogLevel)
-		assert.NotNil(t, cfg.Packages)
+		// The packages are retrieved from the build info of the test binary: the main module and its dependencies.
+		bi, ok := debug.ReadBuildInfo()
+		assert.True(t, ok)
+		expected := []slogscope.Package{{Name: "github.com/apperia-de/slogscope", LogLevel: "ERROR"}}
+		for _, dep := range bi.Deps {
+			expected = append(expected, slogscope.Package{Name: dep.Path, LogLevel: "ERROR"})
+		}
+		assert.Equal(t, expected, cfg.Packages)
+		assert.Contains(t, cfg.Packages, slogscope.Package{Name: "github.com/stretchr/testify", LogLevel: "ERROR"})
 		_ = os.Remove(missingConfigFile)
 	})
 
+	t.Run("test config file creation is disabled", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "slogscope.yml")
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigFile:                filename,
+			DisableConfigFileCreation: true,
+		})
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, h.GetConfig())
+		assert.NoFileExists(t, filename)
+	})
+
 	t.Run("test with debug mode enabled", func(t *testing.T) {
 		buf.Reset()
 		_ = slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
 			Level: slog.LevelDebug,
 		}), &slogscope.HandlerOptions{
 			EnableFileWatcher: false,
+			ConfigFile:        filepath.Join(t.TempDir(), "slogscope.yml"),
 			Debug:             true,
 		})
 		line, err := buf.ReadString('\n')
@@ -99,6 +131,8 @@ func TestNewHandler(t *testing.T) {
 	})
 
 	t.Run("test config file gets changed, renamed or removed", func(t *testing.T) {
+		requireFullBuild(t)
+
 		data, err := os.ReadFile(testConfigFile)
 		assert.NoError(t, err)
 		err = os.WriteFile(testConfigFile+"_tmp", data, 0644)
@@ -152,6 +186,34 @@ func TestHandler_UseConfig(t *testing.T) {
 	assert.Equal(t, slogscope.LogLevelError, cfg.LogLevel)
 }
 
+func TestHandler_SetGlobalLevel(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}},
+		},
+	})
+	l := slog.New(h)
+	l.Debug("first debug message")
+	assert.Equal(t, slog.LevelInfo, h.GetGlobalLevel())
+
+	h.SetGlobalLevel(slog.LevelDebug - 2)
+	l.Debug("second debug message")
+	assert.Equal(t, slog.LevelDebug-2, h.GetGlobalLevel())
+	assert.NotContains(t, buf.String(), "first debug message")
+	assert.Contains(t, buf.String(), "second debug message")
+
+	cfg := h.GetConfig()
+	assert.Equal(t, "DEBUG-2", cfg.LogLevel)
+	assert.Equal(t, []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}}, cfg.Packages)
+
+	d := h.ExplainDecision("github.com/foo/qux", slog.LevelDebug)
+	assert.True(t, d.Enabled)
+	assert.Equal(t, "SetGlobalLevel", d.Source)
+	assert.Equal(t, "HandlerOptions.Config", h.ExplainDecision("github.com/foo/bar", slog.LevelDebug).Source)
+}
+
 func TestHandler_UseConfigTemporarily(t *testing.T) {
 	var (
 		h *slogscope.Handler
@@ -192,6 +254,8 @@ func TestHandler_UseConfigTemporarily(t *testing.T) {
 	})
 
 	t.Run("test with previous settings reset to slogscope.HandlerOptions.ConfigFile", func(t *testing.T) {
+		requireFullBuild(t)
+
 		buf.Reset()
 		h = setupHandlerWithConfigFile("test/data/slogscope.test_config.yml")
 
@@ -223,9 +287,226 @@ func TestHandler_UseConfigTemporarily(t *testing.T) {
 		assert.Equal(t, 2, countLogMessageByLogLevel(buf, slogscope.LogLevelInfo))
 		assert.Equal(t, 3, countLogMessageByLogLevel(buf, slogscope.LogLevelError))
 	})
+
+	t.Run("test cancel reverts early", func(t *testing.T) {
+		h = setupHandlerWithConfig(oldCfg)
+		cancel := h.UseConfigTemporarily(newCfg, time.Hour)
+		assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+		cancel()
+		assert.Equal(t, slogscope.LogLevelDebug, h.GetConfig().LogLevel)
+		cancel()
+		assert.Equal(t, slogscope.LogLevelDebug, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test nested temporary configs are reverted in LIFO order", func(t *testing.T) {
+		h = setupHandlerWithConfig(slogscope.Config{LogLevel: slogscope.LogLevelInfo})
+		cancelOuter := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelWarn}, time.Hour)
+		cancelInner := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelError}, time.Hour)
+
+		// The outer config is reverted together with the inner one.
+		cancelOuter()
+		assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+		cancelInner()
+		assert.Equal(t, slogscope.LogLevelInfo, h.GetConfig().LogLevel)
+
+		cancelOuter = h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelWarn}, time.Hour)
+		cancelInner = h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelError}, time.Hour)
+		cancelInner()
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+		cancelOuter()
+		assert.Equal(t, slogscope.LogLevelInfo, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test permanent changes in the meantime are kept", func(t *testing.T) {
+		h = setupHandlerWithConfig(slogscope.Config{LogLevel: slogscope.LogLevelInfo})
+		cancel := h.UseConfigTemporarily(slogscope.Config{
+			LogLevel: slogscope.LogLevelError,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug}},
+		}, time.Hour)
+		h.SetPackageLevel("github.com/foo/payments", slogscope.LogLevelWarn)
+		h.SetGlobalLevel(slog.LevelDebug)
+		assert.Equal(t, slogscope.Config{
+			LogLevel: slogscope.LogLevelDebug,
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/foo/payments", LogLevel: slogscope.LogLevelWarn},
+			},
+		}, h.GetConfig())
+
+		cancel()
+		assert.Equal(t, slogscope.Config{
+			LogLevel: slogscope.LogLevelDebug,
+			Packages: []slogscope.Package{{Name: "github.com/foo/payments", LogLevel: slogscope.LogLevelWarn}},
+		}, h.GetConfig())
+		assert.Equal(t, "SetPackageLevel", h.ExplainDecision("github.com/foo/payments", slog.LevelWarn).Source)
+	})
+
+	t.Run("test UseConfig replaces temporary configs", func(t *testing.T) {
+		h = setupHandlerWithConfig(slogscope.Config{LogLevel: slogscope.LogLevelInfo})
+		cancel := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelError}, time.Hour)
+		h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelWarn})
+
+		cancel()
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+	})
+}
+
+func TestHandler_SetPackageLevelFor(t *testing.T) {
+	newHandler := func() *slogscope.Handler {
+		return setupHandlerWithConfig(slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}},
+		})
+	}
+	levelOf := func(h *slogscope.Handler, name string) string {
+		for _, p := range h.GetPackages() {
+			if p.Name == name {
+				return p.LogLevel
+			}
+		}
+		return ""
+	}
+
+	t.Run("test only the package rule is reverted", func(t *testing.T) {
+		h := newHandler()
+		h.SetPackageLevelFor("github.com/foo/bar", slogscope.LogLevelDebug, 50*time.Millisecond)
+		h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, time.Hour)
+		assert.Equal(t, slogscope.LogLevelDebug, levelOf(h, "github.com/foo/bar"))
+		assert.True(t, strings.HasPrefix(h.ExplainDecision("github.com/foo/bar", slog.LevelDebug).Source, "SetPackageLevelFor until "))
+		h.SetGlobalLevel(slog.LevelWarn)
+
+		assert.Eventually(t, func() bool {
+			return levelOf(h, "github.com/foo/bar") == slogscope.LogLevelError
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelDebug, levelOf(h, "github.com/foo/payments"))
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+		assert.Equal(t, "HandlerOptions.Config", h.ExplainDecision("github.com/foo/bar", slog.LevelDebug).Source)
+	})
+
+	t.Run("test added package rule is removed", func(t *testing.T) {
+		h := newHandler()
+		h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, 50*time.Millisecond)
+		assert.True(t, h.ExplainDecision("github.com/foo/payments", slog.LevelDebug).Enabled)
+
+		assert.Eventually(t, func() bool {
+			return !h.ExplainDecision("github.com/foo/payments", slog.LevelDebug).Enabled
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}}, h.GetConfig().Packages)
+	})
+
+	t.Run("test package rule changed in the meantime is kept", func(t *testing.T) {
+		h := newHandler()
+		h.SetPackageLevelFor("github.com/foo/bar", slogscope.LogLevelDebug, 50*time.Millisecond)
+		h.SetPackageLevel("github.com/foo/bar", slogscope.LogLevelWarn)
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelWarn, levelOf(h, "github.com/foo/bar"))
+	})
+
+	t.Run("test temporary config before the package rule is reverted right away", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "slogscope.state.json")
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config:       &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+			SessionStore: slogscope.NewFileSessionStore(filename),
+		})
+		cancelTemporary := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelWarn}, time.Hour)
+		cancel := h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, time.Hour)
+
+		cancelTemporary()
+		assert.Equal(t, slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/foo/payments", LogLevel: slogscope.LogLevelDebug}},
+		}, h.GetConfig())
+		sessions, err := slogscope.NewFileSessionStore(filename).Load()
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, "github.com/foo/payments", sessions[0].Package)
+
+		cancel()
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, h.GetConfig())
+	})
+
+	t.Run("test temporary config after the package rule keeps applying", func(t *testing.T) {
+		h := newHandler()
+		cancel := h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, time.Hour)
+		cfg := h.GetConfig()
+		cfg.LogLevel = slogscope.LogLevelWarn
+		cancelTemporary := h.UseConfigTemporarily(cfg, time.Hour)
+
+		cancel()
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+		assert.Equal(t, "", levelOf(h, "github.com/foo/payments"))
+		assert.Equal(t, slogscope.LogLevelError, levelOf(h, "github.com/foo/bar"))
+
+		cancelTemporary()
+		assert.Equal(t, slogscope.LogLevelInfo, h.GetConfig().LogLevel)
+		assert.Equal(t, "", levelOf(h, "github.com/foo/payments"))
+	})
+
+	t.Run("test concurrent calls keep each other's package rules", func(t *testing.T) {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+		h := newHandler()
+		var mu sync.Mutex
+		var cancels []func()
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				name := fmt.Sprintf("github.com/foo/pkg%d", i)
+				var cancel func()
+				switch i % 3 {
+				case 0:
+					cancel = h.SetPackageLevelFor(name, slogscope.LogLevelDebug, time.Hour)
+				case 1:
+					cancel = h.UsePackageLevelTemporarily(name, slogscope.LogLevelDebug, time.Hour)
+				default:
+					cancel = h.EnterCommand(name, slogscope.Package{Name: name, LogLevel: slogscope.LogLevelDebug})
+				}
+				mu.Lock()
+				cancels = append(cancels, cancel)
+				mu.Unlock()
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		var missing []string
+		for i := range 100 {
+			if name := fmt.Sprintf("github.com/foo/pkg%d", i); levelOf(h, name) != slogscope.LogLevelDebug {
+				missing = append(missing, name)
+			}
+		}
+		assert.Empty(t, missing)
+		for _, cancel := range cancels {
+			cancel()
+		}
+		assert.Equal(t, []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}}, h.GetConfig().Packages)
+	})
+
+	t.Run("test watched config file is reloaded once no temporary config is active", func(t *testing.T) {
+		requireFullBuild(t)
+
+		filename := filepath.Join(t.TempDir(), "slogscope.yml")
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: INFO\n"), 0600))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: filename, EnableFileWatcher: true})
+		cancel := h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, time.Hour)
+		cancelTemporary := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelWarn}, time.Hour)
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: ERROR\n"), 0600))
+
+		cancel()
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelWarn}, h.GetConfig())
+		cancelTemporary()
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelError}, h.GetConfig())
+	})
 }
 
 func TestHandler_UseConfigFile(t *testing.T) {
+	requireFullBuild(t)
+
 	var (
 		h *slogscope.Handler
 		l *slog.Logger
@@ -302,3 +583,202 @@ func TestHandler_GetLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ExportConfig(t *testing.T) {
+	h := setupHandlerWithConfig(slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{
+			{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug},
+			{Name: "github.com/apperia-de/slogscope", LogLevel: slogscope.LogLevelError, Delivery: slogscope.DeliveryDurable},
+		},
+	})
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{slogscope.FormatYAML, `version: 2
+log_level: INFO
+packages:
+    - name: github.com/apperia-de/slogscope_test
+      log_level: DEBUG
+    - name: github.com/apperia-de/slogscope
+      log_level: ERROR
+      delivery: durable
+`},
+		{slogscope.FormatJSON, `{
+  "version": 2,
+  "log_level": "INFO",
+  "packages": [
+    {
+      "name": "github.com/apperia-de/slogscope_test",
+      "log_level": "DEBUG"
+    },
+    {
+      "name": "github.com/apperia-de/slogscope",
+      "log_level": "ERROR",
+      "delivery": "durable"
+    }
+  ]
+}`},
+		{slogscope.FormatTOML, `version = 2
+log_level = "INFO"
+
+[[packages]]
+name = "github.com/apperia-de/slogscope_test"
+log_level = "DEBUG"
+
+[[packages]]
+name = "github.com/apperia-de/slogscope"
+log_level = "ERROR"
+delivery = "durable"
+`},
+		{slogscope.FormatEnv, `SLOGSCOPE_LOG_LEVEL=INFO
+SLOGSCOPE_PACKAGES=github.com/apperia-de/slogscope_test=DEBUG,github.com/apperia-de/slogscope=ERROR
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if tt.format == slogscope.FormatYAML || tt.format == slogscope.FormatTOML {
+				requireFullBuild(t)
+			}
+			data, err := h.ExportConfig(tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := h.ExportConfig("xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestHandler_EffectiveConfig(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Profile: "prod",
+		Config: &slogscope.Config{
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: "debug"},
+				{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelDebug, Expires: "2020-01-01"},
+			},
+			Rollout: &slogscope.Rollout{Percent: 100, Packages: []slogscope.Package{{Name: "github.com/foo/qux", LogLevel: "warn+2"}}},
+			Profiles: map[string]slogscope.Config{
+				"prod": {LogLevel: slogscope.LogLevelError},
+			},
+		},
+	})
+	h.UsePackageLevelTemporarily("github.com/foo/bar", slogscope.LogLevelInfo, time.Hour)
+
+	assert.Equal(t, slogscope.Config{
+		LogLevel: slogscope.LogLevelError,
+		Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelInfo},
+			{Name: "github.com/foo/qux", LogLevel: "WARN+2"},
+		},
+	}, h.EffectiveConfig())
+}
+
+func TestHandler_GetPackages(t *testing.T) {
+	h := setupHandlerWithConfig(slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError, Description: "silenced due to issue #123"},
+		},
+	})
+	h.UsePackageLevelTemporarily("github.com/foo/bar", slogscope.LogLevelWarn, time.Minute)
+
+	packages := h.GetPackages()
+	assert.Len(t, packages, 1)
+	assert.Equal(t, "github.com/foo/bar", packages[0].Name)
+	assert.Equal(t, slogscope.LogLevelWarn, packages[0].LogLevel)
+	assert.Equal(t, "silenced due to issue #123", packages[0].Description)
+	assert.True(t, strings.HasPrefix(packages[0].Source, "UsePackageLevelTemporarily until "), packages[0].Source)
+}
+
+func TestNewConfigFromReader(t *testing.T) {
+	expected := &slogscope.Config{
+		LogLevel: slogscope.LogLevelWarn,
+		Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug}},
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"yaml", "log_level: WARN\npackages:\n  - name: github.com/foo/bar\n    log_level: DEBUG\n"},
+		{"json", `{"log_level": "WARN", "packages": [{"name": "github.com/foo/bar", "log_level": "DEBUG"}]}`},
+		{"toml", "log_level = \"WARN\"\n\n[[packages]]\nname = \"github.com/foo/bar\"\nlog_level = \"DEBUG\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.name, func(t *testing.T) {
+			if tt.name != "json" {
+				requireFullBuild(t)
+			}
+			cfg, err := slogscope.NewConfigFromReader(strings.NewReader(tt.data))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+
+	t.Run("test invalid config", func(t *testing.T) {
+		_, err := slogscope.NewConfigFromReader(strings.NewReader(`{"log_level": 1}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("test handler uses config reader", func(t *testing.T) {
+		requireFullBuild(t)
+
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigReader: strings.NewReader(tests[0].data),
+		})
+		assert.Equal(t, *expected, h.GetConfig())
+	})
+
+	t.Run("test invalid config reader falls back to the default config", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "slogscope.yml")
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigReader: strings.NewReader(`{"log_level": 1}`),
+			ConfigFile:   file,
+		})
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, h.GetConfig())
+		assert.Equal(t, "default config", h.ExplainDecision("github.com/foo/bar", slog.LevelInfo).Source)
+		assert.NoFileExists(t, file)
+	})
+}
+
+// enabledHandler wraps a slog.Handler without calling its Enabled method.
+type enabledHandler struct {
+	slog.Handler
+}
+
+func (h enabledHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func TestHandler_WithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&buf, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+	})
+	l := slog.New(h).With("user", "john doe").WithGroup("req")
+
+	l.Debug("filtered")
+	l.Info("request handled", "status", 200)
+	assert.NotContains(t, buf.String(), "filtered")
+	assert.Contains(t, buf.String(), `"user":"john doe","req":{"status":200}`)
+}
+
+func TestHandler_BypassWarning(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&buf, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+	})
+	l := slog.New(enabledHandler{h})
+
+	l.Debug("filtered")
+	l.Debug("filtered")
+	l.Info("handled")
+	assert.NotContains(t, buf.String(), "filtered")
+	assert.Contains(t, buf.String(), "handled")
+	assert.Equal(t, 1, strings.Count(buf.String(), "slogscope: record below the package log level was passed to Handle without Enabled"))
+}
@@ -0,0 +1,93 @@
+package slogscope
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is the number of applied configs kept if HandlerOptions.HistorySize is not set.
+const defaultHistorySize = 10
+
+// HistoryEntry is a config applied by the Handler (see Handler.History).
+type HistoryEntry struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"` // Source of the config, e.g. "file slogscope.yml" or "admin PUT /config".
+	Config Config    `json:"config"`
+}
+
+// record adds the current HandlerOptions.Config to the history. The caller must hold ss.mu.
+func (ss *slogscope) record() {
+	size := ss.opts.HistorySize
+	if size == 0 {
+		size = defaultHistorySize
+	}
+	if size < 0 {
+		return
+	}
+	ss.history = append(ss.history, HistoryEntry{Time: time.Now(), Source: ss.prov.source, Config: *ss.opts.Config})
+	if len(ss.history) > size {
+		ss.history = slices.Delete(ss.history, 0, len(ss.history)-size)
+	}
+}
+
+// History returns the configs applied by the Handler, with the current config first.
+// The number of entries is limited by HandlerOptions.HistorySize.
+func (h *Handler) History() []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := slices.Clone(h.history)
+	slices.Reverse(history)
+	return history
+}
+
+// Rollback reapplies the n-th previous config of the History, e.g. Rollback(1) reapplies the config that was active
+// before the current one. Like UseConfig, it disables any active file watcher, so a bad config isn't applied again.
+func (h *Handler) Rollback(n int) error {
+	history := h.History()
+	if n < 1 || n >= len(history) {
+		return fmt.Errorf("cannot roll back %d config(s): history contains %d previous config(s)", n, max(len(history)-1, 0))
+	}
+	entry := history[n]
+	h.useConfig(entry.Config, provenance{source: fmt.Sprintf("Rollback to config of %s (%s)", entry.Time.Format(time.RFC3339), entry.Source)})
+	h.persist(entry.Config)
+	return nil
+}
+
+// configWatchers contains the channels registered via Handler.WatchConfig.
+type configWatchers struct {
+	mu  sync.Mutex
+	chs map[chan<- HistoryEntry]struct{}
+}
+
+// notify sends the entry to all registered channels without blocking.
+func (w *configWatchers) notify(entry HistoryEntry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.chs {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// WatchConfig sends every config applied by the Handler to ch, e.g. for notifying remote clients about config
+// changes. Like History, the entries contain the configs as applied, before profiles, rollouts and quiet hours are
+// merged (see EffectiveConfig). The Handler doesn't block on ch, so changes are dropped if ch isn't ready to receive
+// them. The returned function stops sending.
+func (h *Handler) WatchConfig(ch chan<- HistoryEntry) (stop func()) {
+	h.watchers.mu.Lock()
+	defer h.watchers.mu.Unlock()
+	if h.watchers.chs == nil {
+		h.watchers.chs = make(map[chan<- HistoryEntry]struct{})
+	}
+	h.watchers.chs[ch] = struct{}{}
+	return sync.OnceFunc(func() {
+		h.watchers.mu.Lock()
+		defer h.watchers.mu.Unlock()
+		delete(h.watchers.chs, ch)
+	})
+}
@@ -0,0 +1,87 @@
+package slogscope_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_History(t *testing.T) {
+	t.Run("test applied configs are recorded with their source", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+		h.UseConfig(newCfg)
+
+		history := h.History()
+		assert.Len(t, history, 2)
+		assert.Equal(t, newCfg, history[0].Config)
+		assert.Equal(t, "UseConfig", history[0].Source)
+		assert.Equal(t, oldCfg, history[1].Config)
+		assert.Equal(t, "HandlerOptions.Config", history[1].Source)
+		assert.False(t, history[0].Time.Before(history[1].Time))
+	})
+
+	t.Run("test history is limited", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg, HistorySize: 2})
+		for range 3 {
+			h.UseConfig(newCfg)
+		}
+		assert.Len(t, h.History(), 2)
+	})
+
+	t.Run("test rollback reapplies a previous config", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+		h.UseConfig(newCfg)
+
+		assert.NoError(t, h.Rollback(1))
+		assert.Equal(t, oldCfg, h.GetConfig())
+		assert.Len(t, h.History(), 3)
+		assert.Contains(t, h.History()[0].Source, "Rollback to config of ")
+
+		assert.Error(t, h.Rollback(0))
+		assert.Error(t, h.Rollback(3))
+	})
+
+	t.Run("test rollback endpoint", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL+"/rollback?n=1", "", nil)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		h.UseConfig(newCfg)
+		resp, err = http.Post(srv.URL+"/rollback", "", nil)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, oldCfg, h.GetConfig())
+	})
+}
+
+func TestHandler_WatchConfig(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg, HistorySize: -1})
+	ch := make(chan slogscope.HistoryEntry, 1)
+	stop := h.WatchConfig(ch)
+
+	h.UseConfig(newCfg)
+	entry := <-ch
+	assert.Equal(t, newCfg, entry.Config)
+	assert.Equal(t, "UseConfig", entry.Source)
+
+	t.Run("test changes are dropped if the channel is not ready", func(t *testing.T) {
+		h.UseConfig(oldCfg)
+		h.UseConfig(newCfg)
+		assert.Equal(t, oldCfg, (<-ch).Config)
+		assert.Empty(t, ch)
+	})
+
+	t.Run("test stop", func(t *testing.T) {
+		stop()
+		h.UseConfig(oldCfg)
+		assert.Empty(t, ch)
+	})
+}
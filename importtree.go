@@ -0,0 +1,96 @@
+package slogscope
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ImportTree returns the root package and all packages of the same module imported by it, directly or indirectly,
+// up to the given depth, where depth 0 returns the root package only. The import graph is retrieved via the
+// go list command, so the sources of the module must be available, e.g. during development or in CI.
+// It fails with the build tag slogscope_minimal.
+func ImportTree(root string, depth int) ([]string, error) {
+	out, err := listImports(root)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := map[string]string{}
+	imports := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		modules[fields[0]] = fields[1]
+		imports[fields[0]] = strings.Fields(fields[2])
+	}
+	module, ok := modules[root]
+	if !ok || module == "" {
+		return nil, fmt.Errorf("package %s is not part of a module", root)
+	}
+
+	// Breadth-first search, so every package is reached with its shortest distance from the root package.
+	seen := map[string]bool{root: true}
+	level := []string{root}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var next []string
+		for _, pkgName := range level {
+			for _, imp := range imports[pkgName] {
+				if !seen[imp] && modules[imp] == module {
+					seen[imp] = true
+					next = append(next, imp)
+				}
+			}
+		}
+		level = next
+	}
+
+	packages := make([]string, 0, len(seen))
+	for pkgName := range seen {
+		packages = append(packages, pkgName)
+	}
+	slices.Sort(packages)
+	return packages, nil
+}
+
+// UseImportTreeLevel sets the log level of the root package and all packages of the same module imported by it up
+// to the given depth (see ImportTree), e.g. for debugging a feature and everything it calls. If revert is greater
+// than zero, the previous configuration is restored after revert amount of time has elapsed
+// (see UseConfigTemporarily), otherwise the change is permanent (see UseConfig).
+// It returns the names of the affected packages.
+func (h *Handler) UseImportTreeLevel(root, level string, depth int, revert time.Duration) ([]string, error) {
+	packages, err := ImportTree(root, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := fmt.Sprintf("UseImportTreeLevel(%s, depth %d)", root, depth)
+	if revert > 0 {
+		origin += " until " + time.Now().Add(revert).Format(time.RFC3339)
+	}
+	update := func(cfg Config, prov provenance) (Config, provenance) {
+		for _, name := range packages {
+			cfg = withPackageLevel(cfg, name, level)
+			prov = prov.with(name, origin)
+		}
+		return cfg, prov
+	}
+
+	if revert > 0 {
+		h.updateTemporarily(revert, func(cfg Config, prov provenance) (Config, provenance) {
+			cfg, prov = update(cfg, prov)
+			prov.session = defaultOverrideSession
+			return cfg, prov
+		})
+	} else {
+		h.mu.Lock()
+		cfg, prov := update(*h.opts.Config, h.prov)
+		h.mu.Unlock()
+		h.useConfig(cfg, prov)
+		h.persist(cfg)
+	}
+	return packages, nil
+}
@@ -0,0 +1,49 @@
+package slogscope_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTree(t *testing.T) {
+	requireFullBuild(t)
+
+	const root = "github.com/apperia-de/slogscope/examples/pkg/app"
+
+	t.Run("test depth limits the import tree", func(t *testing.T) {
+		packages, err := slogscope.ImportTree(root, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{root}, packages)
+
+		packages, err = slogscope.ImportTree("github.com/apperia-de/slogscope/examples", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/apperia-de/slogscope", "github.com/apperia-de/slogscope/examples", root}, packages)
+	})
+
+	t.Run("test packages of other modules are excluded", func(t *testing.T) {
+		packages, err := slogscope.ImportTree("github.com/apperia-de/slogscope", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github.com/apperia-de/slogscope"}, packages)
+	})
+
+	t.Run("test handler uses log level for import tree", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+		packages, err := h.UseImportTreeLevel(root, slogscope.LogLevelDebug, 1, 0)
+		assert.NoError(t, err)
+		assert.Len(t, packages, 6)
+
+		for _, name := range packages {
+			d := h.ExplainDecision(name, slog.LevelDebug)
+			assert.True(t, d.Enabled, name)
+			assert.Equal(t, "UseImportTreeLevel("+root+", depth 1)", d.Source)
+		}
+	})
+
+	t.Run("test unknown package", func(t *testing.T) {
+		_, err := slogscope.ImportTree("github.com/apperia-de/slogscope/does/not/exist", 1)
+		assert.Error(t, err)
+	})
+}
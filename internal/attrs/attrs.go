@@ -0,0 +1,35 @@
+// Package attrs contains helpers for sink handlers, which send the attributes of a record as flat key value pairs.
+package attrs
+
+import "log/slog"
+
+// Flatten appends the resolved attribute to attrs. Groups are flattened using dotted keys.
+func Flatten(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = Flatten(attrs, prefix, ga)
+		}
+		return attrs
+	}
+	return append(attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+}
+
+// JSONValue returns the value in a form suitable for encoding as JSON.
+func JSONValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration, slog.KindTime:
+		return v.String()
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.Any()
+}
@@ -0,0 +1,31 @@
+package slogscope
+
+// SetMaintenanceMode activates or deactivates the maintenance mode, which applies the settings of the maintenance
+// section of the config (see Config.Maintenance) while active, e.g. a verbose config during rollouts.
+// Without a maintenance section, the maintenance mode has no effect.
+func (h *Handler) SetMaintenanceMode(enabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.maintenance == enabled {
+		return
+	}
+	h.maintenance = enabled
+	h.configure()
+}
+
+// MaintenanceMode reports whether the maintenance mode is active (see SetMaintenanceMode).
+func (h *Handler) MaintenanceMode() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.maintenance
+}
+
+// applyMaintenance returns the config with the settings of the maintenance section applied (see mergeConfig),
+// if the maintenance mode is active.
+func (ss *slogscope) applyMaintenance(cfg Config) Config {
+	if !ss.maintenance || cfg.Maintenance == nil {
+		return cfg
+	}
+	return mergeConfig(cfg, *cfg.Maintenance)
+}
@@ -0,0 +1,80 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_SetMaintenanceMode(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Maintenance: &slogscope.Config{
+			Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug}},
+		},
+	}
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{Config: &cfg})
+	l := slog.New(h)
+	logsDebug := func() bool {
+		buf.Reset()
+		l.Debug("debug message")
+		return buf.Len() > 0
+	}
+
+	t.Run("test inactive by default", func(t *testing.T) {
+		assert.False(t, h.MaintenanceMode())
+		assert.False(t, logsDebug())
+	})
+
+	t.Run("test maintenance config applied while active", func(t *testing.T) {
+		h.SetMaintenanceMode(true)
+		assert.True(t, h.MaintenanceMode())
+		assert.True(t, logsDebug())
+		assert.Equal(t, "HandlerOptions.Config (maintenance)", h.GetPackages()[0].Source)
+		assert.Nil(t, h.EffectiveConfig().Maintenance)
+	})
+
+	t.Run("test maintenance config reverted", func(t *testing.T) {
+		h.SetMaintenanceMode(false)
+		assert.False(t, logsDebug())
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, h.EffectiveConfig())
+	})
+
+	t.Run("test admin endpoint", func(t *testing.T) {
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/maintenance", bytes.NewBufferString(`{"enabled":true}`))
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, h.MaintenanceMode())
+
+		resp, err = http.Get(srv.URL + "/maintenance")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"enabled":true}`, string(body))
+		h.SetMaintenanceMode(false)
+	})
+}
+
+func TestConfig_ValidateMaintenance(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Maintenance: &slogscope.Config{
+			LogLevel:    "LOUD",
+			Maintenance: &slogscope.Config{},
+		},
+	}
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "maintenance")
+	assert.ErrorContains(t, err, "maintenance.log_level")
+}
@@ -0,0 +1,120 @@
+package slogscope
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// instanceMetadata contains the resolved metadata of the running instance.
+type instanceMetadata struct {
+	hostname     string
+	podName      string
+	containerID  string
+	buildVersion string
+}
+
+var (
+	metadataOnce sync.Once
+	metadata     instanceMetadata
+
+	buildAttrsOnce sync.Once
+	buildAttrs     []slog.Attr
+)
+
+// getInstanceMetadata returns the metadata of the running instance, which is resolved only once.
+func getInstanceMetadata() instanceMetadata {
+	metadataOnce.Do(func() {
+		metadata.hostname, _ = os.Hostname()
+		metadata.podName = os.Getenv("POD_NAME")
+		if metadata.podName == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			// Kubernetes uses the pod name as hostname by default.
+			metadata.podName = metadata.hostname
+		}
+		metadata.containerID = readContainerID("/proc/self/cgroup", "/proc/self/mountinfo")
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			metadata.buildVersion = bi.Main.Version
+		}
+	})
+	return metadata
+}
+
+// readContainerID returns the first container ID found in the given files, or an empty string.
+func readContainerID(files ...string) string {
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if id := findContainerID(scanner.Text()); id != "" {
+				_ = f.Close()
+				return id
+			}
+		}
+		_ = f.Close()
+	}
+	return ""
+}
+
+// findContainerID returns the first 64 characters of the first run of at least 64 lowercase hex digits in s,
+// or an empty string.
+func findContainerID(s string) string {
+	const idLen = 64
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; '0' <= c && c <= '9' || 'a' <= c && c <= 'f' {
+			if i+1-start == idLen {
+				return s[start : i+1]
+			}
+			continue
+		}
+		start = i + 1
+	}
+	return ""
+}
+
+// metadataAttrs returns the enabled metadata attributes. Metadata which is not available is omitted.
+func metadataAttrs(m *Metadata) []slog.Attr {
+	if m == nil {
+		return nil
+	}
+	md := getInstanceMetadata()
+	var attrs []slog.Attr
+	add := func(enabled bool, key, value string) {
+		if enabled && value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	add(m.Hostname, "hostname", md.hostname)
+	add(m.PodName, "pod_name", md.podName)
+	add(m.ContainerID, "container_id", md.containerID)
+	add(m.BuildVersion, "build_version", md.buildVersion)
+	return attrs
+}
+
+// getBuildAttrs returns the attributes of the build group, which are resolved only once from the build info.
+func getBuildAttrs() []slog.Attr {
+	buildAttrsOnce.Do(func() {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildAttrs = append(buildAttrs, slog.String("go_version", bi.GoVersion))
+		if bi.Main.Version != "" {
+			buildAttrs = append(buildAttrs, slog.String("version", bi.Main.Version))
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				buildAttrs = append(buildAttrs, slog.String("revision", s.Value))
+			case "vcs.modified":
+				buildAttrs = append(buildAttrs, slog.Bool("dirty", s.Value == "true"))
+			}
+		}
+	})
+	return buildAttrs
+}
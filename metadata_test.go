@@ -0,0 +1,116 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Metadata(t *testing.T) {
+	hostname, err := os.Hostname()
+	assert.NoError(t, err)
+
+	logRecord := func(m *slogscope.Metadata) map[string]any {
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{LogLevel: slogscope.LogLevelInfo, Metadata: m},
+		})
+		slog.New(h).Info("info message")
+
+		var rec map[string]any
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &rec))
+		return rec
+	}
+
+	t.Run("test hostname is attached", func(t *testing.T) {
+		rec := logRecord(&slogscope.Metadata{Hostname: true})
+		assert.Equal(t, map[string]any{"hostname": hostname}, rec["instance"])
+	})
+
+	t.Run("test no metadata is attached if disabled", func(t *testing.T) {
+		assert.NotContains(t, logRecord(nil), "instance")
+		assert.NotContains(t, logRecord(&slogscope.Metadata{}), "instance")
+	})
+}
+
+func TestHandler_BuildInfo(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel:  slogscope.LogLevelInfo,
+			BuildInfo: &slogscope.BuildInfo{LogLevel: slogscope.LogLevelWarn},
+		},
+	})
+	l := slog.New(h)
+	l.Info("info message")
+	l.Warn("warn message")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	var info, warn map[string]any
+	assert.NoError(t, json.Unmarshal(lines[0], &info))
+	assert.NoError(t, json.Unmarshal(lines[1], &warn))
+	assert.NotContains(t, info, "build")
+	assert.Contains(t, warn, "build")
+	assert.Equal(t, runtime.Version(), warn["build"].(map[string]any)["go_version"])
+}
+
+func TestHandler_RuntimeMetrics(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo, RuntimeMetrics: true}},
+		},
+	})
+	l := slog.New(h)
+	l.Warn("warn message")
+	l.Error("first error")
+	l.Error("second error")
+	h.Scope("github.com/foo/bar").Error("error of other package")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 4)
+
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		assert.NoError(t, json.Unmarshal(line, &records[i]))
+	}
+	assert.NotContains(t, records[0], "runtime")
+	assert.NotContains(t, records[3], "runtime")
+	snapshot := records[1]["runtime"].(map[string]any)
+	assert.Greater(t, snapshot["goroutines"], float64(0))
+	assert.Greater(t, snapshot["heap_alloc"], float64(0))
+	assert.Contains(t, snapshot, "gc_pause")
+	// The snapshot is throttled.
+	assert.Equal(t, snapshot, records[2]["runtime"])
+}
+
+func TestHandler_SchemaVersion(t *testing.T) {
+	var out bytes.Buffer
+	cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo}
+	h := slogscope.NewHandler(slog.NewJSONHandler(&out, nil), &slogscope.HandlerOptions{Config: &cfg})
+	l := slog.New(h)
+
+	t.Run("test records without schema version", func(t *testing.T) {
+		l.Info("message")
+		assert.NotContains(t, out.String(), "schema_version")
+	})
+
+	t.Run("test records are stamped with the schema version", func(t *testing.T) {
+		cfg.SchemaVersion = "2"
+		h.UseConfig(cfg)
+		out.Reset()
+		l.Info("message")
+		var rec map[string]any
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &rec))
+		assert.Equal(t, "2", rec["schema_version"])
+	})
+}
@@ -0,0 +1,62 @@
+package slogscope
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigVersion is the current schema version of config documents.
+const ConfigVersion = 2
+
+// migrations contains the migration of config documents from the version of its index to the next version.
+var migrations = map[int]func(cfg *Config){
+	1: migrateV1,
+}
+
+// migrateConfig migrates a decoded config document to ConfigVersion. Documents without a version are version 1.
+// Afterward, the version is cleared, because configs in memory are always of the current version and only encoded
+// documents carry it (see marshalConfig). It returns an error for unknown future versions, which can't be interpreted
+// correctly.
+func migrateConfig(cfg *Config) error {
+	if cfg.Version == 0 {
+		cfg.Version = 1
+	}
+	if cfg.Version < 0 || cfg.Version > ConfigVersion {
+		return fmt.Errorf("unsupported config version %d: this version of slogscope supports config versions up to %d", cfg.Version, ConfigVersion)
+	}
+	for ; cfg.Version < ConfigVersion; cfg.Version++ {
+		migrations[cfg.Version](cfg)
+	}
+	cfg.Version = 0
+	return nil
+}
+
+// migrateV1 migrates a version 1 document, which accepted log levels and delivery guarantees in any case and package
+// names with a trailing slash, to the canonical notation of version 2.
+func migrateV1(cfg *Config) {
+	migratePackages := func(packages []Package) {
+		for i, p := range packages {
+			packages[i].Name = strings.TrimSuffix(strings.TrimSpace(p.Name), "/")
+			packages[i].LogLevel = strings.ToUpper(strings.TrimSpace(p.LogLevel))
+			packages[i].Delivery = strings.ToLower(strings.TrimSpace(p.Delivery))
+		}
+	}
+	cfg.LogLevel = strings.ToUpper(strings.TrimSpace(cfg.LogLevel))
+	cfg.Delivery = strings.ToLower(strings.TrimSpace(cfg.Delivery))
+	migratePackages(cfg.Packages)
+	if cfg.Rollout != nil {
+		cfg.Rollout.LogLevel = strings.ToUpper(strings.TrimSpace(cfg.Rollout.LogLevel))
+		migratePackages(cfg.Rollout.Packages)
+	}
+	if cfg.Maintenance != nil {
+		migrateV1(cfg.Maintenance)
+	}
+	for id, service := range cfg.Services {
+		migrateV1(&service)
+		cfg.Services[id] = service
+	}
+	for name, profile := range cfg.Profiles {
+		migrateV1(&profile)
+		cfg.Profiles[name] = profile
+	}
+}
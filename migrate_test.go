@@ -0,0 +1,61 @@
+package slogscope_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigVersion(t *testing.T) {
+	t.Run("test v1 document is migrated", func(t *testing.T) {
+		requireFullBuild(t)
+
+		cfg, err := slogscope.NewConfigFromReader(strings.NewReader(`log_level: debug
+delivery: Durable
+packages:
+  - name: github.com/foo/bar/
+    log_level: error
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, &slogscope.Config{
+			LogLevel: slogscope.LogLevelDebug,
+			Delivery: slogscope.DeliveryDurable,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError}},
+		}, cfg)
+	})
+
+	t.Run("test current document is loaded", func(t *testing.T) {
+		cfg, err := slogscope.NewConfigFromReader(strings.NewReader(`{"version": 2, "log_level": "WARN"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, &slogscope.Config{LogLevel: slogscope.LogLevelWarn}, cfg)
+	})
+
+	t.Run("test future version is rejected", func(t *testing.T) {
+		requireFullBuild(t)
+
+		_, err := slogscope.NewConfigFromReader(strings.NewReader("version: 3\nlog_level: WARN\n"))
+		assert.ErrorContains(t, err, "unsupported config version 3")
+
+		filename := filepath.Join(t.TempDir(), "slogscope.toml")
+		assert.NoError(t, os.WriteFile(filename, []byte("version = 3\nlog_level = \"WARN\"\n"), 0644))
+		_, err = slogscope.NewFileProvider(filename).Load()
+		assert.ErrorContains(t, err, "unsupported config version 3")
+	})
+
+	t.Run("test persisted document is versioned", func(t *testing.T) {
+		requireFullBuild(t)
+
+		filename := filepath.Join(t.TempDir(), "slogscope.yml")
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: INFO\n"), 0644))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: filename, PersistChanges: true})
+		h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelError})
+
+		data, err := os.ReadFile(filename)
+		assert.NoError(t, err)
+		assert.Equal(t, "version: 2\nlog_level: ERROR\npackages: []\n", string(data))
+	})
+}
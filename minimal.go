@@ -0,0 +1,110 @@
+//go:build slogscope_minimal
+
+package slogscope
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// errMinimal is returned by features, which are not available with the build tag slogscope_minimal.
+// The minimal build excludes the YAML and TOML decoders, the file watcher, regular expressions, the HTTP based
+// features (admin endpoints and HTTPProvider), signal handling, child processes and the symbol table, e.g. for TinyGo
+// or other constrained targets.
+var errMinimal = errors.New("not supported by the slogscope_minimal build")
+
+// minimalBuild reports whether the package is built with the build tag slogscope_minimal.
+// The minimal build neither creates a default config file nor scans the module for packages.
+const minimalBuild = true
+
+// unmarshalYAML is not supported by the minimal build, use JSON or TOML config files instead.
+func unmarshalYAML([]byte, any) error {
+	return errMinimal
+}
+
+// marshalYAML is not supported by the minimal build, use JSON or TOML instead.
+func marshalYAML(any) ([]byte, error) {
+	return nil, errMinimal
+}
+
+// Watch is not supported by the minimal build, config files are only loaded once.
+func (p *FileProvider) Watch(chan<- Config, <-chan struct{}) error {
+	return errMinimal
+}
+
+// Watch is not supported by the minimal build, config files are only loaded once.
+func (p *MultiFileProvider) Watch(chan<- Config, <-chan struct{}) error {
+	return errMinimal
+}
+
+// Watch is not supported by the minimal build, config fragments are only loaded once.
+func (p *DirProvider) Watch(chan<- Config, <-chan struct{}) error {
+	return errMinimal
+}
+
+// unmarshalTOML is not supported by the minimal build, use JSON config files instead.
+func unmarshalTOML([]byte, any) error {
+	return errMinimal
+}
+
+// marshalTOML is not supported by the minimal build, use JSON instead.
+func marshalTOML(any) ([]byte, error) {
+	return nil, errMinimal
+}
+
+// compilePackageRegex is not supported by the minimal build, rules with MatchRegex are ignored.
+func compilePackageRegex(expr string) (stringMatcher, error) {
+	return nil, fmt.Errorf("invalid package regex %q: %w", expr, errMinimal)
+}
+
+// MessageTemplate returns the message unchanged in the minimal build, which doesn't include regular expressions.
+// Use FingerprintOptions.Template for replacing variable parts of messages.
+func MessageTemplate(msg string) string {
+	return msg
+}
+
+// HTTPProvider is not supported by the minimal build, which doesn't include net/http.
+type HTTPProvider struct {
+	url string
+}
+
+// NewHTTPProvider returns an HTTPProvider, whose Load and Watch always fail in the minimal build.
+func NewHTTPProvider(url string, _ time.Duration) *HTTPProvider {
+	return &HTTPProvider{url: url}
+}
+
+// Load is not supported by the minimal build.
+func (p *HTTPProvider) Load() (Config, error) {
+	return Config{}, fmt.Errorf("fetching config from %s: %w", p.url, errMinimal)
+}
+
+// Watch is not supported by the minimal build.
+func (p *HTTPProvider) Watch(chan<- Config, <-chan struct{}) error {
+	return errMinimal
+}
+
+// listImports is not supported by the minimal build, which doesn't include os/exec.
+func listImports(root string) (string, error) {
+	return "", fmt.Errorf("error listing imports of %s: %w", root, errMinimal)
+}
+
+// WarmUp is not supported by the minimal build, which doesn't read the symbol table of the executable.
+func (h *Handler) WarmUp() (int, error) {
+	return 0, errMinimal
+}
+
+// children is empty in the minimal build, which doesn't include os/exec, so Handler.StartCommand is not available and
+// configs are neither shared with nor inherited from other processes.
+type children struct{}
+
+func (c *children) broadcast(func() Config) {}
+
+func (c *children) close() {}
+
+func (ss *slogscope) inheritConfig() *os.File {
+	return nil
+}
+
+func (h *Handler) followConfig(*os.File) {}
@@ -0,0 +1,90 @@
+//go:build slogscope_minimal
+
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireFullBuild skips tests, which need the YAML or TOML decoder, the file watcher or regular expressions excluded
+// by the minimal build.
+func requireFullBuild(t *testing.T) {
+	t.Helper()
+	t.Skip("needs a feature, which is excluded by the build tag slogscope_minimal")
+}
+
+// newAdminServer skips tests of the admin endpoints, which are excluded by the build tag slogscope_minimal.
+func newAdminServer(t *testing.T, _ *slogscope.Handler) *httptest.Server {
+	t.Helper()
+	t.Skip("needs the admin endpoints, which are excluded by the build tag slogscope_minimal")
+	return nil
+}
+
+// Run with: go test -tags slogscope_minimal -run TestMinimal .
+func TestMinimal_JSONConfigFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slogscope.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"log_level": "ERROR"}`), 0644))
+
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, nil), &slogscope.HandlerOptions{ConfigFile: filename, EnableFileWatcher: true})
+
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+	slog.New(h).Warn("dropped")
+	assert.Empty(t, buf.String())
+}
+
+func TestMinimal_YAMLNotSupported(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slogscope.yml")
+	require.NoError(t, os.WriteFile(filename, []byte("log_level: ERROR\n"), 0644))
+
+	_, err := slogscope.NewFileProvider(filename).Load()
+	assert.Error(t, err)
+
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{LogLevel: slogscope.LogLevelDebug}})
+	_, err = h.ExportConfig(slogscope.FormatYAML)
+	assert.Error(t, err)
+	_, err = h.ExportConfig(slogscope.FormatJSON)
+	assert.NoError(t, err)
+}
+
+func TestMinimal_TOMLRegexAndHTTPNotSupported(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slogscope.toml")
+	require.NoError(t, os.WriteFile(filename, []byte("log_level = \"ERROR\"\n"), 0644))
+
+	_, err := slogscope.NewFileProvider(filename).Load()
+	assert.Error(t, err)
+
+	_, err = slogscope.NewHTTPProvider("http://localhost/slogscope.json", 0).Load()
+	assert.Error(t, err)
+
+	err = slogscope.Config{Packages: []slogscope.Package{{Name: ".*/db", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchRegex}}}.Validate()
+	assert.Error(t, err)
+}
+
+func TestMinimal_Dependencies(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	out, err := exec.Command(goBin, "list", "-deps", "-tags", "slogscope_minimal", ".").Output()
+	require.NoError(t, err)
+
+	deps := strings.Fields(string(out))
+	for _, excluded := range []string{
+		"regexp", "net/http", "github.com/BurntSushi/toml", "gopkg.in/yaml.v3", "github.com/fsnotify/fsnotify",
+		"debug/elf", "debug/macho", "debug/gosym", "debug/dwarf", "os/exec", "os/signal",
+	} {
+		assert.NotContains(t, deps, excluded)
+	}
+}
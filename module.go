@@ -0,0 +1,100 @@
+package slogscope
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// modulePaths returns the paths of the main module and all dependencies from the build info of the binary.
+var modulePaths = sync.OnceValue(func() []string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	paths := []string{bi.Main.Path}
+	for _, dep := range bi.Deps {
+		paths = append(paths, dep.Path)
+		if dep.Replace != nil && dep.Replace.Path != "" && !strings.HasPrefix(dep.Replace.Path, ".") && !strings.HasPrefix(dep.Replace.Path, "/") {
+			paths = append(paths, dep.Replace.Path)
+		}
+	}
+	return paths
+})
+
+// inModule reports whether the package belongs to the module, i.e. it is the module path or one of its subpackages,
+// which is not part of a nested module known from the build info, e.g. github.com/some/dependency/v2.
+func inModule(module, pkgName string) bool {
+	if pkgName != module && !strings.HasPrefix(pkgName, module+"/") {
+		return false
+	}
+	for _, m := range modulePaths() {
+		if len(m) > len(module) && strings.HasPrefix(m, module+"/") && (pkgName == m || strings.HasPrefix(pkgName, m+"/")) {
+			return false
+		}
+	}
+	return true
+}
+
+// warnUnmatchedRules warns once about every package rule, which can't match any package known from the build info,
+// the registered scopes or the observed packages, e.g. due to a typo in a long import path. Rules with regular
+// expressions, file paths, short names and groups, rules for the standard library and rules without build info are not
+// checked.
+func (ss *slogscope) warnUnmatchedRules(packages []Package) {
+	modules := modulePaths()
+	if len(modules) == 0 {
+		return
+	}
+	for _, p := range packages {
+		name := strings.TrimPrefix(p.Name, "!")
+		switch strings.ToLower(p.Match) {
+		case MatchRegex, MatchFile, MatchShort, MatchGroup:
+			continue
+		}
+		if ss.knownPackage(literalPrefix(name), modules) {
+			continue
+		}
+		ss.warnOnce("unmatched_rule:"+p.Name, "slogscope: package rule matches no known package", "package", p.Name, "hint", "check the import path for typos")
+	}
+}
+
+// knownPackage reports whether the package name or one of its subpackages may exist, i.e. it belongs to the standard
+// library, a module of the build info, a registered scope or an observed package.
+func (ss *slogscope) knownPackage(name string, modules []string) bool {
+	first, _, _ := strings.Cut(name, "/")
+	if name == "" || !strings.Contains(first, ".") {
+		return true
+	}
+	// External test packages, e.g. github.com/foo/bar_test, belong to the module of the package under test.
+	name = strings.TrimSuffix(name, "_test")
+	related := func(pkgName string) bool {
+		pkgName = strings.TrimSuffix(pkgName, "_test")
+		return pkgName == name || strings.HasPrefix(pkgName, name+"/") || strings.HasPrefix(name, pkgName+"/")
+	}
+	for _, m := range modules {
+		if related(m) {
+			return true
+		}
+	}
+	known := false
+	check := func(pkgName any) bool {
+		known = related(pkgName.(string))
+		return !known
+	}
+	ss.observed.Range(func(k, _ any) bool { return check(k) })
+	if !known {
+		ss.scopes.Range(func(_, v any) bool { return check(v) })
+	}
+	return known
+}
+
+// literalPrefix returns the segments of a package name or pattern preceding the first segment with wildcards.
+func literalPrefix(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		if isPattern(s) {
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return name
+}
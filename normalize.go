@@ -0,0 +1,117 @@
+package slogscope
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxNormalizePrecision is the maximum number of decimal places of normalized durations (see Normalize.Precision).
+const maxNormalizePrecision = 9
+
+// durationUnits contains the units of Normalize.DurationUnit.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// sizeUnits contains the decimal and binary units of byte sizes in lower case (see parseSize).
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// normalizer converts the duration and size attributes of records (see Config.Normalize).
+type normalizer struct {
+	unit      time.Duration
+	suffix    string
+	sizeKeys  map[string]struct{}
+	precision float64 // 10^Normalize.Precision
+}
+
+// newNormalizer returns the normalizer for the settings or nil, if nothing is to be normalized.
+func newNormalizer(n *Normalize) *normalizer {
+	if n == nil || n.DurationUnit == "" && len(n.SizeKeys) == 0 {
+		return nil
+	}
+	nz := &normalizer{precision: math.Pow10(min(max(n.Precision, 0), maxNormalizePrecision))}
+	if unit, ok := durationUnits[n.DurationUnit]; ok {
+		nz.unit, nz.suffix = unit, "_"+n.DurationUnit
+	}
+	if len(n.SizeKeys) > 0 {
+		nz.sizeKeys = make(map[string]struct{}, len(n.SizeKeys))
+		for _, k := range n.SizeKeys {
+			nz.sizeKeys[k] = struct{}{}
+		}
+	}
+	return nz
+}
+
+// normalize returns the record with its duration and size attributes normalized, including those within groups.
+func (nz *normalizer) normalize(rec slog.Record) slog.Record {
+	normalized := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		normalized.AddAttrs(nz.attr(a))
+		return true
+	})
+	return normalized
+}
+
+func (nz *normalizer) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch {
+	case v.Kind() == slog.KindGroup:
+		attrs := v.Group()
+		normalized := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			normalized[i] = nz.attr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(normalized...)}
+	case v.Kind() == slog.KindDuration && nz.unit > 0:
+		d := float64(v.Duration()) / float64(nz.unit)
+		return slog.Float64(strings.TrimSuffix(a.Key, nz.suffix)+nz.suffix, math.Round(d*nz.precision)/nz.precision)
+	}
+	if _, ok := nz.sizeKeys[a.Key]; ok {
+		if size, ok := parseSize(v); ok {
+			return slog.Int64(strings.TrimSuffix(a.Key, "_bytes")+"_bytes", size)
+		}
+	}
+	return a
+}
+
+// parseSize returns the number of bytes of a numeric value or a string like "1.5MB", "512 KiB" or "1024".
+func parseSize(v slog.Value) (int64, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return v.Int64(), true
+	case slog.KindUint64:
+		return int64(min(v.Uint64(), math.MaxInt64)), true
+	case slog.KindFloat64:
+		return int64(math.Round(v.Float64())), true
+	case slog.KindString:
+		s := strings.TrimSpace(v.String())
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i < 0 {
+			i = len(s)
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+		if err != nil || !ok {
+			return 0, false
+		}
+		return int64(math.Round(n * unit)), true
+	}
+	return 0, false
+}
@@ -0,0 +1,54 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Normalize(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Normalize: &slogscope.Normalize{
+			DurationUnit: "ms",
+			Precision:    1,
+			SizeKeys:     []string{"body_size", "payload"},
+		},
+	}})
+	l := slog.New(h)
+
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{"duration", []any{"latency", 1500 * time.Millisecond}, "latency_ms=1500"},
+		{"duration precision", []any{"latency", 1234567 * time.Nanosecond}, "latency_ms=1.2"},
+		{"duration with unit suffix", []any{"latency_ms", 2 * time.Second}, "latency_ms=2000"},
+		{"duration within group", []any{slog.Group("db", "took", time.Second)}, "db.took_ms=1000"},
+		{"decimal size", []any{"body_size", "1.5MB"}, "body_size_bytes=1500000"},
+		{"binary size", []any{"payload", "512 KiB"}, "payload_bytes=524288"},
+		{"numeric size", []any{"payload", 42}, "payload_bytes=42"},
+		{"invalid size", []any{"payload", "large"}, "payload=large"},
+		{"other keys", []any{"size", "1MB"}, "size=1MB"},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.name, func(t *testing.T) {
+			out.Reset()
+			l.Info("request", tt.args...)
+			assert.Contains(t, out.String(), tt.want)
+		})
+	}
+
+	t.Run("test invalid settings", func(t *testing.T) {
+		cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Normalize: &slogscope.Normalize{DurationUnit: "min", Precision: -1}}
+		err := cfg.Validate()
+		assert.ErrorContains(t, err, "normalize.duration_unit")
+		assert.ErrorContains(t, err, "normalize.precision")
+	})
+}
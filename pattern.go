@@ -0,0 +1,332 @@
+package slogscope
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Available matching modes of package names (see Package.Match).
+const (
+	MatchGlob  = "glob"  // The name is the exact package name or a pattern with wildcards, e.g. github.com/myorg/** (default).
+	MatchRegex = "regex" // The name is a regular expression, which must match the whole package name.
+	// MatchModule matches all packages of the Go module with the name as module path, e.g. github.com/some/dependency,
+	// but not the packages of nested modules known from the build info, e.g. github.com/some/dependency/v2.
+	MatchModule = "module"
+	// MatchShort matches all packages with the name as last path element, e.g. debuglogger matches
+	// github.com/myorg/service/debuglogger, so ops tooling does not require full import paths.
+	MatchShort = "short"
+	// MatchFile matches the name as pattern with wildcards against the file path of the caller instead of its package,
+	// e.g. **/internal/server/grpc_*.go. Rules for files take precedence over rules for packages.
+	MatchFile = "file"
+	// MatchGroup matches the name against the groups of the logger (see slog.Logger.WithGroup) instead of the package
+	// of the caller, e.g. http matches the groups "http" and "http.client", but not "api.http". Nested groups are
+	// joined by dots. Rules for groups take precedence over rules for files and packages.
+	MatchGroup = "group"
+)
+
+// Kinds of rules matching a package (see RuleCandidate).
+const (
+	ruleExact   = "exact"   // The rule of the package itself.
+	ruleParent  = "parent"  // The rule of a parent package, which is inherited.
+	rulePattern = "pattern" // A rule with wildcards (see MatchGlob).
+	ruleRegex   = "regex"   // A rule with a regular expression (see MatchRegex).
+	ruleModule  = "module"  // A rule for a Go module (see MatchModule).
+	ruleShort   = "short"   // A rule for the last path element of packages (see MatchShort).
+)
+
+// patterns contains the package rules with wildcard names (see isPattern), regular expressions (see MatchRegex),
+// modules (see MatchModule) and short names (see MatchShort) together with the rules resolved for the package names matched so far, so every package name is matched against
+// all rules only once per config.
+type patterns struct {
+	rules         []*pkg
+	excludes      []*pkg   // Packages excluded from less specific rules by names prefixed with "!".
+	resolved      sync.Map // Matching rule (*pkg) or nil by package name.
+	files         []*pkg   // Rules for file paths (see MatchFile).
+	resolvedFiles sync.Map // Matching rule (*pkg) or nil by file path.
+	groups        []*pkg   // Rules for groups (see MatchGroup).
+}
+
+// candidate is a rule matching a package name.
+type candidate struct {
+	rule       *pkg
+	kind       string
+	excludedBy *pkg // Exclusion skipping the rule, nil if the rule applies.
+}
+
+// newPatterns returns the patterns of the given package, file and group rules, ordered by descending specificity,
+// together with the exclusions.
+func newPatterns(rules, excludes, files, groups []*pkg) *patterns {
+	bySpecificity := func(a, b *pkg) int {
+		return cmp.Compare(len(b.name), len(a.name))
+	}
+	slices.SortStableFunc(rules, bySpecificity)
+	slices.SortStableFunc(files, bySpecificity)
+	slices.SortStableFunc(groups, bySpecificity)
+	return &patterns{rules: rules, excludes: excludes, files: files, groups: groups}
+}
+
+// matchGroup returns the longest rule matching the groups of a logger joined by dots (see MatchGroup).
+func (p *patterns) matchGroup(groups string) (*pkg, bool) {
+	if p == nil || groups == "" {
+		return nil, false
+	}
+	for _, r := range p.groups {
+		if groups == r.name || strings.HasPrefix(groups, r.name+".") {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// matchFile returns the longest rule matching the file path (see MatchFile).
+func (p *patterns) matchFile(file string) (*pkg, bool) {
+	if p == nil || len(p.files) == 0 {
+		return nil, false
+	}
+	if v, ok := p.resolvedFiles.Load(file); ok {
+		rule := v.(*pkg)
+		return rule, rule != nil
+	}
+	var rule *pkg
+	normalized := normalizeFilePath(file)
+	for _, r := range p.files {
+		if matchPackage(r.name, normalized) {
+			rule = r
+			break
+		}
+	}
+	p.resolvedFiles.Store(file, rule)
+	return rule, rule != nil
+}
+
+// match returns the rule with the highest precedence for the package name (see candidates).
+func (p *patterns) match(pkgName string, exact *sync.Map) (*pkg, bool) {
+	if p == nil {
+		return nil, false
+	}
+	if v, ok := p.resolved.Load(pkgName); ok {
+		rule := v.(*pkg)
+		return rule, rule != nil
+	}
+	var rule *pkg
+	for _, c := range p.candidates(pkgName, exact) {
+		if c.excludedBy == nil {
+			rule = c.rule
+			break
+		}
+	}
+	p.resolved.Store(pkgName, rule)
+	return rule, rule != nil
+}
+
+// candidates returns all rules matching the package name in descending order of precedence, which is the
+// Package.Priority, then the rule of the package itself and then the longest name, i.e. the nearest parent package
+// or the most specific pattern. Rules of parent packages and patterns, which are not longer than the most specific
+// matching exclusion, are marked as excluded.
+func (p *patterns) candidates(pkgName string, exact *sync.Map) []candidate {
+	var exclusion *pkg
+	for _, e := range p.excludes {
+		if (e.matches(pkgName) || !e.isPattern() && strings.HasPrefix(pkgName, e.name+"/")) &&
+			(exclusion == nil || len(e.name) > len(exclusion.name)) {
+			exclusion = e
+		}
+	}
+
+	var cs []candidate
+	if v, ok := exact.Load(pkgName); ok && !v.(*pkg).isPattern() {
+		cs = append(cs, candidate{rule: v.(*pkg), kind: ruleExact})
+	}
+	for parent := path.Dir(pkgName); parent != "." && parent != "/"; parent = path.Dir(parent) {
+		if v, ok := exact.Load(parent); ok && !v.(*pkg).isPattern() {
+			cs = append(cs, candidate{rule: v.(*pkg), kind: ruleParent})
+		}
+	}
+	for _, r := range p.rules {
+		if !r.matches(pkgName) {
+			continue
+		}
+		kind := rulePattern
+		switch {
+		case r.re != nil:
+			kind = ruleRegex
+		case r.module:
+			kind = ruleModule
+		case r.short:
+			kind = ruleShort
+		}
+		cs = append(cs, candidate{rule: r, kind: kind})
+	}
+
+	for i, c := range cs {
+		if c.kind != ruleExact && exclusion != nil && len(c.rule.name) <= len(exclusion.name) {
+			cs[i].excludedBy = exclusion
+		}
+	}
+	slices.SortStableFunc(cs, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.rule.priority, a.rule.priority),
+			cmp.Compare(boolToInt(b.kind == ruleExact), boolToInt(a.kind == ruleExact)),
+			cmp.Compare(len(b.rule.name), len(a.rule.name)),
+		)
+	})
+	return cs
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// isPattern reports whether the rule is a pattern, a regular expression, a module or a short name instead of a
+// package name.
+func (p *pkg) isPattern() bool {
+	return p.re != nil || p.module || p.short || isPattern(p.name)
+}
+
+// matches reports whether the package name matches the rule, which is a pattern, a regular expression, a module or a
+// short name.
+func (p *pkg) matches(pkgName string) bool {
+	if p.short {
+		return path.Base(pkgName) == p.name
+	}
+	if p.re != nil {
+		return p.re.MatchString(pkgName)
+	}
+	if p.module {
+		return inModule(p.name, pkgName)
+	}
+	return matchPackage(p.name, pkgName)
+}
+
+// stringMatcher is implemented by *regexp.Regexp. Regular expressions are only compiled by the full build
+// (see compilePackageRegex), so the minimal build doesn't depend on the regexp package.
+type stringMatcher interface {
+	MatchString(s string) bool
+}
+
+// isPattern reports whether the package name of a rule contains wildcards.
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// matchPackage reports whether the package name matches the pattern. The pattern is matched segment by segment
+// with path.Match, e.g. "github.com/myorg/*" matches the direct subpackages of github.com/myorg. The segment "**"
+// matches any number of segments, e.g. "github.com/myorg/**" matches github.com/myorg and all its subpackages.
+func matchPackage(pattern, pkgName string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(pkgName, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// rule returns the package rule for the package name. Without explicit priorities, the rule of the package itself
+// takes precedence. Otherwise, the package inherits the rule of its nearest parent package, e.g.
+// github.com/myorg/service/db the rule of github.com/myorg/service, unless a longer pattern matches it
+// (see patterns.candidates).
+func (ss *slogscope) rule(pkgName string) (*pkg, bool) {
+	return ss.patterns.Load().match(ss.ruleName(pkgName), &ss.pkgMap)
+}
+
+// ruleName returns the package name matched against the package rules, which is the package under test for an
+// external test package, if Config.TestPackages is enabled and there is no rule for the test package itself.
+func (ss *slogscope) ruleName(pkgName string) string {
+	if !ss.settings.Load().testPackages {
+		return pkgName
+	}
+	name, ok := strings.CutSuffix(pkgName, "_test")
+	if !ok {
+		return pkgName
+	}
+	if v, ok := ss.pkgMap.Load(pkgName); ok && !v.(*pkg).isPattern() {
+		return pkgName
+	}
+	return name
+}
+
+// callerRule returns the rule for a log call from the given file of the given package via a logger with the given
+// groups. Rules for the groups (see MatchGroup) take precedence over rules for the file path (see MatchFile), which
+// take precedence over the rule of the package.
+func (ss *slogscope) callerRule(pkgName, file, groups string) (*pkg, bool) {
+	p := ss.patterns.Load()
+	if rule, ok := p.matchGroup(groups); ok {
+		return rule, true
+	}
+	if rule, ok := p.matchFile(file); ok {
+		return rule, true
+	}
+	return p.match(ss.ruleName(pkgName), &ss.pkgMap)
+}
+
+// RuleMatch explains which package rule applies to a package (see Handler.ExplainMatch).
+type RuleMatch struct {
+	Package  string `json:"package"`
+	Rule     string `json:"rule,omitempty"` // Name of the winning rule, empty if the global log level applies.
+	LogLevel string `json:"log_level"`      // Log level of the winning rule or the global log level.
+	// Candidates contains all rules matching the package in descending order of precedence.
+	Candidates []RuleCandidate `json:"candidates,omitempty"`
+}
+
+// RuleCandidate is a package rule matching a package.
+type RuleCandidate struct {
+	Rule     string `json:"rule"`
+	Kind     string `json:"kind"` // One of "exact", "parent", "pattern", "regex", "module" or "short".
+	Priority int    `json:"priority,omitempty"`
+	LogLevel string `json:"log_level"`
+	Source   string `json:"source,omitempty"`
+	// Excluded is the exclusion, e.g. "!github.com/myorg/service/noisy", which skips the rule for the package.
+	Excluded string `json:"excluded,omitempty"`
+}
+
+// ExplainMatch returns all package rules matching the package in descending order of precedence together with the
+// winning rule, e.g. for debugging overlapping patterns and priorities. Vendored package paths are normalized to
+// their import paths.
+func (h *Handler) ExplainMatch(pkgName string) RuleMatch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pkgName = normalizePackagePath(pkgName)
+	m := RuleMatch{Package: pkgName, LogLevel: h.settings.Load().logLvl.String()}
+	p := h.patterns.Load()
+	if p == nil {
+		return m
+	}
+	for _, c := range p.candidates(h.ruleName(pkgName), &h.pkgMap) {
+		rc := RuleCandidate{
+			Rule:     c.rule.name,
+			Kind:     c.kind,
+			Priority: c.rule.priority,
+			LogLevel: c.rule.logLevel.String(),
+			Source:   c.rule.source,
+		}
+		if c.excludedBy != nil {
+			rc.Excluded = "!" + c.excludedBy.name
+		} else if m.Rule == "" {
+			m.Rule, m.LogLevel = rc.Rule, rc.LogLevel
+		}
+		m.Candidates = append(m.Candidates, rc)
+	}
+	return m
+}
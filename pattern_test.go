@@ -0,0 +1,411 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_PackagePatterns(t *testing.T) {
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/myorg/**", LogLevel: slogscope.LogLevelWarn},
+				{Name: "github.com/myorg/service/*", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/myorg/service/api", LogLevel: slogscope.LogLevelError},
+				{Name: "github.com/myorg/tools/gen-*", LogLevel: slogscope.LogLevelError},
+			},
+		},
+	})
+
+	tests := []struct {
+		pkg  string
+		rule string
+	}{
+		{pkg: "github.com/myorg", rule: "github.com/myorg/**"},
+		{pkg: "github.com/myorg/lib/util", rule: "github.com/myorg/**"},
+		{pkg: "github.com/myorg/service", rule: "github.com/myorg/**"},
+		{pkg: "github.com/myorg/service/db", rule: "github.com/myorg/service/*"},
+		{pkg: "github.com/myorg/service/db/migrations", rule: "github.com/myorg/**"},
+		{pkg: "github.com/myorg/service/api", rule: "github.com/myorg/service/api"},
+		{pkg: "github.com/myorg/tools/gen-mocks", rule: "github.com/myorg/tools/gen-*"},
+		{pkg: "github.com/otherorg/service/db", rule: ""},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.pkg, func(t *testing.T) {
+			// Repeated to cover the resolved rules.
+			for range 2 {
+				assert.Equal(t, tt.rule, h.ExplainDecision(tt.pkg, slog.LevelDebug).Rule)
+			}
+		})
+	}
+}
+
+func TestHandler_PackageRegex(t *testing.T) {
+	requireFullBuild(t)
+
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: `.*internal/(db|cache)`, LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchRegex},
+			},
+		},
+	})
+
+	assert.True(t, h.ExplainDecision("github.com/myorg/internal/db", slog.LevelDebug).Enabled)
+	assert.True(t, h.ExplainDecision("github.com/myorg/service/internal/cache", slog.LevelDebug).Enabled)
+	// The expression must match the whole package name.
+	assert.False(t, h.ExplainDecision("github.com/myorg/internal/db/migrations", slog.LevelDebug).Enabled)
+	assert.False(t, h.ExplainDecision("github.com/myorg/internal/queue", slog.LevelDebug).Enabled)
+}
+
+func TestConfig_ValidatePackagePattern(t *testing.T) {
+	cfg := slogscope.Config{Packages: []slogscope.Package{{Name: "github.com/myorg/[", LogLevel: slogscope.LogLevelDebug}}}
+	assert.ErrorContains(t, cfg.Validate(), `packages[0].name: invalid package pattern "github.com/myorg/["`)
+
+	cfg.Packages[0].Match = slogscope.MatchRegex
+	assert.ErrorContains(t, cfg.Validate(), `packages[0].name: invalid package regex "github.com/myorg/["`)
+
+	cfg.Packages[0].Match = "prefix"
+	assert.ErrorContains(t, cfg.Validate(), `packages[0].match: invalid matching mode "prefix"`)
+}
+
+func TestHandler_PackageInheritance(t *testing.T) {
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/myorg/service", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/myorg/service/db", LogLevel: slogscope.LogLevelError},
+				{Name: "github.com/myorg/service/api/*", LogLevel: slogscope.LogLevelWarn},
+				{Name: "github.com/myorg/**", LogLevel: slogscope.LogLevelWarn},
+			},
+		},
+	})
+
+	tests := []struct {
+		pkg  string
+		rule string
+	}{
+		{pkg: "github.com/myorg/service", rule: "github.com/myorg/service"},
+		{pkg: "github.com/myorg/service/cache", rule: "github.com/myorg/service"},
+		{pkg: "github.com/myorg/service/cache/redis", rule: "github.com/myorg/service"},
+		{pkg: "github.com/myorg/service/db/migrations", rule: "github.com/myorg/service/db"},
+		{pkg: "github.com/myorg/service/api/v1", rule: "github.com/myorg/service/api/*"},
+		{pkg: "github.com/myorg/tools", rule: "github.com/myorg/**"},
+		{pkg: "github.com/myorg/servicemesh", rule: "github.com/myorg/**"},
+		{pkg: "github.com/otherorg/service", rule: ""},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.pkg, func(t *testing.T) {
+			assert.Equal(t, tt.rule, h.ExplainDecision(tt.pkg, slog.LevelDebug).Rule)
+		})
+	}
+}
+
+func TestHandler_PackageExclusion(t *testing.T) {
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelWarn,
+			Packages: []slogscope.Package{
+				{Name: "github.com/myorg/service/**", LogLevel: slogscope.LogLevelDebug},
+				{Name: "!github.com/myorg/service/internal/noisy"},
+				{Name: "github.com/myorg/service/internal/noisy/important", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/myorg/lib", LogLevel: slogscope.LogLevelDebug},
+				{Name: "!github.com/myorg/lib/*/gen", LogLevel: ""},
+			},
+		},
+	})
+
+	tests := []struct {
+		pkg  string
+		rule string
+	}{
+		{pkg: "github.com/myorg/service/internal/db", rule: "github.com/myorg/service/**"},
+		{pkg: "github.com/myorg/service/internal/noisy", rule: ""},
+		{pkg: "github.com/myorg/service/internal/noisy/sub", rule: ""},
+		{pkg: "github.com/myorg/service/internal/noisy/important", rule: "github.com/myorg/service/internal/noisy/important"},
+		{pkg: "github.com/myorg/lib/api", rule: "github.com/myorg/lib"},
+		{pkg: "github.com/myorg/lib/api/gen", rule: ""},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.pkg, func(t *testing.T) {
+			d := h.ExplainDecision(tt.pkg, slog.LevelInfo)
+			assert.Equal(t, tt.rule, d.Rule)
+			assert.Equal(t, tt.rule != "", d.Enabled)
+		})
+	}
+
+	cfg := slogscope.Config{Packages: []slogscope.Package{{Name: "!github.com/myorg/service/internal/noisy"}, {Name: "!"}}}
+	assert.EqualError(t, cfg.Validate(), `invalid config (1 problems): packages[1].name: must not be empty after "!"`)
+}
+
+func TestHandler_ExplainMatch(t *testing.T) {
+	requireFullBuild(t)
+
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/myorg/service", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/myorg/**", LogLevel: slogscope.LogLevelWarn},
+				{Name: `.*/db`, LogLevel: slogscope.LogLevelError, Match: slogscope.MatchRegex, Priority: 10},
+				{Name: "!github.com/myorg/service/cache"},
+			},
+		},
+	})
+
+	t.Run("test priority wins over specificity", func(t *testing.T) {
+		m := h.ExplainMatch("github.com/myorg/service/db")
+		assert.Equal(t, `.*/db`, m.Rule)
+		assert.Equal(t, slogscope.LogLevelError, m.LogLevel)
+		assert.Equal(t, []slogscope.RuleCandidate{
+			{Rule: `.*/db`, Kind: "regex", Priority: 10, LogLevel: slogscope.LogLevelError, Source: "HandlerOptions.Config"},
+			{Rule: "github.com/myorg/service", Kind: "parent", LogLevel: slogscope.LogLevelDebug, Source: "HandlerOptions.Config"},
+			{Rule: "github.com/myorg/**", Kind: "pattern", LogLevel: slogscope.LogLevelWarn, Source: "HandlerOptions.Config"},
+		}, m.Candidates)
+		assert.Equal(t, m.Rule, h.ExplainDecision("github.com/myorg/service/db", slog.LevelInfo).Rule)
+	})
+
+	t.Run("test specificity without priorities", func(t *testing.T) {
+		m := h.ExplainMatch("github.com/myorg/service/api")
+		assert.Equal(t, "github.com/myorg/service", m.Rule)
+		assert.Len(t, m.Candidates, 2)
+	})
+
+	t.Run("test excluded rules", func(t *testing.T) {
+		m := h.ExplainMatch("github.com/myorg/service/cache")
+		assert.Empty(t, m.Rule)
+		assert.Equal(t, slogscope.LogLevelInfo, m.LogLevel)
+		for _, c := range m.Candidates {
+			assert.Equal(t, "!github.com/myorg/service/cache", c.Excluded)
+		}
+	})
+
+	t.Run("test unmatched package", func(t *testing.T) {
+		assert.Equal(t, slogscope.RuleMatch{Package: "github.com/other", LogLevel: slogscope.LogLevelInfo}, h.ExplainMatch("github.com/other"))
+	})
+}
+
+func TestHandler_FileRules(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo},
+				{Name: "**/pattern_*.go", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchFile},
+			},
+		},
+	})
+	l := slog.New(h)
+
+	l.Debug("debug from pattern_test.go")
+	logFromOtherFunction(l, "debug from function_test.go")
+
+	assert.Contains(t, buf.String(), "debug from pattern_test.go")
+	assert.NotContains(t, buf.String(), "debug from function_test.go")
+	assert.Empty(t, h.Stats().Warnings)
+
+	cfg := slogscope.Config{Packages: []slogscope.Package{{Name: "!**/*_gen.go", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchFile}}}
+	assert.EqualError(t, cfg.Validate(), "invalid config (1 problems): packages[0].name: exclusions are not supported for files")
+}
+
+func TestHandler_ModuleRules(t *testing.T) {
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/stretchr", LogLevel: slogscope.LogLevelError, Match: slogscope.MatchModule},
+				{Name: "github.com/stretchr/testify", LogLevel: slogscope.LogLevelWarn, Match: slogscope.MatchModule},
+			},
+		},
+	})
+
+	t.Run("test packages of the module", func(t *testing.T) {
+		for _, pkgName := range []string{"github.com/stretchr/testify", "github.com/stretchr/testify/assert"} {
+			m := h.ExplainMatch(pkgName)
+			assert.Equal(t, "github.com/stretchr/testify", m.Rule, pkgName)
+			assert.Equal(t, slogscope.LogLevelWarn, m.LogLevel, pkgName)
+			assert.Equal(t, "module", m.Candidates[0].Kind, pkgName)
+		}
+	})
+
+	t.Run("test nested modules are not matched", func(t *testing.T) {
+		assert.Len(t, h.ExplainMatch("github.com/stretchr/testify/assert").Candidates, 1)
+		assert.Equal(t, "github.com/stretchr", h.ExplainMatch("github.com/stretchr/other").Rule)
+	})
+
+	t.Run("test other packages", func(t *testing.T) {
+		assert.Empty(t, h.ExplainMatch("github.com/stretchrx").Rule)
+	})
+
+	t.Run("test invalid module path", func(t *testing.T) {
+		cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Packages: []slogscope.Package{
+			{Name: "github.com/some/*", LogLevel: slogscope.LogLevelWarn, Match: slogscope.MatchModule},
+		}}
+		assert.ErrorContains(t, cfg.Validate(), "must not contain wildcards")
+	})
+}
+
+func TestHandler_ShortNameRules(t *testing.T) {
+	h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "debuglogger", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchShort},
+				{Name: "github.com/myorg/other/debuglogger", LogLevel: slogscope.LogLevelError},
+			},
+		},
+	})
+
+	t.Run("test packages with the short name", func(t *testing.T) {
+		m := h.ExplainMatch("github.com/myorg/service/internal/debuglogger")
+		assert.Equal(t, "debuglogger", m.Rule)
+		assert.Equal(t, "short", m.Candidates[0].Kind)
+		assert.Equal(t, "debuglogger", h.ExplainMatch("debuglogger").Rule)
+	})
+
+	t.Run("test exact rule takes precedence", func(t *testing.T) {
+		assert.Equal(t, "github.com/myorg/other/debuglogger", h.ExplainMatch("github.com/myorg/other/debuglogger").Rule)
+	})
+
+	t.Run("test other packages", func(t *testing.T) {
+		assert.Empty(t, h.ExplainMatch("github.com/myorg/debuglogger/sub").Rule)
+		assert.Empty(t, h.ExplainMatch("github.com/myorg/mydebuglogger").Rule)
+	})
+
+	t.Run("test invalid short name", func(t *testing.T) {
+		cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Packages: []slogscope.Package{
+			{Name: "service/debuglogger", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchShort},
+		}}
+		assert.ErrorContains(t, cfg.Validate(), "invalid short name")
+	})
+}
+
+func TestHandler_UnmatchedRules(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/stretchr/testify/assert", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/stretchr/**", LogLevel: slogscope.LogLevelInfo},
+				{Name: "net/http", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/stretchr/tesitfy", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/apperia/**", LogLevel: slogscope.LogLevelInfo},
+				{Name: ".*/db", LogLevel: slogscope.LogLevelInfo, Match: slogscope.MatchRegex},
+			},
+		},
+	})
+
+	assert.Equal(t, map[string]uint64{
+		"unmatched_rule:github.com/stretchr/tesitfy": 1,
+		"unmatched_rule:github.com/apperia/**":       1,
+	}, h.Stats().Warnings)
+	assert.Contains(t, out.String(), `msg="slogscope: package rule matches no known package" package=github.com/stretchr/tesitfy`)
+}
+
+func TestHandler_GroupRules(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelWarn},
+				{Name: "http", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchGroup},
+				{Name: "http.server", LogLevel: slogscope.LogLevelError, Match: slogscope.MatchGroup},
+			},
+		},
+	})
+	l := slog.New(h)
+
+	l.WithGroup("http").Debug("debug from http")
+	l.WithGroup("http").With("key", "value").WithGroup("client").Debug("debug from http.client")
+	l.WithGroup("http").WithGroup("server").Warn("warn from http.server")
+	l.WithGroup("api").WithGroup("http").Debug("debug from api.http")
+	l.Info("info without group")
+
+	assert.Contains(t, buf.String(), "debug from http")
+	assert.Contains(t, buf.String(), "debug from http.client")
+	assert.NotContains(t, buf.String(), "warn from http.server")
+	assert.NotContains(t, buf.String(), "debug from api.http")
+	assert.NotContains(t, buf.String(), "info without group")
+	assert.Empty(t, h.Stats().Warnings)
+
+	cfg := slogscope.Config{Packages: []slogscope.Package{
+		{Name: "!http", Match: slogscope.MatchGroup},
+		{Name: "http*", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchGroup},
+	}}
+	assert.EqualError(t, cfg.Validate(), `invalid config (2 problems): packages[0].name: exclusions are not supported for groups; `+
+		`packages[1].name: invalid group "http*": must not contain wildcards`)
+}
+
+func TestHandler_VendoredPaths(t *testing.T) {
+	var buf bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/dep/lib", LogLevel: slogscope.LogLevelDebug},
+				{Name: "**/github.com/stretchr/testify/assert/*.go", LogLevel: slogscope.LogLevelDebug, Match: slogscope.MatchFile},
+			},
+		},
+	})
+
+	t.Run("test vendored package paths", func(t *testing.T) {
+		for _, pkgName := range []string{"github.com/myorg/service/vendor/github.com/dep/lib", "vendor/github.com/dep/lib/sub"} {
+			m := h.ExplainMatch(pkgName)
+			assert.Equal(t, "github.com/dep/lib", m.Rule, pkgName)
+			assert.Equal(t, slogscope.LogLevelDebug, m.LogLevel, pkgName)
+		}
+		assert.Empty(t, h.ExplainMatch("github.com/myorg/vendored/lib").Rule)
+	})
+
+	t.Run("test file paths within the module cache", func(t *testing.T) {
+		// The file path of the assert package contains the version of testify, unless it is vendored.
+		pc := reflect.ValueOf(assert.True).Pointer()
+		rec := slog.NewRecord(time.Now(), slog.LevelDebug, "debug from testify", pc)
+		assert.NoError(t, h.Handle(context.Background(), rec))
+		assert.Contains(t, buf.String(), "debug from testify")
+	})
+}
+
+func TestHandler_TestPackages(t *testing.T) {
+	newHandler := func(buf *bytes.Buffer, testPackages bool, packages ...slogscope.Package) *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{LogLevel: slogscope.LogLevelWarn, TestPackages: testPackages, Packages: packages},
+		})
+	}
+	rule := slogscope.Package{Name: "github.com/apperia-de/slogscope", LogLevel: slogscope.LogLevelDebug}
+
+	t.Run("test rule of the package under test applies", func(t *testing.T) {
+		var buf bytes.Buffer
+		slog.New(newHandler(&buf, true, rule)).Debug("debug from test package")
+		assert.Contains(t, buf.String(), "debug from test package")
+	})
+
+	t.Run("test disabled by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		slog.New(newHandler(&buf, false, rule)).Debug("debug from test package")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("test rule of the test package takes precedence", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := newHandler(&buf, true, rule, slogscope.Package{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelError})
+		slog.New(h).Debug("debug from test package")
+		assert.Empty(t, buf.String())
+		assert.Equal(t, "github.com/apperia-de/slogscope_test", h.ExplainMatch("github.com/apperia-de/slogscope_test").Rule)
+		assert.Equal(t, "github.com/apperia-de/slogscope", h.ExplainMatch("github.com/apperia-de/slogscope/sub_test").Rule)
+	})
+}
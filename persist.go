@@ -0,0 +1,65 @@
+package slogscope
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// persist writes the config back to HandlerOptions.ConfigFile if HandlerOptions.PersistChanges is enabled, so runtime
+// changes survive restarts. Configs from a ConfigSource, a ConfigProvider, multiple ConfigFiles or a config directory
+// are not persisted.
+func (ss *slogscope) persist(cfg Config) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.opts.PersistChanges || ss.hasSource() || len(ss.opts.ConfigFiles) > 0 || ss.opts.ConfigFile == "" || isDir(ss.opts.ConfigFile) {
+		return
+	}
+	if err := writeFileAtomic(ss.opts.ConfigFile, cfg); err != nil {
+		slog.New(ss.slogh).Warn("slogscope: error persisting config", "file", ss.opts.ConfigFile, "error", err.Error())
+		return
+	}
+	ss.logger.Debug("persisted config", "file", ss.opts.ConfigFile)
+}
+
+// writeFileAtomic encodes the config depending on the file extension (see marshalConfig) and replaces the file
+// atomically by writing a temporary file within the same directory and renaming it afterwards.
+func writeFileAtomic(filename string, cfg Config) error {
+	data, err := marshalConfig(filename, &cfg)
+	if err != nil {
+		return err
+	}
+	return writeDataAtomic(filename, data)
+}
+
+// writeDataAtomic replaces the file atomically by writing a temporary file within the same directory and renaming it
+// afterwards.
+func writeDataAtomic(filename string, data []byte) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(filename); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails after a successful rename.
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
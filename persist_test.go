@@ -0,0 +1,67 @@
+package slogscope_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_PersistChanges(t *testing.T) {
+	newHandler := func(persist bool) (*slogscope.Handler, string) {
+		file := filepath.Join(t.TempDir(), "slogscope.json")
+		assert.NoError(t, os.WriteFile(file, []byte(`{"log_level": "INFO"}`), 0600))
+		return slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigFile:     file,
+			PersistChanges: persist,
+		}), file
+	}
+	load := func(file string) slogscope.Config {
+		cfg, err := slogscope.NewFileProvider(file).Load()
+		assert.NoError(t, err)
+		return cfg
+	}
+
+	t.Run("test runtime changes are persisted", func(t *testing.T) {
+		h, file := newHandler(true)
+		h.UseConfig(newCfg)
+		assert.Equal(t, newCfg, load(file))
+
+		fi, err := os.Stat(file)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+		entries, err := os.ReadDir(filepath.Dir(file))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary file must be removed")
+	})
+
+	t.Run("test temporary changes are not persisted", func(t *testing.T) {
+		h, file := newHandler(true)
+		h.UsePackageLevelTemporarily("github.com/foo/bar", slogscope.LogLevelDebug, time.Hour)
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, load(file))
+	})
+
+	t.Run("test permanent changes during temporary changes are persisted without them", func(t *testing.T) {
+		h, file := newHandler(true)
+		cancel := h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelDebug}, time.Hour)
+		h.SetPackageLevel("github.com/foo/bar", slogscope.LogLevelWarn)
+		want := slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn}},
+		}
+		assert.Equal(t, want, load(file))
+
+		cancel()
+		assert.Equal(t, want, h.GetConfig())
+	})
+
+	t.Run("test changes are not persisted by default", func(t *testing.T) {
+		h, file := newHandler(false)
+		h.UseConfig(newCfg)
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, load(file))
+	})
+}
@@ -0,0 +1,30 @@
+package slogscope
+
+import (
+	"fmt"
+	"os"
+)
+
+// profile returns the name of the active profile, which is HandlerOptions.Profile or, if not set,
+// the environment variable SLOGSCOPE_PROFILE.
+func (ss *slogscope) profile() string {
+	if ss.opts.Profile != "" {
+		return ss.opts.Profile
+	}
+	return os.Getenv(envProfile)
+}
+
+// applyProfile returns the config with the settings of the active profile applied (see mergeConfig).
+func (ss *slogscope) applyProfile(cfg Config) Config {
+	name := ss.profile()
+	if name == "" {
+		return cfg
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		ss.logger.Debug(fmt.Sprintf("unknown profile %q! -> using config without profile.", name))
+		return cfg
+	}
+	ss.logger.Debug(fmt.Sprintf("using profile %q", name))
+	return mergeConfig(cfg, p)
+}
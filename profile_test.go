@@ -0,0 +1,48 @@
+package slogscope_test
+
+import (
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Profile(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelWarn}},
+		Profiles: map[string]slogscope.Config{
+			"dev":  {LogLevel: slogscope.LogLevelDebug, Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug}}},
+			"prod": {Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelError}}},
+		},
+	}
+	packages := func(profile string) []slogscope.PackageInfo {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &cfg, Profile: profile})
+		return h.GetPackages()
+	}
+	thisPkg := slogscope.PackageInfo{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelWarn, Source: "HandlerOptions.Config"}
+
+	t.Run("test without profile", func(t *testing.T) {
+		assert.Equal(t, []slogscope.PackageInfo{thisPkg}, packages(""))
+	})
+
+	t.Run("test profile adds packages", func(t *testing.T) {
+		assert.Equal(t, []slogscope.PackageInfo{
+			thisPkg,
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug, Source: "HandlerOptions.Config (profile dev)"},
+		}, packages("dev"))
+	})
+
+	t.Run("test profile overrides package log level", func(t *testing.T) {
+		assert.Equal(t, slogscope.LogLevelError, packages("prod")[0].LogLevel)
+	})
+
+	t.Run("test profile from environment", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_PROFILE", "prod")
+		assert.Equal(t, slogscope.LogLevelError, packages("")[0].LogLevel)
+	})
+
+	t.Run("test unknown profile", func(t *testing.T) {
+		assert.Equal(t, []slogscope.PackageInfo{thisPkg}, packages("staging"))
+	})
+}
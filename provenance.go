@@ -0,0 +1,197 @@
+package slogscope
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+)
+
+// provenance describes where the current config and its package rules came from.
+type provenance struct {
+	source   string            // Source of the config, e.g. "file slogscope.yml" or "UseConfig".
+	packages map[string]string // Sources of individual package rules, which differ from source, by package name.
+	problems ValidationErrors  // Problems found while decoding the config, e.g. unknown keys.
+	session  string            // Name of the override session of a temporary config (see HandlerOptions.AnnotateOverrides).
+}
+
+// with returns a copy of the provenance with the source of the given package rule replaced.
+func (p provenance) with(pkgName, source string) provenance {
+	p.packages = maps.Clone(p.packages)
+	if p.packages == nil {
+		p.packages = map[string]string{}
+	}
+	p.packages[pkgName] = source
+	return p
+}
+
+// without returns a copy of the provenance without a source of the given package rule, e.g. after removing it.
+func (p provenance) without(pkgName string) provenance {
+	p.packages = maps.Clone(p.packages)
+	delete(p.packages, pkgName)
+	return p
+}
+
+// withGlobal returns a copy of the provenance with the source of the config replaced, keeping the sources of the given
+// package rules.
+func (p provenance) withGlobal(source string, pkgs []Package) provenance {
+	p.packages = maps.Clone(p.packages)
+	if p.packages == nil {
+		p.packages = map[string]string{}
+	}
+	for _, pkg := range pkgs {
+		if _, ok := p.packages[pkg.Name]; !ok {
+			p.packages[pkg.Name] = p.source
+		}
+	}
+	p.source = source
+	return p
+}
+
+// sourcer is implemented by ConfigSources and ConfigProviders, which know the provenance of the Config they loaded last.
+type sourcer interface {
+	provenance() provenance
+}
+
+// SourceDescriber is implemented by ConfigSources and ConfigProviders of other packages, which describe where their Config comes from,
+// e.g. "etcd http://localhost:2379 key /config/slogscope.yml". The description is reported as source of the config
+// and its package rules, e.g. by Handler.ExplainDecision.
+type SourceDescriber interface {
+	DescribeSource() string
+}
+
+// sourceProvenance returns the provenance of the Config loaded last by the ConfigSource.
+func sourceProvenance(s ConfigSource) provenance {
+	if src, ok := s.(sourcer); ok {
+		return src.provenance()
+	}
+	if d, ok := s.(SourceDescriber); ok {
+		return provenance{source: d.DescribeSource()}
+	}
+	return provenance{source: fmt.Sprintf("config source %T", s)}
+}
+
+// provenanceOf returns the provenance of the Config loaded last by the ConfigProvider.
+func provenanceOf(p ConfigProvider) provenance {
+	if s, ok := p.(sourcer); ok {
+		return s.provenance()
+	}
+	if d, ok := p.(SourceDescriber); ok {
+		return provenance{source: d.DescribeSource()}
+	}
+	return provenance{source: fmt.Sprintf("config provider %T", p)}
+}
+
+// packageLines returns the source of every package rule of the config file in the format "file <filename>:<line>".
+// The line is the first line containing the package name, which is good enough for YAML, JSON and TOML files
+// formatted as usual.
+func packageLines(filename string, data []byte, packages []Package) map[string]string {
+	lines := bytes.Split(data, []byte("\n"))
+	sources := make(map[string]string, len(packages))
+	for _, p := range packages {
+		for i, line := range lines {
+			if bytes.Contains(line, []byte(p.Name)) && bytes.Contains(line, []byte("name")) {
+				sources[p.Name] = fmt.Sprintf("file %s:%d", filename, i+1)
+				break
+			}
+		}
+	}
+	return sources
+}
+
+// envProvenance returns the provenance of a Config built by NewConfigFromEnv.
+func envProvenance(cfg Config) provenance {
+	prov := provenance{source: "env " + envLogLevel}
+	for _, p := range cfg.Packages {
+		if _, ok := os.LookupEnv(envPackage + p.Name); ok {
+			prov = prov.with(p.Name, "env "+envPackage+p.Name)
+		} else {
+			prov = prov.with(p.Name, "env "+envPackages)
+		}
+	}
+	return prov
+}
+
+// resolveSources returns the sources of the global log level and of all package rules of the config, taking the
+// service section, the active profile and rollout into account.
+func (ss *slogscope) resolveSources(cfg Config) (string, map[string]string) {
+	global := ss.prov.source
+	sources := make(map[string]string, len(cfg.Packages))
+	for _, p := range cfg.Packages {
+		sources[p.Name] = ss.prov.source
+	}
+	maps.Copy(sources, ss.prov.packages)
+
+	if id := ss.service(); len(cfg.Services) > 0 {
+		if s, ok := cfg.Services[id]; ok {
+			if s.LogLevel != "" {
+				global += fmt.Sprintf(" (service %s)", id)
+			}
+			for _, pkg := range s.Packages {
+				sources[pkg.Name] = fmt.Sprintf("%s (service %s)", ss.prov.source, id)
+			}
+		}
+	}
+	if name := ss.profile(); name != "" {
+		if p, ok := cfg.Profiles[name]; ok {
+			if p.LogLevel != "" {
+				global += fmt.Sprintf(" (profile %s)", name)
+			}
+			for _, pkg := range p.Packages {
+				sources[pkg.Name] = fmt.Sprintf("%s (profile %s)", ss.prov.source, name)
+			}
+		}
+	}
+	if cfg.Rollout != nil && ss.inRollout(cfg.Rollout) {
+		if cfg.Rollout.LogLevel != "" {
+			global += " (rollout)"
+		}
+		for _, pkg := range cfg.Rollout.Packages {
+			sources[pkg.Name] = fmt.Sprintf("%s (rollout)", ss.prov.source)
+		}
+	}
+	if m := cfg.Maintenance; m != nil && ss.maintenance {
+		if m.LogLevel != "" {
+			global += " (maintenance)"
+		}
+		for _, pkg := range m.Packages {
+			sources[pkg.Name] = fmt.Sprintf("%s (maintenance)", ss.prov.source)
+		}
+	}
+	if v := ss.opts.Verbosity; v > 0 {
+		global += fmt.Sprintf(" (verbosity %s)", verbosityFlag(v))
+	}
+	return global, sources
+}
+
+// Decision explains whether records of a package at a log level are enabled, and which rule decided it.
+type Decision struct {
+	Package  string `json:"package"`
+	Level    string `json:"level"`
+	Enabled  bool   `json:"enabled"`
+	Rule     string `json:"rule,omitempty"` // Name of the matching package rule, empty if the global log level applies.
+	LogLevel string `json:"log_level"`      // Log level of the matching package rule or the global log level.
+	Source   string `json:"source"`         // Source of the rule, e.g. "file slogscope.yml:12" or "admin PUT /config".
+	Tapped   bool   `json:"tapped"`         // Whether the record is enabled only for taps, e.g. by CaptureToFile.
+}
+
+// ExplainDecision explains whether records of the given package at the given log level are enabled,
+// which rule decided it and where the rule came from. Vendored package paths are normalized to their import paths.
+func (h *Handler) ExplainDecision(pkgName string, lvl slog.Level) Decision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pkgName = normalizePackagePath(pkgName)
+	ruleLvl := h.settings.Load().logLvl
+	d := Decision{Package: pkgName, Level: lvl.String(), LogLevel: ruleLvl.String(), Source: h.globalSource}
+	if p, ok := h.rule(pkgName); ok {
+		d.Rule, d.LogLevel, d.Source = p.name, p.logLevel.String(), p.source
+		ruleLvl = p.logLevel
+	}
+	d.Enabled = lvl >= ruleLvl
+	if !d.Enabled && h.taps.enabled(pkgName, lvl) {
+		d.Enabled, d.Tapped = true, true
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+package slogscope_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ExplainDecision(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "slogscope.yml")
+	assert.NoError(t, os.WriteFile(file, []byte(`log_level: INFO
+packages:
+  - name: github.com/foo/bar
+    log_level: ERROR
+  - name: github.com/foo/baz
+    log_level: DEBUG
+`), 0644))
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: file})
+
+	t.Run("test package rule is explained with its file line", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.Equal(t, slogscope.Decision{
+			Package:  "github.com/foo/baz",
+			Level:    "DEBUG",
+			Enabled:  true,
+			Rule:     "github.com/foo/baz",
+			LogLevel: "DEBUG",
+			Source:   "file " + file + ":5",
+		}, h.ExplainDecision("github.com/foo/baz", slog.LevelDebug))
+	})
+
+	t.Run("test global log level is explained", func(t *testing.T) {
+		requireFullBuild(t)
+
+		d := h.ExplainDecision("github.com/foo/qux", slog.LevelDebug)
+		assert.False(t, d.Enabled)
+		assert.Empty(t, d.Rule)
+		assert.Equal(t, "INFO", d.LogLevel)
+		assert.Equal(t, "file "+file, d.Source)
+	})
+
+	t.Run("test temporary override is recorded as source", func(t *testing.T) {
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL+"/overrides", "application/json",
+			strings.NewReader(`{"package": "github.com/foo/bar", "log_level": "DEBUG", "ttl": "1h"}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		resp, err = http.Get(srv.URL + "/explain?package=github.com/foo/bar&level=DEBUG")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var d slogscope.Decision
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&d))
+		assert.True(t, d.Enabled)
+		assert.True(t, strings.HasPrefix(d.Source, "admin POST /overrides from 127.0.0.1:"), d.Source)
+		assert.Contains(t, d.Source, " until "+time.Now().Add(time.Hour).Format("2006-01-02"))
+
+		// Other package rules keep their source.
+		for _, p := range h.GetPackages() {
+			if p.Name == "github.com/foo/baz" {
+				assert.Equal(t, "file "+file+":5", p.Source)
+			}
+		}
+	})
+
+	t.Run("test environment variables are recorded as source", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_PACKAGES", "github.com/foo/bar=WARN")
+		t.Setenv("SLOGSCOPE_PKG_github.com/foo/baz", "DEBUG")
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFromEnv: true})
+		assert.Equal(t, "env SLOGSCOPE_PACKAGES", h.ExplainDecision("github.com/foo/bar", slog.LevelWarn).Source)
+		assert.Equal(t, "env SLOGSCOPE_PKG_github.com/foo/baz", h.ExplainDecision("github.com/foo/baz", slog.LevelWarn).Source)
+		assert.Equal(t, "env SLOGSCOPE_LOG_LEVEL", h.ExplainDecision("github.com/foo/qux", slog.LevelWarn).Source)
+	})
+}
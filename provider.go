@@ -0,0 +1,82 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// ConfigProvider is a source of a Config, e.g. a config file, a database or a feature flag system.
+// Use it via HandlerOptions.ConfigSource and SourceOf.
+type ConfigProvider interface {
+	// Load returns the current Config of the source.
+	Load() (Config, error)
+}
+
+// ConfigWatcher is a ConfigProvider, which additionally notifies about changes of its Config.
+// If HandlerOptions.EnableFileWatcher is true, the ConfigSource returned by SourceOf watches it.
+type ConfigWatcher interface {
+	ConfigProvider
+	// Watch starts watching the source in the background and sends every changed Config to ch, until done is closed.
+	// It returns an error if watching the source is not possible.
+	Watch(ch chan<- Config, done <-chan struct{}) error
+}
+
+// FileProvider is a ConfigWatcher reading the Config from a YAML, JSON or TOML config file (see unmarshalConfig).
+// It is used for HandlerOptions.ConfigFile, if no HandlerOptions.ConfigSource is given.
+type FileProvider struct {
+	filename string
+	dir      bool // Watches the directory of the config file instead of the file itself (see NewConfigMapProvider).
+	logger   *slog.Logger
+
+	mu   sync.Mutex
+	prov provenance // Provenance of the last loaded config.
+}
+
+// NewFileProvider returns a FileProvider for the given config file.
+func NewFileProvider(filename string) *FileProvider {
+	return &FileProvider{filename: filename, logger: slog.New(NewNilHandler())}
+}
+
+// NewConfigMapProvider returns a FileProvider for a config file mounted from a Kubernetes ConfigMap.
+// ConfigMap mounts are updated by atomically swapping the symlink of the ..data directory, which removes the watched
+// file from the perspective of a file watcher. Therefore, the provider watches the directory of the config file and
+// reloads the config whenever the content of the file, after resolving all symlinks, changes.
+// This also works for editors, which replace files on save.
+func NewConfigMapProvider(filename string) *FileProvider {
+	p := NewFileProvider(filename)
+	p.dir = true
+	return p
+}
+
+// Load reads and decodes the config file.
+func (p *FileProvider) Load() (Config, error) {
+	data, err := os.ReadFile(p.filename)
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading config file (%s): %w", p.filename, err)
+	}
+	return p.decode(data)
+}
+
+// decode decodes the content of the config file and remembers the provenance of its package rules.
+func (p *FileProvider) decode(data []byte) (Config, error) {
+	var cfg Config
+	if err := unmarshalConfig(p.filename, data, &cfg); err != nil {
+		return cfg, fmt.Errorf("error unmarshalling config file (%s): %w", p.filename, err)
+	}
+	p.mu.Lock()
+	p.prov = provenance{
+		source:   "file " + p.filename,
+		packages: packageLines(p.filename, data, cfg.Packages),
+		problems: unknownKeys(p.filename, data),
+	}
+	p.mu.Unlock()
+	return cfg, nil
+}
+
+func (p *FileProvider) provenance() provenance {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.prov
+}
@@ -0,0 +1,162 @@
+// Package consul provides a slogscope.ConfigWatcher reading the config from a Consul KV key. Importing the package
+// registers the scheme "consul" for slogscope.NewProviderFromURL, e.g. consul://localhost:8500/config/slogscope.yml.
+package consul
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+)
+
+func init() {
+	slogscope.RegisterProviderType("consul", newProviderFromURL)
+}
+
+// newProviderFromURL creates a Provider for a URL like consul://localhost:8500/config/slogscope.yml.
+func newProviderFromURL(u *url.URL) (slogscope.ConfigProvider, error) {
+	return NewProvider("http://"+u.Host, u.Path), nil
+}
+
+// consulWaitTime is the maximum duration of a blocking query to Consul.
+const consulWaitTime = 5 * time.Minute
+
+// Provider is a slogscope.ConfigWatcher reading the Config from a Consul KV key. The config format is determined
+// by the extension of the key (see slogscope.UnmarshalConfig), e.g. config/slogscope.yml. Changes of the key are
+// detected via blocking queries and applied within seconds. An ACL token is taken from the CONSUL_HTTP_TOKEN
+// environment variable.
+type Provider struct {
+	address string
+	key     string
+	token   string
+	client  *http.Client
+	logger  *slog.Logger
+
+	mu    sync.Mutex
+	index uint64 // Consul index of the last loaded config.
+}
+
+// NewProvider returns a Provider for the given key, using the Consul agent address, e.g. http://localhost:8500.
+func NewProvider(address, key string) *Provider {
+	return &Provider{
+		address: strings.TrimSuffix(address, "/"),
+		key:     strings.TrimPrefix(key, "/"),
+		token:   os.Getenv("CONSUL_HTTP_TOKEN"),
+		client:  &http.Client{},
+		logger:  slog.New(slogscope.NewNilHandler()),
+	}
+}
+
+// Load reads the current Config from the Consul key.
+func (p *Provider) Load() (slogscope.Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg, _, err := p.get(ctx, 0)
+	return cfg, err
+}
+
+// Watch watches the Consul key using blocking queries and sends the Config after every change.
+func (p *Provider) Watch(ch chan<- slogscope.Config, done <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	go func() {
+		for ctx.Err() == nil {
+			p.mu.Lock()
+			index := p.index
+			p.mu.Unlock()
+
+			cfg, modified, err := p.get(ctx, index)
+			if err != nil {
+				if ctx.Err() == nil {
+					p.logger.Debug(err.Error())
+				}
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+				}
+				continue
+			}
+			if !modified {
+				continue
+			}
+			p.logger.Debug(fmt.Sprintf("consul key (%s) was modified.", p.key))
+			select {
+			case ch <- cfg:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return nil
+}
+
+// get reads the Consul key. With an index greater than zero, it blocks until the key changes after that index or
+// the wait time elapses, and reports whether the key was modified.
+func (p *Provider) get(ctx context.Context, index uint64) (slogscope.Config, bool, error) {
+	var cfg slogscope.Config
+	q := url.Values{"raw": {""}}
+	if index > 0 {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", consulWaitTime.String())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/kv/"+p.key+"?"+q.Encode(), nil)
+	if err != nil {
+		return cfg, false, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Consul-Token", p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return cfg, false, fmt.Errorf("error requesting consul key (%s): %w", p.key, err)
+	}
+	defer resp.Body.Close()
+
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	p.mu.Lock()
+	// The index must be reset if it goes backwards, see https://developer.hashicorp.com/consul/api-docs/features/blocking
+	if newIndex < p.index {
+		newIndex = 0
+	}
+	p.index = newIndex
+	p.mu.Unlock()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return cfg, false, fmt.Errorf("consul key %q does not exists", p.key)
+	default:
+		return cfg, false, fmt.Errorf("error requesting consul key (%s): unexpected response: %s", p.key, resp.Status)
+	}
+	if index > 0 && newIndex == index {
+		// The wait time elapsed without changes.
+		return cfg, false, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return cfg, false, fmt.Errorf("error reading consul key (%s): %w", p.key, err)
+	}
+	if cfg, err = slogscope.UnmarshalConfig(p.key, data); err != nil {
+		return cfg, false, fmt.Errorf("error unmarshalling consul key (%s): %w", p.key, err)
+	}
+	return cfg, true, nil
+}
+
+// DescribeSource describes the source of the config (see slogscope.SourceDescriber).
+func (p *Provider) DescribeSource() string {
+	return fmt.Sprintf("consul %s key %s", p.address, p.key)
+}
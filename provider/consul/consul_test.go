@@ -0,0 +1,52 @@
+package consul_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/provider/consul"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider(t *testing.T) {
+	updates, stop := make(chan string), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/config/slogscope.yml", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+
+		if r.URL.Query().Get("index") == "" {
+			w.Header().Set("X-Consul-Index", "3")
+			_, _ = w.Write([]byte("log_level: ERROR"))
+			return
+		}
+		select {
+		case value := <-updates:
+			w.Header().Set("X-Consul-Index", "4")
+			_, _ = w.Write([]byte(value))
+		case <-stop:
+		}
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	t.Setenv("CONSUL_HTTP_TOKEN", "secret")
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    consul.NewProvider(srv.URL, "config/slogscope.yml"),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	updates <- "log_level: DEBUG"
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewProviderFromURL(t *testing.T) {
+	p, err := slogscope.NewProviderFromURL("consul://localhost:8500/config/slogscope.yml")
+	assert.NoError(t, err)
+	assert.IsType(t, &consul.Provider{}, p)
+}
@@ -0,0 +1,19 @@
+module github.com/apperia-de/slogscope/provider/consul
+
+go 1.23
+
+require (
+	github.com/apperia-de/slogscope v1.1.0
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/apperia-de/slogscope => ../..
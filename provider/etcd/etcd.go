@@ -0,0 +1,242 @@
+// Package etcd provides a slogscope.ConfigWatcher reading the config from an etcd key. Importing the package registers
+// the scheme "etcd" for slogscope.NewProviderFromURL, e.g. etcd://localhost:2379/config/slogscope.yml.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+)
+
+func init() {
+	slogscope.RegisterProviderType("etcd", newProviderFromURL)
+}
+
+// newProviderFromURL creates a Provider for a URL like etcd://localhost:2379/config/slogscope.yml.
+func newProviderFromURL(u *url.URL) (slogscope.ConfigProvider, error) {
+	return NewProvider("http://"+u.Host, u.Path), nil
+}
+
+// Provider is a slogscope.ConfigWatcher reading the Config from an etcd key, using the JSON gateway of the etcd v3
+// API. The config format is determined by the extension of the key (see slogscope.UnmarshalConfig), e.g.
+// /config/slogscope.yml. Changes of the key are received via a watch stream and applied immediately.
+type Provider struct {
+	endpoint string
+	key      string
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	revision int64 // Revision of the last loaded config.
+}
+
+// etcdKeyValue is a key value pair of the etcd v3 JSON gateway. Keys and values are base64 encoded,
+// int64 values are encoded as strings.
+type etcdKeyValue struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+// NewProvider returns an Provider for the given key, using the etcd endpoint, e.g. http://localhost:2379.
+func NewProvider(endpoint, key string) *Provider {
+	return &Provider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		key:      key,
+		client:   &http.Client{},
+		logger:   slog.New(slogscope.NewNilHandler()),
+	}
+}
+
+// Load reads the current Config from the etcd key.
+func (p *Provider) Load() (slogscope.Config, error) {
+	cfg, _, err := p.load()
+	return cfg, err
+}
+
+// load reads the current Config from the etcd key and returns it together with the current revision of the store.
+func (p *Provider) load() (slogscope.Config, int64, error) {
+	var cfg slogscope.Config
+	var resp struct {
+		Header struct {
+			Revision string `json:"revision"`
+		} `json:"header"`
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.post(ctx, "/v3/kv/range", map[string]any{"key": []byte(p.key)}, &resp); err != nil {
+		return cfg, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return cfg, 0, fmt.Errorf("etcd key %q does not exists", p.key)
+	}
+	cfg, err := p.decode(resp.Kvs[0])
+	rev, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	return cfg, rev, err
+}
+
+// Watch watches the etcd key and sends the Config after every change. Interrupted watch streams are reestablished.
+// If the revision to watch from was already compacted, the config is reloaded and watched from the current revision.
+func (p *Provider) Watch(ch chan<- slogscope.Config, done <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	go func() {
+		for ctx.Err() == nil {
+			err := p.watch(ctx, ch)
+			if err == nil {
+				// The watch was canceled due to a compaction and is re-created right away.
+				continue
+			}
+			if ctx.Err() == nil {
+				p.logger.Debug(fmt.Sprintf("etcd watch error for key (%s): %s.", p.key, err.Error()))
+			}
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return nil
+}
+
+// watch runs a single watch stream, starting after the revision of the last loaded config. It returns nil, if etcd
+// canceled the watch because the revision was compacted, after sending the reloaded config.
+func (p *Provider) watch(ctx context.Context, ch chan<- slogscope.Config) error {
+	p.mu.Lock()
+	req := map[string]any{"key": []byte(p.key), "start_revision": strconv.FormatInt(p.revision+1, 10)}
+	p.mu.Unlock()
+
+	body, err := json.Marshal(map[string]any{"create_request": req})
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/v3/watch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Result struct {
+				Events []struct {
+					Type string       `json:"type"`
+					Kv   etcdKeyValue `json:"kv"`
+				} `json:"events"`
+				Canceled        bool   `json:"canceled"`
+				CancelReason    string `json:"cancel_reason"`
+				CompactRevision string `json:"compact_revision"`
+			} `json:"result"`
+		}
+		if err = dec.Decode(&msg); err != nil {
+			return err
+		}
+		if msg.Result.Canceled {
+			compacted, _ := strconv.ParseInt(msg.Result.CompactRevision, 10, 64)
+			if compacted == 0 {
+				return fmt.Errorf("watch canceled: %s", msg.Result.CancelReason)
+			}
+			return p.reload(ctx, ch, compacted)
+		}
+		for _, ev := range msg.Result.Events {
+			if ev.Type == "DELETE" {
+				p.logger.Debug(fmt.Sprintf("etcd key (%s) was deleted.", p.key))
+				continue
+			}
+			cfg, err := p.decode(ev.Kv)
+			if err != nil {
+				p.logger.Debug(err.Error())
+				continue
+			}
+			p.logger.Debug(fmt.Sprintf("etcd key (%s) was modified.", p.key))
+			select {
+			case ch <- cfg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// reload loads the config after the watched revision was compacted, sends it and resets the revision to watch from
+// to the current revision of the store, which is at least the compaction revision.
+func (p *Provider) reload(ctx context.Context, ch chan<- slogscope.Config, compacted int64) error {
+	p.logger.Debug(fmt.Sprintf("etcd watch for key (%s) canceled, revision %d was compacted -> reloading config.", p.key, compacted))
+	cfg, rev, err := p.load()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.revision = max(rev, compacted-1)
+	p.mu.Unlock()
+
+	select {
+	case ch <- cfg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// decode unmarshals the config of the key value pair and remembers its revision.
+func (p *Provider) decode(kv etcdKeyValue) (slogscope.Config, error) {
+	cfg, err := slogscope.UnmarshalConfig(p.key, kv.Value)
+	if err != nil {
+		return cfg, fmt.Errorf("error unmarshalling etcd key (%s): %w", p.key, err)
+	}
+	if rev, err := strconv.ParseInt(kv.ModRevision, 10, 64); err == nil {
+		p.mu.Lock()
+		p.revision = max(p.revision, rev)
+		p.mu.Unlock()
+	}
+	return cfg, nil
+}
+
+// post sends the request as JSON to the etcd endpoint and decodes the JSON response into resp.
+func (p *Provider) post(ctx context.Context, path string, req, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error requesting etcd (%s): %w", p.endpoint, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error requesting etcd (%s): unexpected response: %s", p.endpoint, httpResp.Status)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// DescribeSource describes the source of the config (see slogscope.SourceDescriber).
+func (p *Provider) DescribeSource() string {
+	return fmt.Sprintf("etcd %s key %s", p.endpoint, p.key)
+}
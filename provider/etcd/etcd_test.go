@@ -0,0 +1,109 @@
+package etcd_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/provider/etcd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider(t *testing.T) {
+	updates, stop := make(chan string), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("/config/slogscope.yml")), req["key"])
+			_ = json.NewEncoder(w).Encode(map[string]any{"kvs": []map[string]any{
+				{"value": []byte("log_level: ERROR"), "mod_revision": "3"},
+			}})
+		case "/v3/watch":
+			assert.Equal(t, "4", req["create_request"].(map[string]any)["start_revision"])
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case value := <-updates:
+					_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"events": []map[string]any{
+						{"kv": map[string]any{"value": []byte(value), "mod_revision": "5"}},
+					}}})
+					w.(http.Flusher).Flush()
+				case <-stop:
+					return
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    etcd.NewProvider(srv.URL, "/config/slogscope.yml"),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	updates <- "log_level: DEBUG"
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestProvider_Compaction(t *testing.T) {
+	var mu sync.Mutex
+	value, revision := "log_level: ERROR", "3"
+	watches := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"header": map[string]any{"revision": revision},
+				"kvs":    []map[string]any{{"value": []byte(value), "mod_revision": "3"}},
+			})
+		case "/v3/watch":
+			start := req["create_request"].(map[string]any)["start_revision"].(string)
+			select {
+			case watches <- start:
+			default:
+			}
+			if start == "4" {
+				// The key was modified and revision 4 compacted in the meantime.
+				value, revision = "log_level: WARN", "12"
+				_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"canceled": true, "compact_revision": "10"}})
+			}
+		}
+	}))
+	defer srv.Close()
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    etcd.NewProvider(srv.URL, "/config/slogscope.yml"),
+		EnableFileWatcher: true,
+	})
+	defer h.Close()
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelWarn
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "4", <-watches)
+	assert.Equal(t, "13", <-watches)
+}
+
+func TestNewProviderFromURL(t *testing.T) {
+	p, err := slogscope.NewProviderFromURL("etcd://localhost:2379/config/slogscope.yml")
+	assert.NoError(t, err)
+	assert.IsType(t, &etcd.Provider{}, p)
+}
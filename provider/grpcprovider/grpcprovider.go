@@ -0,0 +1,173 @@
+// Package grpcprovider provides a slogscope.ConfigWatcher receiving the config from a control plane implementing the
+// gRPC ConfigService defined in proto/config.proto. Importing the package registers the scheme "grpc" for
+// slogscope.NewProviderFromURL, e.g. grpc://control-plane.example.com:443?instance_id=api-1.
+package grpcprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	slogscopepb "github.com/apperia-de/slogscope/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func init() {
+	slogscope.RegisterProviderType("grpc", newProviderFromURL)
+}
+
+// newProviderFromURL creates a Provider for a URL like grpc://control-plane.example.com:443?instance_id=api-1,
+// using the system root CAs.
+func newProviderFromURL(u *url.URL) (slogscope.ConfigProvider, error) {
+	return NewProvider(u.Host, nil, u.Query().Get("instance_id")), nil
+}
+
+// Provider is a slogscope.ConfigWatcher receiving the Config from a control plane implementing the gRPC ConfigService
+// defined in proto/config.proto. Updates are pushed by the control plane via a server stream and applied immediately.
+type Provider struct {
+	target     string
+	instanceID string
+	client     slogscopepb.ConfigServiceClient
+	err        error // Error creating the client connection, returned by Load and Watch.
+	logger     *slog.Logger
+
+	mu   sync.Mutex
+	last []byte // Encoded config of the last update.
+}
+
+// NewProvider returns a Provider for the given target, e.g. control-plane.example.com:443. The TLS config
+// may be nil, in which case the system root CAs are used. The instance ID is sent with the Watch request
+// (default: hostname, see slogscope.HandlerOptions.InstanceID).
+func NewProvider(target string, tlsConfig *tls.Config, instanceID string) *Provider {
+	if instanceID == "" {
+		instanceID = defaultInstanceID()
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "https://"), "/")
+	p := &Provider{
+		target:     target,
+		instanceID: instanceID,
+		logger:     slog.New(slogscope.NewNilHandler()),
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		p.err = fmt.Errorf("error creating grpc client for %s: %w", target, err)
+		return p
+	}
+	p.client = slogscopepb.NewConfigServiceClient(conn)
+	return p
+}
+
+// Load receives the current Config, which is the first update of the stream.
+func (p *Provider) Load() (slogscope.Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var cfg slogscope.Config
+	err := p.watch(ctx, func(c slogscope.Config) bool {
+		cfg = c
+		return false
+	})
+	return cfg, err
+}
+
+// Watch keeps a stream to the control plane open and sends the Config of every update. Broken streams are reopened.
+func (p *Provider) Watch(ch chan<- slogscope.Config, done <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	go func() {
+		for ctx.Err() == nil {
+			err := p.watch(ctx, func(cfg slogscope.Config) bool {
+				p.logger.Debug(fmt.Sprintf("grpc config (%s) was updated.", p.target))
+				select {
+				case ch <- cfg:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			p.logger.Debug(fmt.Sprintf("grpc stream (%s) failed: %v", p.target, err))
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return nil
+}
+
+// watch calls the Watch method and passes every changed Config to fn until fn returns false or the stream ends.
+func (p *Provider) watch(ctx context.Context, fn func(slogscope.Config) bool) error {
+	if p.err != nil {
+		return p.err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := p.client.Watch(ctx, &slogscopepb.WatchRequest{InstanceId: p.instanceID})
+	if err != nil {
+		return fmt.Errorf("error calling watch of %s: %w", p.target, err)
+	}
+
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("stream closed by server")
+		}
+		if err != nil {
+			return err
+		}
+
+		encoded, format := update.GetConfig(), update.GetFormat()
+		p.mu.Lock()
+		unchanged := bytes.Equal(encoded, p.last)
+		p.last = encoded
+		p.mu.Unlock()
+		if unchanged {
+			continue
+		}
+
+		name := ""
+		if format != "" {
+			name = "config." + format
+		}
+		cfg, err := slogscope.UnmarshalConfig(name, encoded)
+		if err != nil {
+			p.logger.Debug(fmt.Sprintf("error unmarshalling grpc config update: %s", err.Error()))
+			continue
+		}
+		if !fn(cfg) {
+			return nil
+		}
+	}
+}
+
+// DescribeSource describes the source of the config (see slogscope.SourceDescriber).
+func (p *Provider) DescribeSource() string {
+	return "grpc " + p.target
+}
+
+// defaultInstanceID returns the hostname or, if unavailable, the process ID.
+func defaultInstanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return strconv.Itoa(os.Getpid())
+}
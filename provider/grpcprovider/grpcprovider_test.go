@@ -0,0 +1,83 @@
+package grpcprovider_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	slogscopepb "github.com/apperia-de/slogscope/proto"
+	"github.com/apperia-de/slogscope/provider/grpcprovider"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// configService is a ConfigService streaming the config set via update.
+type configService struct {
+	slogscopepb.UnimplementedConfigServiceServer
+	t       *testing.T
+	mu      sync.Mutex
+	update  *slogscopepb.ConfigUpdate
+	changed chan struct{}
+}
+
+func (s *configService) set(update *slogscopepb.ConfigUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update = update
+	if s.changed != nil {
+		close(s.changed)
+	}
+	s.changed = make(chan struct{})
+}
+
+func (s *configService) Watch(req *slogscopepb.WatchRequest, stream grpc.ServerStreamingServer[slogscopepb.ConfigUpdate]) error {
+	assert.Equal(s.t, "pod-1", req.GetInstanceId())
+	for {
+		s.mu.Lock()
+		update, changed := s.update, s.changed
+		s.mu.Unlock()
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func TestProvider(t *testing.T) {
+	svc := &configService{t: t}
+	svc.set(&slogscopepb.ConfigUpdate{Config: []byte("log_level: ERROR"), Format: "yaml"})
+	grpcSrv := grpc.NewServer()
+	slogscopepb.RegisterConfigServiceServer(grpcSrv, svc)
+	srv := httptest.NewUnstartedServer(grpcSrv)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    grpcprovider.NewProvider(srv.URL, &tls.Config{RootCAs: roots}, "pod-1"),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	svc.set(&slogscopepb.ConfigUpdate{Config: []byte(`{"log_level": "DEBUG"}`), Format: "json"})
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+	}, time.Second, 10*time.Millisecond)
+	h.UseConfig(h.GetConfig()) // Stops watching.
+}
+
+func TestNewProviderFromURL(t *testing.T) {
+	p, err := slogscope.NewProviderFromURL("grpc://control-plane.example.com:443?instance_id=api-1")
+	assert.NoError(t, err)
+	assert.IsType(t, &grpcprovider.Provider{}, p)
+}
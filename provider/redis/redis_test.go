@@ -0,0 +1,148 @@
+package redis_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/provider/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is a minimal Redis server supporting the commands AUTH, GET and SUBSCRIBE.
+type fakeRedis struct {
+	net.Listener
+	value    string
+	messages chan string
+}
+
+// dropConnection is a message closing the subscribed connection instead of being published.
+const dropConnection = "\x00drop"
+
+func newFakeRedis(t *testing.T, value string) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	r := &fakeRedis{Listener: l, value: value, messages: make(chan string)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	for {
+		var n int
+		if _, err := fmt.Fscanf(br, "*%d\r\n", &n); err != nil {
+			return
+		}
+		args := make([]string, n)
+		for i := range args {
+			var size int
+			if _, err := fmt.Fscanf(br, "$%d\r\n", &size); err != nil {
+				return
+			}
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(br, data); err != nil {
+				return
+			}
+			args[i] = string(data[:size])
+		}
+
+		switch args[0] {
+		case "AUTH":
+			if args[1] != "secret" {
+				_, _ = io.WriteString(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			_, _ = io.WriteString(conn, "+OK\r\n")
+		case "GET":
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(r.value), r.value)
+		case "SUBSCRIBE":
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1])
+			for msg := range r.messages {
+				if msg == dropConnection {
+					return
+				}
+				fmt.Fprintf(conn, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(msg), msg)
+			}
+			return
+		}
+	}
+}
+
+func TestProvider(t *testing.T) {
+	srv := newFakeRedis(t, "log_level: ERROR")
+	defer srv.Close()
+
+	t.Run("test wrong password", func(t *testing.T) {
+		_, err := redis.NewProvider("redis://:wrong@"+srv.Addr().String(), "slogscope.yml", "slogscope").Load()
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    redis.NewProvider("redis://:secret@"+srv.Addr().String(), "slogscope.yml", "slogscope"),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	t.Run("test published config is applied", func(t *testing.T) {
+		srv.messages <- "log_level: DEBUG"
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test empty message reloads the key", func(t *testing.T) {
+		srv.messages <- ""
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelError
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestProvider_Resubscribe(t *testing.T) {
+	srv := newFakeRedis(t, "log_level: ERROR")
+	defer srv.Close()
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    redis.NewProvider(srv.Addr().String(), "slogscope.yml", "slogscope"),
+		EnableFileWatcher: true,
+	})
+	defer h.Close()
+
+	srv.messages <- "log_level: DEBUG"
+	srv.messages <- dropConnection
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+	}, time.Second, 10*time.Millisecond)
+
+	// After resubscribing, the key is reloaded and published configs are received again.
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelError
+	}, 3*time.Second, 10*time.Millisecond)
+	srv.messages <- "log_level: WARN"
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelWarn
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewProviderFromURL(t *testing.T) {
+	p, err := slogscope.NewProviderFromURL("redis://localhost:6379/0?key=slogscope.yml")
+	assert.NoError(t, err)
+	assert.IsType(t, &redis.Provider{}, p)
+
+	_, err = slogscope.NewProviderFromURL("redis://localhost:6379/0")
+	assert.ErrorContains(t, err, "missing query parameter key")
+}
@@ -0,0 +1,89 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DirProvider is a ConfigWatcher merging all config fragments (*.yml and *.yaml files) of a directory, e.g. conf.d/,
+// in lexical order of their filenames, where later fragments override earlier ones (see mergeConfig).
+// It is used for HandlerOptions.ConfigFile, if it points to a directory.
+type DirProvider struct {
+	dir    string
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	prov provenance // Provenance of the last loaded config.
+}
+
+// NewDirProvider returns a DirProvider for the given directory.
+func NewDirProvider(dir string) *DirProvider {
+	return &DirProvider{dir: dir, logger: slog.New(NewNilHandler())}
+}
+
+// Load reads and merges all config fragments of the directory.
+// It returns an error if the directory contains no fragments or any fragment is invalid.
+func (p *DirProvider) Load() (Config, error) {
+	fragments, err := p.fragments()
+	if err != nil {
+		return Config{}, err
+	}
+	if len(fragments) == 0 {
+		return Config{}, fmt.Errorf("config directory (%s) contains no config fragments", p.dir)
+	}
+
+	mp := NewMultiFileProvider(fragments...)
+	mp.logger = p.logger
+	cfg, err := mp.Load()
+	if err != nil {
+		return Config{}, err
+	}
+	p.mu.Lock()
+	p.prov = mp.provenance()
+	p.mu.Unlock()
+	return cfg, nil
+}
+
+func (p *DirProvider) provenance() provenance {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.prov
+}
+
+// fragments returns the paths of all config fragments of the directory in lexical order.
+func (p *DirProvider) fragments() ([]string, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config directory (%s): %w", p.dir, err)
+	}
+	var fragments []string
+	for _, e := range entries {
+		if !e.IsDir() && isFragment(e.Name()) {
+			fragments = append(fragments, filepath.Join(p.dir, e.Name()))
+		}
+	}
+	slices.Sort(fragments)
+	return fragments, nil
+}
+
+// isFragment reports whether the file is a config fragment. Hidden files, e.g. editor swap files or the ..data
+// symlinks of Kubernetes ConfigMap mounts, are ignored.
+func isFragment(filename string) bool {
+	name := filepath.Base(filename)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// isDir reports whether the path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,143 @@
+package slogscope
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// MultiFileProvider is a ConfigWatcher merging multiple config files, where later files override earlier ones,
+// e.g. global defaults, an environment overlay and local developer overrides. Missing files are skipped.
+// It is used by NewFilesSource.
+type MultiFileProvider struct {
+	files  []*FileProvider
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	prov provenance // Provenance of the last loaded config.
+}
+
+// NewMultiFileProvider returns a MultiFileProvider for the given config files in ascending order of precedence.
+func NewMultiFileProvider(filenames ...string) *MultiFileProvider {
+	p := &MultiFileProvider{logger: slog.New(NewNilHandler())}
+	for _, filename := range filenames {
+		p.files = append(p.files, NewFileProvider(filename))
+	}
+	return p
+}
+
+// Load reads all existing config files and merges them (see mergeConfig).
+// It returns an error if none of the config files exists or any existing config file is invalid.
+func (p *MultiFileProvider) Load() (Config, error) {
+	var cfg Config
+	var loaded bool
+	var prov provenance
+	for _, fp := range p.files {
+		if !checkFileExists(fp.filename) {
+			p.logger.Debug(fmt.Sprintf("config file (%s) does not exists! -> skipped.", fp.filename))
+			continue
+		}
+		overlay, err := fp.Load()
+		if err != nil {
+			return Config{}, err
+		}
+		cfg = mergeConfig(cfg, overlay)
+		loaded = true
+
+		fileProv := fp.provenance()
+		prov.source = strings.TrimPrefix(prov.source+", "+fileProv.source, ", ")
+		prov.packages = mergeSources(prov.packages, fileProv.packages)
+		for _, e := range fileProv.problems {
+			prov.problems = append(prov.problems, ValidationError{Field: fp.filename + ": " + e.Field, Message: e.Message})
+		}
+	}
+	if !loaded {
+		return cfg, errors.New("none of the config files exists")
+	}
+	p.mu.Lock()
+	p.prov = prov
+	p.mu.Unlock()
+	return cfg, nil
+}
+
+func (p *MultiFileProvider) provenance() provenance {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.prov
+}
+
+// mergeSources returns base with all sources of overlay applied.
+func mergeSources(base, overlay map[string]string) map[string]string {
+	if base == nil {
+		base = map[string]string{}
+	}
+	maps.Copy(base, overlay)
+	return base
+}
+
+// mergeConfig returns base with all settings of overlay applied. Settings are overridden if they are set in overlay,
+// packages are merged by name (see mergePackages).
+func mergeConfig(base, overlay Config) Config {
+	if overlay.Version != 0 {
+		base.Version = overlay.Version
+	}
+	if overlay.LogLevel != "" {
+		base.LogLevel = overlay.LogLevel
+	}
+	if overlay.Delivery != "" {
+		base.Delivery = overlay.Delivery
+	}
+	base.Packages = mergePackages(base.Packages, overlay.Packages)
+	if overlay.Rollout != nil {
+		base.Rollout = overlay.Rollout
+	}
+	if overlay.Metadata != nil {
+		base.Metadata = overlay.Metadata
+	}
+	if overlay.BuildInfo != nil {
+		base.BuildInfo = overlay.BuildInfo
+	}
+	if overlay.Normalize != nil {
+		base.Normalize = overlay.Normalize
+	}
+	if overlay.Renames != nil {
+		base.Renames = overlay.Renames
+	}
+	if overlay.Sinks != nil {
+		base.Sinks = overlay.Sinks
+	}
+	if overlay.SchemaVersion != "" {
+		base.SchemaVersion = overlay.SchemaVersion
+	}
+	if overlay.Generated != "" {
+		base.Generated = overlay.Generated
+	}
+	if overlay.FallbackScope != "" {
+		base.FallbackScope = overlay.FallbackScope
+	}
+	if overlay.TestPackages {
+		base.TestPackages = true
+	}
+	if overlay.Profiles != nil {
+		// Profiles are merged by name, a profile of overlay replaces the one of base.
+		profiles := maps.Clone(base.Profiles)
+		if profiles == nil {
+			profiles = make(map[string]Config, len(overlay.Profiles))
+		}
+		maps.Copy(profiles, overlay.Profiles)
+		base.Profiles = profiles
+	}
+	if overlay.Services != nil {
+		base.Services = overlay.Services
+	}
+	if overlay.Maintenance != nil {
+		base.Maintenance = overlay.Maintenance
+	}
+	if overlay.QuietHours != nil {
+		base.QuietHours = overlay.QuietHours
+	}
+	return base
+}
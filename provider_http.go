@@ -0,0 +1,147 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPollInterval is the default interval for polling remote config sources.
+const defaultPollInterval = 30 * time.Second
+
+// HTTPProvider is a ConfigWatcher fetching the Config from a URL. The config format is determined by the
+// Content-Type of the response and falls back to the extension of the URL path (see unmarshalConfig).
+// Changes are detected by polling, using conditional requests (ETag and Last-Modified) to avoid needless transfers.
+type HTTPProvider struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu           sync.Mutex
+	cfg          Config
+	etag         string
+	lastModified string
+}
+
+// NewHTTPProvider returns an HTTPProvider for the given URL, polling for changes every interval (default: 30s).
+func NewHTTPProvider(url string, interval time.Duration) *HTTPProvider {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &HTTPProvider{
+		url:      url,
+		interval: interval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   slog.New(NewNilHandler()),
+	}
+}
+
+// Load fetches the current Config from the URL.
+func (p *HTTPProvider) Load() (Config, error) {
+	cfg, _, err := p.fetch()
+	return cfg, err
+}
+
+// Watch polls the URL and sends the Config whenever it was modified.
+func (p *HTTPProvider) Watch(ch chan<- Config, done <-chan struct{}) error {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				cfg, modified, err := p.fetch()
+				if err != nil {
+					p.logger.Debug(err.Error())
+					continue
+				}
+				if !modified {
+					continue
+				}
+				p.logger.Debug(fmt.Sprintf("config (%s) was modified.", p.url))
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// fetch requests the config from the URL and reports whether it was modified since the last successful request.
+func (p *HTTPProvider) fetch() (Config, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, p.url, nil)
+	if err != nil {
+		return p.cfg, false, err
+	}
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	if p.lastModified != "" {
+		req.Header.Set("If-Modified-Since", p.lastModified)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return p.cfg, false, fmt.Errorf("error fetching config (%s): %w", p.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return p.cfg, false, nil
+	default:
+		return p.cfg, false, fmt.Errorf("error fetching config (%s): unexpected response: %s", p.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return p.cfg, false, fmt.Errorf("error reading config (%s): %w", p.url, err)
+	}
+	var cfg Config
+	if err = unmarshalConfig(configName(p.url, resp.Header.Get("Content-Type")), data, &cfg); err != nil {
+		return p.cfg, false, fmt.Errorf("error unmarshalling config (%s): %w", p.url, err)
+	}
+
+	p.cfg = cfg
+	p.etag = resp.Header.Get("ETag")
+	p.lastModified = resp.Header.Get("Last-Modified")
+	return cfg, true, nil
+}
+
+// configName returns a pseudo filename for determining the config format of a remote config via unmarshalConfig.
+func configName(rawURL, contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "config.json"
+	case strings.Contains(contentType, "toml"):
+		return "config.toml"
+	case strings.Contains(contentType, "yaml"):
+		return "config.yml"
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Path
+	}
+	return rawURL
+}
+
+func (p *HTTPProvider) provenance() provenance {
+	return provenance{source: "http " + p.url}
+}
@@ -0,0 +1,276 @@
+package slogscope_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryProvider is a slogscope.ConfigWatcher holding its Config in memory.
+type memoryProvider struct {
+	cfg     slogscope.Config
+	err     error
+	updates chan slogscope.Config
+}
+
+func (p *memoryProvider) Load() (slogscope.Config, error) {
+	return p.cfg, p.err
+}
+
+func (p *memoryProvider) Watch(ch chan<- slogscope.Config, done <-chan struct{}) error {
+	go func() {
+		for {
+			select {
+			case cfg := <-p.updates:
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func TestHandler_ConfigProvider(t *testing.T) {
+	t.Run("test config is loaded and watched", func(t *testing.T) {
+		p := &memoryProvider{cfg: oldCfg, updates: make(chan slogscope.Config)}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigProvider:    p,
+			EnableFileWatcher: true,
+		})
+		assert.Equal(t, oldCfg, h.GetConfig())
+
+		p.updates <- newCfg
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == newCfg.LogLevel
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test default config is used if the provider fails", func(t *testing.T) {
+		p := &memoryProvider{err: errors.New("unavailable")}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigProvider: p})
+		assert.Equal(t, slogscope.Config{LogLevel: slogscope.LogLevelInfo}, h.GetConfig())
+	})
+
+	t.Run("test file provider", func(t *testing.T) {
+		requireFullBuild(t)
+		cfg, err := slogscope.NewFileProvider("test/data/slogscope.test_config.toml").Load()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, cfg.LogLevel)
+
+		_, err = slogscope.NewFileProvider("test/data/does_not_exist.yml").Load()
+		assert.Error(t, err)
+	})
+}
+
+func TestHandler_HTTPProvider(t *testing.T) {
+	requireFullBuild(t)
+
+	var mu sync.Mutex
+	cfg, etag, notModified := "log_level: ERROR", `"v1"`, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(cfg))
+	}))
+	defer srv.Close()
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    slogscope.NewHTTPProvider(srv.URL+"/slogscope", 10*time.Millisecond),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	t.Run("test unchanged config is not transferred again", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return notModified > 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test changed config is applied", func(t *testing.T) {
+		mu.Lock()
+		cfg, etag = `{"log_level": "DEBUG"}`, `"v2"`
+		mu.Unlock()
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestHandler_ConfigMapProvider(t *testing.T) {
+	requireFullBuild(t)
+
+	// Simulate the layout of a ConfigMap mount, which is updated by atomically swapping the ..data symlink.
+	dir := t.TempDir()
+	writeVersion := func(version, cfg string) {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, version), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, version, "slogscope.yml"), []byte(cfg), 0644))
+		assert.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		assert.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", "log_level: ERROR")
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "slogscope.yml"), filepath.Join(dir, "slogscope.yml")))
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigProvider:    slogscope.NewConfigMapProvider(filepath.Join(dir, "slogscope.yml")),
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+
+	for _, lvl := range []string{slogscope.LogLevelDebug, slogscope.LogLevelWarn} {
+		writeVersion("..v"+lvl, "log_level: "+lvl)
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == lvl
+		}, time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestHandler_ConfigFiles(t *testing.T) {
+	requireFullBuild(t)
+
+	dir := t.TempDir()
+	defaults, overlay, local := filepath.Join(dir, "defaults.yml"), filepath.Join(dir, "prod.json"), filepath.Join(dir, "local.yml")
+	assert.NoError(t, os.WriteFile(defaults, []byte(`log_level: INFO
+delivery: durable
+packages:
+  - name: github.com/foo/bar
+    log_level: WARN
+  - name: github.com/foo/baz
+    log_level: WARN
+`), 0644))
+	assert.NoError(t, os.WriteFile(overlay, []byte(`{"log_level": "ERROR", "packages": [{"name": "github.com/foo/baz", "log_level": "DEBUG"}]}`), 0644))
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigFiles:       []string{defaults, overlay, local},
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.Config{
+		LogLevel: slogscope.LogLevelError,
+		Delivery: slogscope.DeliveryDurable,
+		Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn},
+			{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelDebug},
+		},
+	}, h.GetConfig())
+
+	t.Run("test change of any file is merged", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(defaults, []byte("log_level: INFO\n"), 0644))
+		assert.Eventually(t, func() bool {
+			return len(h.GetConfig().Packages) == 1 && h.GetConfig().Delivery == ""
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test file created later is merged", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(local, []byte("log_level: DEBUG\n"), 0644))
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test removed file is no longer merged", func(t *testing.T) {
+		assert.NoError(t, os.Remove(local))
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelError
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestHandler_ConfigFilesProfiles(t *testing.T) {
+	requireFullBuild(t)
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yml")
+	assert.NoError(t, os.WriteFile(a, []byte(`log_level: INFO
+profiles:
+  prod:
+    log_level: ERROR
+  dev:
+    log_level: DEBUG
+`), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte(`log_level: INFO
+profiles:
+  dev:
+    log_level: WARN
+`), 0644))
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigFiles: []string{a, b},
+		Profile:     "prod",
+	})
+	assert.Equal(t, map[string]slogscope.Config{
+		"prod": {LogLevel: slogscope.LogLevelError},
+		"dev":  {LogLevel: slogscope.LogLevelWarn},
+	}, h.GetConfig().Profiles)
+	assert.Equal(t, slogscope.LogLevelError, h.EffectiveConfig().LogLevel)
+}
+
+func TestHandler_ConfigDir(t *testing.T) {
+	requireFullBuild(t)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00-defaults.yml"), []byte(`log_level: INFO
+packages:
+  - name: github.com/foo/bar
+    log_level: WARN
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "10-team.yml"), []byte(`log_level: ERROR
+packages:
+  - name: github.com/foo/bar
+    log_level: DEBUG
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("log_level: WARN\n"), 0644))
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		ConfigFile:        dir,
+		EnableFileWatcher: true,
+	})
+	assert.Equal(t, slogscope.Config{
+		LogLevel: slogscope.LogLevelError,
+		Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug}},
+	}, h.GetConfig())
+
+	t.Run("test added fragment is merged", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "20-local.yml"), []byte("log_level: WARN\n"), 0644))
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelWarn
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test removed fragment is no longer merged", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(dir, "10-team.yml")))
+		assert.Eventually(t, func() bool {
+			cfg := h.GetConfig()
+			return len(cfg.Packages) == 1 && cfg.Packages[0].LogLevel == slogscope.LogLevelWarn
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test default config applies without fragments", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(dir, "00-defaults.yml")))
+		assert.NoError(t, os.Remove(filepath.Join(dir, "20-local.yml")))
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == slogscope.LogLevelInfo && len(h.GetConfig().Packages) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,282 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch watches the config file for changes and sends the reloaded Config after every modification.
+// Watching stops if the config file is removed or renamed.
+func (p *FileProvider) Watch(ch chan<- Config, done <-chan struct{}) error {
+	if p.dir {
+		return p.watchDir(ch, done)
+	}
+	if !checkFileExists(p.filename) {
+		return fmt.Errorf("config file %q does not exists", p.filename)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Add the config file to watch.
+	if err = watcher.Add(p.filename); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	// Start listening for events.
+	go func() {
+		p.logger.Debug(fmt.Sprintf("started file watcher for config file (%s).", p.filename))
+		defer func() {
+			if err := watcher.Close(); err != nil {
+				p.logger.Debug(fmt.Sprintf("file watcher error for config file (%s): %s.", p.filename, err.Error()))
+				return
+			}
+			p.logger.Debug(fmt.Sprintf("stopped file watcher for config file (%s).", p.filename))
+		}()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				switch {
+				case event.Has(fsnotify.Remove):
+					p.logger.Debug(fmt.Sprintf("config file (%s) was removed.", event.Name))
+					return
+				case event.Has(fsnotify.Rename):
+					p.logger.Debug(fmt.Sprintf("config file (%s) was renamed.", event.Name))
+					return
+				case event.Has(fsnotify.Write):
+					p.logger.Debug(fmt.Sprintf("config file (%s) was modified.", event.Name))
+					cfg, err := p.Load()
+					if err != nil {
+						p.logger.Debug(err.Error())
+						continue
+					}
+					select {
+					case ch <- cfg:
+					case <-done:
+						return
+					}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				p.logger.Debug(fmt.Sprintf("file watcher error for config file (%s): %s.", p.filename, err.Error()))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// watchDir watches the directory of the config file and sends the reloaded Config whenever the content of the config
+// file changes. Watching continues if the config file is removed or renamed.
+func (p *FileProvider) watchDir(ch chan<- Config, done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(p.filename)
+	if err = watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	last, _ := os.ReadFile(p.filename)
+
+	go func() {
+		p.logger.Debug(fmt.Sprintf("started directory watcher for config file (%s).", p.filename))
+		defer func() {
+			_ = watcher.Close()
+			p.logger.Debug(fmt.Sprintf("stopped directory watcher for config file (%s).", p.filename))
+		}()
+
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Every event within the directory may swap the symlinks, so the content is compared instead.
+				data, err := os.ReadFile(p.filename)
+				if err != nil || bytes.Equal(data, last) {
+					continue
+				}
+				last = data
+				p.logger.Debug(fmt.Sprintf("config file (%s) was modified.", p.filename))
+
+				cfg, err := p.decode(data)
+				if err != nil {
+					p.logger.Debug(err.Error())
+					continue
+				}
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				p.logger.Debug(fmt.Sprintf("directory watcher error for config file (%s): %s.", p.filename, err.Error()))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Watch watches the directory and sends the merged Config after any config fragment was added, modified, removed
+// or renamed.
+func (p *DirProvider) Watch(ch chan<- Config, done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err = watcher.Add(p.dir); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		p.logger.Debug(fmt.Sprintf("started directory watcher for config directory (%s).", p.dir))
+		defer func() {
+			_ = watcher.Close()
+			p.logger.Debug(fmt.Sprintf("stopped directory watcher for config directory (%s).", p.dir))
+		}()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isFragment(event.Name) || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				p.logger.Debug(fmt.Sprintf("config fragment (%s) was changed: %s.", event.Name, event.Op))
+
+				cfg, err := p.Load()
+				if err != nil {
+					p.logger.Debug(err.Error())
+					if fragments, _ := p.fragments(); len(fragments) > 0 {
+						continue
+					}
+					// Without any fragments, the default config applies again.
+					cfg = Config{LogLevel: defaultLogLevel}
+					p.mu.Lock()
+					p.prov = provenance{source: "default config"}
+					p.mu.Unlock()
+				}
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				p.logger.Debug(fmt.Sprintf("directory watcher error for config directory (%s): %s.", p.dir, err.Error()))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Watch watches the directories of all config files and sends the merged Config after any of the config files was
+// created, modified, removed or renamed, so config files created after the start are merged as well.
+func (p *MultiFileProvider) Watch(ch chan<- Config, done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]bool, len(p.files))
+	var dirs []string
+	for _, fp := range p.files {
+		filename := filepath.Clean(fp.filename)
+		files[filename] = true
+		if dir := filepath.Dir(filename); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	var watched int
+	for _, dir := range dirs {
+		if err = watcher.Add(dir); err != nil {
+			p.logger.Debug(fmt.Sprintf("cannot watch config directory (%s): %s.", dir, err.Error()))
+			continue
+		}
+		watched++
+	}
+	if watched == 0 {
+		_ = watcher.Close()
+		return errors.New("none of the config files can be watched")
+	}
+
+	go func() {
+		p.logger.Debug(fmt.Sprintf("started file watcher for config files (%s).", strings.Join(slices.Sorted(maps.Keys(files)), ", ")))
+		defer func() {
+			_ = watcher.Close()
+			p.logger.Debug("stopped file watcher for config files.")
+		}()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !files[filepath.Clean(event.Name)] || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				p.logger.Debug(fmt.Sprintf("config file (%s) was changed: %s.", event.Name, event.Op))
+
+				// Any change requires merging all files again.
+				cfg, err := p.Load()
+				if err != nil {
+					p.logger.Debug(err.Error())
+					continue
+				}
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				p.logger.Debug(fmt.Sprintf("file watcher error for config files: %s.", err.Error()))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// quietHoursLayout is the layout of QuietHours.From and QuietHours.To.
+const quietHoursLayout = "15:04"
+
+// window returns the start and end of the quiet hours in minutes of the day and their location.
+func (q QuietHours) window() (from, to int, loc *time.Location, err error) {
+	start, err := time.Parse(quietHoursLayout, q.From)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid start %q: expected HH:MM", q.From)
+	}
+	end, err := time.Parse(quietHoursLayout, q.To)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid end %q: expected HH:MM", q.To)
+	}
+	if start.Equal(end) {
+		return 0, 0, nil, fmt.Errorf("start and end %q are equal", q.From)
+	}
+	loc = time.Local
+	if q.Location != "" {
+		if loc, err = time.LoadLocation(q.Location); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid location %q: %w", q.Location, err)
+		}
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), loc, nil
+}
+
+// logLevel returns the minimum log level during the quiet hours.
+func (q QuietHours) logLevel() slog.Level {
+	if q.LogLevel == "" {
+		return slog.LevelWarn
+	}
+	lvl, _ := parseLogLevel(q.LogLevel)
+	return lvl
+}
+
+// quietFloor returns the minimum log level of all quiet hours active at now, whether any of them is active, and the
+// next time any of them starts or ends. Invalid quiet hours are ignored.
+func quietFloor(hours []QuietHours, now time.Time) (floor slog.Level, active bool, next time.Time) {
+	for _, q := range hours {
+		from, to, loc, err := q.window()
+		if err != nil {
+			continue
+		}
+		t := now.In(loc)
+		minute := t.Hour()*60 + t.Minute()
+		// Quiet hours may span midnight, e.g. 22:00-02:00.
+		if from < to && minute >= from && minute < to || from > to && (minute >= from || minute < to) {
+			if !active || q.logLevel() > floor {
+				floor = q.logLevel()
+			}
+			active = true
+		}
+		// The boundaries are wall clock times, which differ from midnight plus the minutes on days with DST changes.
+		for _, m := range []int{from, to, from + 24*60, to + 24*60} {
+			boundary := time.Date(t.Year(), t.Month(), t.Day(), m/60, m%60, 0, 0, loc)
+			if boundary.After(now) && (next.IsZero() || boundary.Before(next)) {
+				next = boundary
+			}
+		}
+	}
+	return floor, active, next
+}
+
+// applyQuietHours applies the quiet hours active at the current time (see withQuietHours) and reapplies the config as
+// soon as the next quiet hours start or end. It reports whether quiet hours are active. The caller must hold ss.mu.
+func (ss *slogscope) applyQuietHours(cfg Config) (Config, bool) {
+	if ss.quietTimer != nil {
+		ss.quietTimer.Stop()
+		ss.quietTimer = nil
+	}
+	cfg, active, next := withQuietHours(cfg, ss.now())
+	if !next.IsZero() {
+		ss.quietTimer = ss.reconfigureAt(next)
+	}
+	return cfg, active
+}
+
+// withQuietHours raises the global log level and the log levels of all package rules and functions to the minimum
+// log level of the quiet hours active at now (see Config.QuietHours). It reports whether quiet hours are active and
+// returns the next time any quiet hours start or end.
+func withQuietHours(cfg Config, now time.Time) (Config, bool, time.Time) {
+	floor, active, next := quietFloor(cfg.QuietHours, now)
+	if !active {
+		return cfg, false, next
+	}
+
+	raise := func(level string) string {
+		lvl, _ := parseLogLevel(level)
+		return max(lvl, floor).String()
+	}
+	cfg.LogLevel = raise(cfg.LogLevel)
+	packages := make([]Package, len(cfg.Packages))
+	for i, p := range cfg.Packages {
+		if strings.HasPrefix(p.Name, "!") {
+			// Exclusions have no log level of their own.
+			packages[i] = p
+			continue
+		}
+		// Rules without a log level are resolved like in slogscope.configure before raising them.
+		p.LogLevel = raise(p.LogLevel)
+		raiseFunctions := func(functions []Function) []Function {
+			if functions == nil {
+				return nil
+			}
+			raised := make([]Function, len(functions))
+			for j, f := range functions {
+				f.LogLevel = raise(f.LogLevel)
+				raised[j] = f
+			}
+			return raised
+		}
+		p.Functions, p.Receivers = raiseFunctions(p.Functions), raiseFunctions(p.Receivers)
+		packages[i] = p
+	}
+	if cfg.Packages != nil {
+		cfg.Packages = packages
+	}
+	return cfg, true, next
+}
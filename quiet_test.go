@@ -0,0 +1,139 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_QuietHours(t *testing.T) {
+	now := time.Now().UTC()
+	window := func(from, to time.Duration) slogscope.QuietHours {
+		return slogscope.QuietHours{From: now.Add(from).Format("15:04"), To: now.Add(to).Format("15:04"), Location: "UTC"}
+	}
+	newHandler := func(q slogscope.QuietHours) *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel:   slogscope.LogLevelDebug,
+				Packages:   []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelInfo}},
+				QuietHours: []slogscope.QuietHours{q},
+			},
+		})
+	}
+
+	t.Run("test active quiet hours raise all log levels", func(t *testing.T) {
+		h := newHandler(window(-time.Hour, time.Hour))
+		d := h.ExplainDecision("github.com/foo/baz", slog.LevelInfo)
+		assert.False(t, d.Enabled)
+		assert.Equal(t, slogscope.LogLevelWarn, d.LogLevel)
+		assert.Equal(t, "HandlerOptions.Config (quiet hours)", d.Source)
+		assert.False(t, h.ExplainDecision("github.com/foo/bar", slog.LevelInfo).Enabled)
+		assert.True(t, h.ExplainDecision("github.com/foo/bar", slog.LevelWarn).Enabled)
+		assert.Equal(t, slogscope.LogLevelWarn, h.EffectiveConfig().LogLevel)
+	})
+
+	t.Run("test active quiet hours raise rules without log level", func(t *testing.T) {
+		h := slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel:   slogscope.LogLevelDebug,
+				Packages:   []slogscope.Package{{Name: "github.com/foo/bar", Functions: []slogscope.Function{{Name: "Run"}}}},
+				QuietHours: []slogscope.QuietHours{window(-time.Hour, time.Hour)},
+			},
+		})
+		d := h.ExplainDecision("github.com/foo/bar", slog.LevelInfo)
+		assert.False(t, d.Enabled)
+		assert.Equal(t, slogscope.LogLevelWarn, d.LogLevel)
+		assert.Equal(t, []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn, Functions: []slogscope.Function{{Name: "Run", LogLevel: slogscope.LogLevelWarn}}},
+		}, h.EffectiveConfig().Packages)
+	})
+
+	t.Run("test inactive quiet hours", func(t *testing.T) {
+		h := newHandler(window(time.Hour, 2*time.Hour))
+		assert.True(t, h.ExplainDecision("github.com/foo/baz", slog.LevelDebug).Enabled)
+		assert.True(t, h.ExplainDecision("github.com/foo/bar", slog.LevelInfo).Enabled)
+	})
+
+	t.Run("test invalid quiet hours", func(t *testing.T) {
+		cfg := slogscope.Config{QuietHours: []slogscope.QuietHours{{From: "22:00", To: "25:00"}, {From: "01:00", To: "01:00"}}}
+		assert.EqualError(t, cfg.Validate(), `invalid config (2 problems): quiet_hours[0]: invalid end "25:00": expected HH:MM; `+
+			`quiet_hours[1]: start and end "01:00" are equal`)
+	})
+}
+
+// fakeClock is a wall clock, which can be set to simulate clock changes.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func TestHandler_QuietHoursClockChanges(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database is not available: %s", err.Error())
+	}
+	newHandler := func(clock *fakeClock, q slogscope.QuietHours) *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{LogLevel: slogscope.LogLevelDebug, QuietHours: []slogscope.QuietHours{q}},
+			Clock:  clock.Now,
+		})
+	}
+	quiet := func(h *slogscope.Handler) func() bool {
+		return func() bool { return !h.ExplainDecision("github.com/foo/bar", slog.LevelInfo).Enabled }
+	}
+
+	t.Run("test forward jump into quiet hours", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)}
+		h := newHandler(clock, slogscope.QuietHours{From: "12:00", To: "13:00", Location: "UTC"})
+		assert.False(t, quiet(h)())
+
+		clock.Set(time.Date(2026, 6, 1, 12, 30, 0, 0, time.UTC))
+		assert.Eventually(t, quiet(h), 3*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("test backward jump out of quiet hours", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2026, 6, 1, 12, 30, 0, 0, time.UTC)}
+		h := newHandler(clock, slogscope.QuietHours{From: "12:00", To: "13:00", Location: "UTC"})
+		assert.True(t, quiet(h)())
+
+		clock.Set(time.Date(2026, 6, 1, 11, 0, 0, 0, time.UTC))
+		assert.Eventually(t, func() bool { return !quiet(h)() }, 3*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("test end of quiet hours on the day DST ends", func(t *testing.T) {
+		// On 2026-10-25, 06:00 in Berlin is 7 hours after midnight.
+		clock := &fakeClock{now: time.Date(2026, 10, 25, 5, 59, 59, 0, berlin)}
+		h := newHandler(clock, slogscope.QuietHours{From: "22:00", To: "06:00", Location: "Europe/Berlin"})
+		assert.True(t, quiet(h)())
+
+		clock.Set(time.Date(2026, 10, 25, 6, 0, 0, 0, berlin))
+		assert.Eventually(t, func() bool { return !quiet(h)() }, 3*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("test start of quiet hours on the day DST starts", func(t *testing.T) {
+		// On 2026-03-29, 04:00 in Berlin is 3 hours after midnight.
+		clock := &fakeClock{now: time.Date(2026, 3, 29, 3, 59, 59, 0, berlin)}
+		h := newHandler(clock, slogscope.QuietHours{From: "04:00", To: "05:00", Location: "Europe/Berlin"})
+		assert.False(t, quiet(h)())
+
+		clock.Set(time.Date(2026, 3, 29, 4, 0, 0, 0, berlin))
+		assert.Eventually(t, quiet(h), 3*time.Second, 50*time.Millisecond)
+	})
+}
@@ -0,0 +1,95 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SinkFactory creates the slog.Handler of a sink from the options of its Sink config (see RegisterSinkType).
+type SinkFactory func(options map[string]string) (slog.Handler, error)
+
+// ProviderFactory creates a ConfigProvider from a URL (see RegisterProviderType).
+type ProviderFactory func(u *url.URL) (ConfigProvider, error)
+
+// registry contains the registered sink types and provider schemes. Optional integrations, e.g. in separate modules,
+// register themselves from an init function, so they are available as soon as their package is imported.
+var registry = struct {
+	mu        sync.RWMutex
+	sinks     map[string]SinkFactory
+	providers map[string]ProviderFactory
+}{
+	sinks: map[string]SinkFactory{},
+	providers: map[string]ProviderFactory{
+		"file":  newFileProviderFromURL,
+		"http":  newHTTPProviderFromURL,
+		"https": newHTTPProviderFromURL,
+	},
+}
+
+// RegisterSinkType registers a sink type, which can be referenced by Sink.Type within the config.
+// A registered type replaces an existing type of the same name.
+func RegisterSinkType(name string, factory SinkFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.sinks[strings.ToLower(name)] = factory
+}
+
+// RegisterProviderType registers a ConfigProvider for a URL scheme, which can be used with NewProviderFromURL.
+// A registered scheme replaces an existing scheme of the same name.
+func RegisterProviderType(scheme string, factory ProviderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.providers[strings.ToLower(scheme)] = factory
+}
+
+// NewProviderFromURL returns a ConfigProvider for the URL depending on its scheme, e.g. "file:///etc/slogscope.yml"
+// or "etcd://localhost:2379/config/slogscope.yml" after importing github.com/apperia-de/slogscope/provider/etcd.
+// It returns an error listing the registered schemes if no provider is registered for the scheme.
+func NewProviderFromURL(rawURL string) (ConfigProvider, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	registry.mu.RLock()
+	factory, ok := registry.providers[strings.ToLower(u.Scheme)]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no config provider registered for scheme %q (registered: %s): import the package providing it", u.Scheme, strings.Join(registeredProviderTypes(), ", "))
+	}
+	return factory(u)
+}
+
+// sinkFactory returns the registered factory of the sink type.
+func sinkFactory(name string) (SinkFactory, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	factory, ok := registry.sinks[strings.ToLower(name)]
+	return factory, ok
+}
+
+// registeredSinkTypes returns the names of all registered sink types in sorted order.
+func registeredSinkTypes() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return slices.Sorted(maps.Keys(registry.sinks))
+}
+
+// registeredProviderTypes returns the schemes of all registered providers in sorted order.
+func registeredProviderTypes() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return slices.Sorted(maps.Keys(registry.providers))
+}
+
+func newFileProviderFromURL(u *url.URL) (ConfigProvider, error) {
+	return NewFileProvider(u.Host + u.Path), nil
+}
+
+func newHTTPProviderFromURL(u *url.URL) (ConfigProvider, error) {
+	return NewHTTPProvider(u.String(), 0), nil
+}
@@ -0,0 +1,118 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/url"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHandler is a slog.Handler remembering the messages of all handled records.
+type recordingHandler struct {
+	messages *[]string
+	closed   *int // Number of calls of Close, if not nil.
+}
+
+func (h recordingHandler) Close() error {
+	if h.closed != nil {
+		*h.closed++
+	}
+	return nil
+}
+
+func (h recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h recordingHandler) Handle(_ context.Context, rec slog.Record) error {
+	*h.messages = append(*h.messages, rec.Message)
+	return nil
+}
+func (h recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestRegisterSinkType(t *testing.T) {
+	var messages []string
+	var options map[string]string
+	var created, closed int
+	slogscope.RegisterSinkType("recording", func(opts map[string]string) (slog.Handler, error) {
+		options = opts
+		created++
+		return recordingHandler{messages: &messages, closed: &closed}, nil
+	})
+
+	t.Run("test registered sink type is created from the config", func(t *testing.T) {
+		l := slog.New(slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks:    []slogscope.Sink{{Name: "audit", Type: "recording", Options: map[string]string{"target": "audit-log"}}},
+			},
+		}))
+		l.Warn("warn message")
+		l.Error("error message")
+		assert.Equal(t, []string{"error message"}, messages)
+		assert.Equal(t, map[string]string{"target": "audit-log"}, options)
+	})
+
+	t.Run("test created sinks are reused and closed", func(t *testing.T) {
+		created, closed = 0, 0
+		cfg := slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Sinks:    []slogscope.Sink{{Name: "audit", Type: "recording", Options: map[string]string{"target": "audit-log"}}},
+		}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &cfg})
+		h.SetGlobalLevel(slog.LevelDebug)
+		assert.Equal(t, 1, created)
+		assert.Equal(t, 0, closed)
+
+		cfg.Sinks = []slogscope.Sink{{Name: "audit", Type: "recording", Options: map[string]string{"target": "other-log"}}}
+		h.UseConfig(cfg)
+		assert.Equal(t, 2, created)
+		assert.Equal(t, 1, closed)
+	})
+
+	t.Run("test unavailable sinks are reported", func(t *testing.T) {
+		var buf bytes.Buffer
+		cfg := slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Sinks:    []slogscope.Sink{{Name: "logs", Type: "loki"}, {Name: "pager"}},
+		}
+		h := slogscope.NewHandler(slog.NewTextHandler(&buf, nil), &slogscope.HandlerOptions{Config: &cfg})
+
+		assert.Equal(t, []string{
+			`logs: sink type "loki" is not registered (registered: recording)`,
+			"pager: no handler given via HandlerOptions.Sinks and no sink type configured",
+		}, h.Stats().UnavailableSinks)
+		assert.Contains(t, buf.String(), "slogscope: unavailable sinks are disabled")
+		assert.ErrorContains(t, cfg.Validate(), `sinks[0].type: unknown sink type "loki"`)
+	})
+}
+
+func TestNewProviderFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		provider any
+	}{
+		{"file:///etc/slogscope.yml", &slogscope.FileProvider{}},
+		{"https://config.example.com/slogscope.yml", &slogscope.HTTPProvider{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			p, err := slogscope.NewProviderFromURL(tt.url)
+			assert.NoError(t, err)
+			assert.IsType(t, tt.provider, p)
+		})
+	}
+
+	t.Run("test unknown scheme", func(t *testing.T) {
+		_, err := slogscope.NewProviderFromURL("kafka://localhost:9092/slogscope")
+		assert.ErrorContains(t, err, `no config provider registered for scheme "kafka"`)
+
+		slogscope.RegisterProviderType("kafka", func(u *url.URL) (slogscope.ConfigProvider, error) {
+			return slogscope.NewFileProvider(u.Path), nil
+		})
+		_, err = slogscope.NewProviderFromURL("kafka://localhost:9092/slogscope")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package slogscope
+
+import "fmt"
+
+// pendingReload is a config change of the watched ConfigSource received while reloads are paused (see Handler.PauseReloads).
+type pendingReload struct {
+	cfg    Config
+	prov   provenance
+	doneCh chan struct{} // Closed as soon as the watcher was stopped, e.g. by UseConfig.
+}
+
+// PauseReloads suspends applying config changes of the watched ConfigSource, e.g. during startup migrations or
+// leader election. Changes received in the meantime are applied by ResumeReloads. Explicit changes, e.g. via
+// UseConfig, are applied regardless.
+func (h *Handler) PauseReloads() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reloadsPaused = true
+	h.logger.Debug("paused config reloads")
+}
+
+// ResumeReloads resumes applying config changes of the watched ConfigSource (see PauseReloads) and applies the
+// latest change received while reloads were paused, if any.
+func (h *Handler) ResumeReloads() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reloadsPaused = false
+	pending := h.pendingReload
+	h.pendingReload = nil
+	if pending == nil {
+		h.logger.Debug("resumed config reloads")
+		return
+	}
+	select {
+	case <-pending.doneCh:
+		// The config was replaced in the meantime, e.g. by UseConfig.
+		h.logger.Debug("resumed config reloads, pending config change was superseded")
+	default:
+		h.opts.Config = &pending.cfg
+		h.prov = pending.prov
+		h.configure()
+		h.logger.Debug(fmt.Sprintf("resumed config reloads, applied pending config: %#v", pending.cfg))
+	}
+}
@@ -0,0 +1,104 @@
+package slogscope_test
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// chanWatcher is a ConfigWatcher sending the configs of its channel.
+type chanWatcher struct {
+	configs chan slogscope.Config
+}
+
+func (w *chanWatcher) Load() (slogscope.Config, error) {
+	return slogscope.Config{LogLevel: slogscope.LogLevelInfo}, nil
+}
+
+func (w *chanWatcher) Watch(ch chan<- slogscope.Config, done <-chan struct{}) error {
+	go func() {
+		for {
+			select {
+			case cfg := <-w.configs:
+				select {
+				case ch <- cfg:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func TestHandler_PauseReloads(t *testing.T) {
+	w := &chanWatcher{configs: make(chan slogscope.Config)}
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigProvider: w, EnableFileWatcher: true})
+	logLevel := func() string { return h.GetConfig().LogLevel }
+	assert.Equal(t, slogscope.LogLevelInfo, logLevel())
+
+	t.Run("test changes are deferred while paused", func(t *testing.T) {
+		h.PauseReloads()
+		w.configs <- slogscope.Config{LogLevel: slogscope.LogLevelWarn}
+		w.configs <- slogscope.Config{LogLevel: slogscope.LogLevelDebug}
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelInfo, logLevel())
+	})
+
+	t.Run("test latest pending change is applied on resume", func(t *testing.T) {
+		h.ResumeReloads()
+		assert.Equal(t, slogscope.LogLevelDebug, logLevel())
+	})
+
+	t.Run("test changes are applied after resume", func(t *testing.T) {
+		w.configs <- slogscope.Config{LogLevel: slogscope.LogLevelError}
+		assert.Eventually(t, func() bool { return logLevel() == slogscope.LogLevelError }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test pending change superseded by an explicit config", func(t *testing.T) {
+		h.PauseReloads()
+		w.configs <- slogscope.Config{LogLevel: slogscope.LogLevelDebug}
+		time.Sleep(20 * time.Millisecond)
+		h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelWarn})
+		h.ResumeReloads()
+		assert.Equal(t, slogscope.LogLevelWarn, logLevel())
+	})
+}
+
+// TestHandler_ConcurrentReload is meant to be run with the race detector (see make test-race).
+func TestHandler_ConcurrentReload(t *testing.T) {
+	w := &chanWatcher{configs: make(chan slogscope.Config)}
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigProvider: w})
+	l := slog.New(h)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			assert.NoError(t, h.Reload())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			cancel := h.UsePackageLevelTemporarily("github.com/foo/bar", slogscope.LogLevelWarn, time.Minute)
+			h.SetGlobalLevel(slog.LevelInfo)
+			cancel()
+		}
+	}()
+	for range 200 {
+		_ = h.GetConfig()
+		_ = h.EffectiveConfig()
+		l.Info("info message")
+	}
+	wg.Wait()
+	h.UseConfig(slogscope.Config{LogLevel: slogscope.LogLevelError})
+	assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+}
@@ -0,0 +1,109 @@
+package slogscope
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// renamer moves the attributes of records to new keys (see Config.Renames).
+type renamer struct {
+	renames []Rename
+}
+
+// newRenamer returns the renamer for the renames or nil, if nothing is to be renamed. Invalid renames are skipped.
+func newRenamer(renames []Rename) *renamer {
+	var valid []Rename
+	for _, r := range renames {
+		if validAttrPath(r.From) && validAttrPath(r.To) && r.From != r.To {
+			valid = append(valid, r)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return &renamer{renames: valid}
+}
+
+// validAttrPath reports whether the path consists of non-empty keys separated by dots.
+func validAttrPath(path string) bool {
+	return path != "" && !slices.Contains(strings.Split(path, "."), "")
+}
+
+// rename returns the record with all renames applied in order.
+func (rn *renamer) rename(rec slog.Record) slog.Record {
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	renamed := false
+	for _, r := range rn.renames {
+		var a slog.Attr
+		var ok bool
+		if attrs, a, ok = removeAttr(attrs, r.From); ok {
+			attrs = setAttr(attrs, r.To, a.Value)
+			renamed = true
+		}
+	}
+	if !renamed {
+		return rec
+	}
+	out := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	out.AddAttrs(attrs...)
+	return out
+}
+
+// removeAttr removes the attribute at the path from attrs and returns it. Keys containing dots, e.g.
+// "slogscope.override", take precedence over groups. Groups becoming empty are removed as well.
+func removeAttr(attrs []slog.Attr, path string) ([]slog.Attr, slog.Attr, bool) {
+	for i, a := range attrs {
+		if a.Key == path {
+			return slices.Delete(slices.Clone(attrs), i, i+1), a, true
+		}
+		rest, ok := strings.CutPrefix(path, a.Key+".")
+		if !ok || a.Value.Resolve().Kind() != slog.KindGroup {
+			continue
+		}
+		group, removed, ok := removeAttr(a.Value.Resolve().Group(), rest)
+		if !ok {
+			continue
+		}
+		attrs = slices.Clone(attrs)
+		if len(group) == 0 {
+			return slices.Delete(attrs, i, i+1), removed, true
+		}
+		attrs[i] = slog.Attr{Key: a.Key, Value: slog.GroupValue(group...)}
+		return attrs, removed, true
+	}
+	return attrs, slog.Attr{}, false
+}
+
+// setAttr sets the attribute at the path to v, replacing an existing attribute and creating missing groups. The
+// given attributes are not modified.
+func setAttr(attrs []slog.Attr, path string, v slog.Value) []slog.Attr {
+	for i, a := range attrs {
+		if a.Key == path {
+			attrs = slices.Clone(attrs)
+			attrs[i] = slog.Attr{Key: path, Value: v}
+			return attrs
+		}
+		if rest, ok := strings.CutPrefix(path, a.Key+"."); ok && a.Value.Resolve().Kind() == slog.KindGroup {
+			attrs = slices.Clone(attrs)
+			attrs[i] = slog.Attr{Key: a.Key, Value: slog.GroupValue(setAttr(a.Value.Resolve().Group(), rest, v)...)}
+			return attrs
+		}
+	}
+	key, rest, nested := strings.Cut(path, ".")
+	a := slog.Attr{Key: key, Value: v}
+	if nested {
+		a.Value = slog.GroupValue(setAttr(nil, rest, v)...)
+	}
+	// An attribute, which isn't a group, is replaced by the group.
+	if i := slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == key }); i >= 0 {
+		attrs = slices.Clone(attrs)
+		attrs[i] = a
+		return attrs
+	}
+	return append(slices.Clip(attrs), a)
+}
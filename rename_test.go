@@ -0,0 +1,63 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Renames(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewJSONHandler(&out, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Renames: []slogscope.Rename{
+			{From: "user_id", To: "user.id"},
+			{From: "http.status", To: "status_code"},
+			{From: "err", To: "error.message"},
+			{From: "error.message", To: "error.msg"},
+			{From: "trace.id", To: "trace_id"},
+		},
+	}})
+	l := slog.New(h)
+
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{"rename into a new group", []any{"user_id", 42}, `{"user":{"id":42}}`},
+		{"rename into an existing group", []any{"user_id", 42, slog.Group("user", "name", "bob")}, `{"user":{"name":"bob","id":42}}`},
+		{"rename out of a group", []any{slog.Group("http", "status", 200, "method", "GET")}, `{"http":{"method":"GET"},"status_code":200}`},
+		{"empty groups are removed", []any{slog.Group("http", "status", 200)}, `{"status_code":200}`},
+		{"renames are applied in order", []any{"err", "timeout"}, `{"error":{"msg":"timeout"}}`},
+		{"existing attributes are replaced", []any{"user_id", 42, "user", "bob"}, `{"user":{"id":42}}`},
+		{"keys containing dots", []any{"trace.id", "abc"}, `{"trace_id":"abc"}`},
+		{"other attributes are unchanged", []any{"user", "bob"}, `{"user":"bob"}`},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.name, func(t *testing.T) {
+			out.Reset()
+			l.Info("request", tt.args...)
+			assert.Equal(t, tt.want, strings.TrimSpace(out.String()))
+		})
+	}
+
+	t.Run("test invalid renames", func(t *testing.T) {
+		cfg := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Renames: []slogscope.Rename{{From: "", To: "a..b"}, {From: "x", To: "x"}}}
+		err := cfg.Validate()
+		assert.ErrorContains(t, err, `renames[0].from: invalid attribute path ""`)
+		assert.ErrorContains(t, err, `renames[0].to: invalid attribute path "a..b"`)
+		assert.ErrorContains(t, err, `renames[1]: from and to must differ, got "x"`)
+	})
+}
@@ -0,0 +1,66 @@
+package slogscope
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+)
+
+// inRollout reports whether this instance is selected by the rollout. The selection is stable for the same
+// instance ID and seed, so every instance of a fleet decides independently, but consistently, without coordination.
+func (ss *slogscope) inRollout(r *Rollout) bool {
+	if r.Percent <= 0 {
+		return false
+	}
+	if r.Percent >= 100 {
+		return true
+	}
+	f := fnv.New32a()
+	_, _ = f.Write([]byte(r.Seed + "/" + ss.opts.InstanceID))
+	return f.Sum32()%100 < uint32(r.Percent)
+}
+
+// applyRollout returns the config with the log levels of the rollout applied, if this instance is selected by it.
+func (ss *slogscope) applyRollout(cfg Config) Config {
+	if cfg.Rollout == nil || !ss.inRollout(cfg.Rollout) {
+		return cfg
+	}
+	ss.logger.Debug(fmt.Sprintf("instance %q is selected by rollout (percent=%d)", ss.opts.InstanceID, cfg.Rollout.Percent))
+	if cfg.Rollout.LogLevel != "" {
+		cfg.LogLevel = cfg.Rollout.LogLevel
+	}
+	cfg.Packages = mergePackages(cfg.Packages, cfg.Rollout.Packages)
+	return cfg
+}
+
+// mergePackages returns base with all packages of overlay applied. Packages of overlay replace packages of base
+// with the same name, all other packages of overlay are appended.
+func mergePackages(base, overlay []Package) []Package {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make([]Package, 0, len(base)+len(overlay))
+	idx := map[string]int{}
+	for _, p := range base {
+		idx[p.Name] = len(merged)
+		merged = append(merged, p)
+	}
+	for _, p := range overlay {
+		if i, ok := idx[p.Name]; ok {
+			merged[i] = p
+			continue
+		}
+		idx[p.Name] = len(merged)
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// defaultInstanceID returns the hostname or, if not available, the process ID.
+func defaultInstanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return strconv.Itoa(os.Getpid())
+}
@@ -0,0 +1,58 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// debugEnabledForInstance reports whether a DEBUG message is logged by an instance with the given ID.
+func debugEnabledForInstance(instanceID string, rollout *slogscope.Rollout) bool {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+		Config:     &slogscope.Config{LogLevel: slogscope.LogLevelInfo, Rollout: rollout},
+		InstanceID: instanceID,
+	})
+	slog.New(h).Debug("debug message")
+	return out.Len() > 0
+}
+
+func TestHandler_Rollout(t *testing.T) {
+	t.Run("test rollout to all instances", func(t *testing.T) {
+		assert.True(t, debugEnabledForInstance("instance-1", &slogscope.Rollout{Percent: 100, LogLevel: slogscope.LogLevelDebug}))
+	})
+
+	t.Run("test rollout to no instances", func(t *testing.T) {
+		assert.False(t, debugEnabledForInstance("instance-1", &slogscope.Rollout{Percent: 0, LogLevel: slogscope.LogLevelDebug}))
+	})
+
+	t.Run("test rollout to a percentage of all instances", func(t *testing.T) {
+		rollout := &slogscope.Rollout{Percent: 10, Seed: "incident-42", LogLevel: slogscope.LogLevelDebug}
+		selected := 0
+		for i := 0; i < 1000; i++ {
+			if debugEnabledForInstance(fmt.Sprintf("instance-%d", i), rollout) {
+				selected++
+			}
+		}
+		assert.InDelta(t, 100, selected, 50)
+	})
+
+	t.Run("test rollout selection is stable", func(t *testing.T) {
+		rollout := &slogscope.Rollout{Percent: 50, LogLevel: slogscope.LogLevelDebug}
+		for i := 0; i < 10; i++ {
+			id := fmt.Sprintf("instance-%d", i)
+			assert.Equal(t, debugEnabledForInstance(id, rollout), debugEnabledForInstance(id, rollout))
+		}
+	})
+
+	t.Run("test rollout package overrides", func(t *testing.T) {
+		assert.True(t, debugEnabledForInstance("instance-1", &slogscope.Rollout{
+			Percent:  100,
+			Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug}},
+		}))
+	})
+}
@@ -0,0 +1,44 @@
+package slogscope
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// runtimeSnapshotInterval is the minimum interval between two runtime snapshots (see Package.RuntimeMetrics).
+// Records within the interval share the same snapshot, so bursts of errors don't stop the world repeatedly.
+const runtimeSnapshotInterval = time.Second
+
+// runtimeSnapshot is the throttled snapshot of the Go runtime attached to ERROR records.
+type runtimeSnapshot struct {
+	mu    sync.Mutex
+	taken time.Time
+	attrs []slog.Attr
+}
+
+// get returns the attributes of the runtime group, which are taken at most once per runtimeSnapshotInterval.
+func (s *runtimeSnapshot) get() []slog.Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attrs != nil && time.Since(s.taken) < runtimeSnapshotInterval {
+		return s.attrs
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	var lastPause time.Duration
+	if ms.NumGC > 0 {
+		lastPause = time.Duration(ms.PauseNs[(ms.NumGC+255)%256])
+	}
+	s.taken = time.Now()
+	s.attrs = []slog.Attr{
+		slog.Int("goroutines", runtime.NumGoroutine()),
+		slog.Uint64("heap_alloc", ms.HeapAlloc),
+		slog.Uint64("heap_sys", ms.HeapSys),
+		slog.Uint64("gc_count", uint64(ms.NumGC)),
+		slog.Duration("gc_pause", lastPause),
+	}
+	return s.attrs
+}
@@ -0,0 +1,72 @@
+package slogscope
+
+import (
+	"sync"
+	"time"
+)
+
+// scheduleCheckInterval is the maximum interval between two wall clock checks of a scheduled rule change. Timers run
+// on the monotonic clock, so without these checks, changes of the wall clock, e.g. NTP jumps, would delay or advance
+// the change by the size of the jump.
+const scheduleCheckInterval = time.Second
+
+// wallTimer calls a function as soon as the wall clock reaches a point in time (see afterWallClock).
+type wallTimer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+// afterWallClock calls fn as soon as the wall clock given by now reaches at or has been set back behind the time of
+// the previous check, since scheduled rules must be reevaluated in both cases. The wall clock is checked at least
+// every scheduleCheckInterval.
+func afterWallClock(now func() time.Time, at time.Time, fn func()) *wallTimer {
+	wt := &wallTimer{}
+	wt.arm(now, now(), at, fn)
+	return wt
+}
+
+func (wt *wallTimer) arm(now func() time.Time, checked, at time.Time, fn func()) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if wt.stopped {
+		return
+	}
+	// Round(0) strips the monotonic clock readings, so the wall clock is compared.
+	d := min(max(at.Round(0).Sub(checked.Round(0)), 0), scheduleCheckInterval)
+	wt.timer = time.AfterFunc(d, func() {
+		t := now().Round(0)
+		if !t.Before(at.Round(0)) || t.Before(checked.Round(0)) {
+			fn()
+			return
+		}
+		wt.arm(now, t, at, fn)
+	})
+}
+
+// Stop prevents the function from being called.
+func (wt *wallTimer) Stop() {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.stopped = true
+	if wt.timer != nil {
+		wt.timer.Stop()
+	}
+}
+
+// now returns the current wall clock time of HandlerOptions.Clock or time.Now.
+func (ss *slogscope) now() time.Time {
+	if ss.opts.Clock != nil {
+		return ss.opts.Clock()
+	}
+	return time.Now()
+}
+
+// reconfigureAt reapplies the config as soon as the wall clock reaches at (see afterWallClock).
+func (ss *slogscope) reconfigureAt(at time.Time) *wallTimer {
+	return afterWallClock(ss.now, at, func() {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		ss.configure()
+	})
+}
@@ -0,0 +1,47 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+)
+
+// RegisterScope registers the package name, which is used for matching the package rules of all records logged from
+// the package of the given function. This is mainly meant for plugins loaded via the plugin package, since the main
+// package of a plugin gets an unpredictable name like "plugin/unnamed-4f6d8b9..." at runtime. A plugin can register
+// its scope from within its initialization function, e.g.:
+//
+//	func Init(h *slogscope.Handler) error {
+//		return h.RegisterScope("github.com/foo/myplugin", Init)
+//	}
+func (h *Handler) RegisterScope(name string, fn any) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("cannot register scope %q: expected a function, got %T", name, fn)
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return fmt.Errorf("cannot register scope %q: unknown function", name)
+	}
+	pkgName, _ := splitFuncName(f.Name())
+	if isUnresolvedPackage(pkgName) {
+		return fmt.Errorf("cannot register scope %q: package of function %s can't be resolved", name, f.Name())
+	}
+	h.scopes.Store(pkgName, name)
+	h.decisions.invalidate()
+	h.logger.Debug(fmt.Sprintf("registered scope=%q for package=%q", name, pkgName))
+	return nil
+}
+
+// Scope returns a logger, whose records are matched against the package rules by the given scope name instead of the
+// package of the caller, e.g. "github.com/foo/mylib/cache". In contrast to caller detection, the scope name is stable
+// across refactorings and applies even if the log calls are funneled through helper packages:
+//
+//	logger := h.Scope("github.com/foo/mylib/cache")
+//	logger.Debug("cache miss", "key", key)
+//
+// File and function rules do not apply to scoped loggers.
+func (h *Handler) Scope(name string) *slog.Logger {
+	return slog.New(&Handler{slogscope: h.slogscope, next: h.next, scopeName: name, groups: h.groups})
+}
@@ -0,0 +1,111 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// pluginSource is the main package of a plugin logging via the logger of the host.
+const pluginSource = `package main
+
+import "log/slog"
+
+func Run(l *slog.Logger, msg string) {
+	l.Debug(msg)
+}
+`
+
+// buildPlugin builds pluginSource as plugin and returns its Run function. Since the plugin is built from a file list,
+// its main package gets a name like "plugin/unnamed-4f6d8b9..." at runtime.
+func buildPlugin(t *testing.T) func(*slog.Logger, string) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(pluginSource), 0644))
+
+	cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", "myplugin.so", "main.go")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("plugins are not supported: %s", strings.TrimSpace(string(out)))
+	}
+	p, err := plugin.Open(filepath.Join(dir, "myplugin.so"))
+	if err != nil {
+		t.Skipf("plugins are not supported: %s", err.Error())
+	}
+	run, err := p.Lookup("Run")
+	assert.NoError(t, err)
+	return run.(func(*slog.Logger, string))
+}
+
+func TestHandler_RegisterScope(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{Name: "example.com/myplugin", LogLevel: slogscope.LogLevelDebug}},
+	}})
+	l := slog.New(h)
+
+	t.Run("test invalid functions are rejected", func(t *testing.T) {
+		assert.Error(t, h.RegisterScope("example.com/myplugin", "Run"))
+		assert.Error(t, h.RegisterScope("example.com/myplugin", (func())(nil)))
+	})
+
+	t.Run("test plugin uses its registered scope", func(t *testing.T) {
+		run := buildPlugin(t)
+
+		run(l, "unregistered message")
+		assert.NotContains(t, out.String(), "unregistered message")
+
+		assert.NoError(t, h.RegisterScope("example.com/myplugin", run))
+		run(l, "registered message")
+		assert.Contains(t, out.String(), "registered message")
+
+		var registered bool
+		for _, p := range h.GetPackages() {
+			registered = registered || p.Name == "example.com/myplugin" && p.Observed == 1
+		}
+		assert.True(t, registered)
+	})
+}
+
+func TestHandler_Scope(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{
+			{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelError},
+			{Name: "example.com/mylib/**", LogLevel: slogscope.LogLevelDebug},
+		},
+	}})
+
+	t.Run("test scoped logger matches its scope name", func(t *testing.T) {
+		out.Reset()
+		l := h.Scope("example.com/mylib/cache")
+		l.Debug("scoped message")
+		assert.Contains(t, out.String(), "scoped message")
+
+		l.With("key", "value").WithGroup("g").Debug("derived message", "n", 1)
+		assert.Contains(t, out.String(), "key=value g.n=1")
+	})
+
+	t.Run("test caller detection without scope", func(t *testing.T) {
+		out.Reset()
+		slog.New(h).Warn("unscoped message")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("test scope is observed", func(t *testing.T) {
+		var observed bool
+		for _, p := range h.GetPackages() {
+			observed = observed || p.Name == "example.com/mylib/cache" && p.Observed > 0
+		}
+		assert.True(t, observed)
+	})
+}
@@ -0,0 +1,43 @@
+package slogscope
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigSearchPath returns the config files searched in this order if no HandlerOptions.ConfigFile is given:
+//
+//  1. ./slogscope.yml
+//  2. $XDG_CONFIG_HOME/slogscope/slogscope.yml (default: ~/.config/slogscope/slogscope.yml, see os.UserConfigDir)
+//  3. /etc/<binary>/slogscope.yml, where <binary> is the name of the executable
+func ConfigSearchPath() []string {
+	path := []string{defaultConfigFile}
+	if dir, err := os.UserConfigDir(); err == nil {
+		path = append(path, filepath.Join(dir, "slogscope", defaultConfigFile))
+	}
+	if len(os.Args) > 0 {
+		binary := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+		path = append(path, filepath.Join("/etc", binary, defaultConfigFile))
+	}
+	return path
+}
+
+// searchConfigFile returns the first existing config file of the ConfigSearchPath or, if none exists,
+// defaultConfigFile, which is created with the default config.
+func searchConfigFile() string {
+	for _, filename := range ConfigSearchPath() {
+		if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+			return filename
+		}
+	}
+	return defaultConfigFile
+}
+
+// ConfigFile returns the config file used by the Handler, which is HandlerOptions.ConfigFile or, if not given,
+// the file chosen from the ConfigSearchPath.
+func (h *Handler) ConfigFile() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.opts.ConfigFile
+}
@@ -0,0 +1,46 @@
+package slogscope_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ConfigSearchPath(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	defer func() { _ = os.Chdir(wd) }()
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	xdgFile := filepath.Join(xdg, "slogscope", "slogscope.yml")
+	assert.Equal(t, xdgFile, slogscope.ConfigSearchPath()[1])
+
+	t.Run("test default config file without any config", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{DisableConfigFileCreation: true})
+		assert.Equal(t, "slogscope.yml", h.ConfigFile())
+	})
+
+	t.Run("test config file of the XDG config directory", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.NoError(t, os.MkdirAll(filepath.Dir(xdgFile), 0755))
+		assert.NoError(t, os.WriteFile(xdgFile, []byte("log_level: WARN\n"), 0644))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), nil)
+		assert.Equal(t, xdgFile, h.ConfigFile())
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test config file of the working directory takes precedence", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.NoError(t, os.WriteFile("slogscope.yml", []byte("log_level: ERROR\n"), 0644))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), nil)
+		assert.Equal(t, "slogscope.yml", h.ConfigFile())
+		assert.Equal(t, slogscope.LogLevelError, h.GetConfig().LogLevel)
+	})
+}
@@ -0,0 +1,112 @@
+package slogscope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SelfTestScope is the scope name of the synthetic records emitted by Handler.SelfTest. Package rules and sinks can
+// match it like any other package, e.g. to route the records of smoke tests.
+const SelfTestScope = "slogscope/selftest"
+
+// selfTestAttrKey is the key of the attribute containing the ID of the self-test run (see Handler.SelfTest).
+const selfTestAttrKey = "slogscope.selftest"
+
+// HealthChecker is implemented by sink handlers, which can check the availability of their backend, e.g. by pinging
+// the endpoint of a webhook (see Handler.SelfTest).
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// SelfTestReport is the result of Handler.SelfTest.
+type SelfTestReport struct {
+	ID       string           `json:"id"` // Value of the attribute slogscope.selftest of all synthetic records.
+	Passed   bool             `json:"passed"`
+	Duration time.Duration    `json:"duration"`
+	Records  []SelfTestRecord `json:"records"`
+	Sinks    []SinkHealth     `json:"sinks,omitempty"`
+}
+
+// SelfTestRecord describes how the synthetic record of a log level traversed the Handler.
+type SelfTestRecord struct {
+	Level  string   `json:"level"`
+	Logged bool     `json:"logged"`          // Whether the record was enabled by the config and passed to the wrapped handler.
+	Sinks  []string `json:"sinks,omitempty"` // Names of the sinks the record was forwarded to.
+	Error  string   `json:"error,omitempty"` // Error returned by the wrapped handler or the delivery.
+}
+
+// SinkHealth is the health of a sink of the config (see HealthChecker).
+type SinkHealth struct {
+	Name    string `json:"name"`
+	Checked bool   `json:"checked"`         // Whether the sink handler implements HealthChecker.
+	Error   string `json:"error,omitempty"` // Error of the health check or the reason why the sink is unavailable.
+}
+
+// SelfTest verifies the pipeline end-to-end, e.g. in deployment smoke tests: it emits one synthetic record per log
+// level (DEBUG to ERROR) for SelfTestScope, reports which of them were logged and forwarded to which sinks and checks
+// the health of all sinks of the config. The test passes, if at least one record was logged or forwarded, no error
+// occurred and all sinks are healthy. The synthetic records carry the attribute slogscope.selftest with the ID of the
+// report, so they can be looked up in the log backend.
+//
+// Records queued by the asynchronous delivery mode are not awaited, failed deliveries end up in the dead letter file
+// as usual.
+func (h *Handler) SelfTest(ctx context.Context) SelfTestReport {
+	start := time.Now()
+	report := SelfTestReport{ID: fmt.Sprintf("%x", start.UnixNano())}
+	scoped := &Handler{slogscope: h.slogscope, next: h.next, scopeName: SelfTestScope}
+
+	traversed := false
+	for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		r := SelfTestRecord{Level: lvl.String()}
+		if err := ctx.Err(); err != nil {
+			r.Error = err.Error()
+			report.Records = append(report.Records, r)
+			continue
+		}
+		r.Logged, _ = scoped.decide(SelfTestScope, "", "", lvl)
+		r.Sinks = h.taps.sinks(SelfTestScope, lvl)
+		if scoped.Enabled(ctx, lvl) {
+			rec := slog.NewRecord(time.Now(), lvl, "slogscope self-test", 0)
+			rec.AddAttrs(slog.String(selfTestAttrKey, report.ID))
+			if err := scoped.Handle(ctx, rec); err != nil {
+				r.Error = err.Error()
+			}
+		}
+		traversed = traversed || r.Logged || len(r.Sinks) > 0
+		report.Records = append(report.Records, r)
+	}
+	report.Sinks = h.sinkHealth(ctx)
+
+	report.Passed = traversed &&
+		!slices.ContainsFunc(report.Records, func(r SelfTestRecord) bool { return r.Error != "" }) &&
+		!slices.ContainsFunc(report.Sinks, func(s SinkHealth) bool { return s.Error != "" })
+	report.Duration = time.Since(start)
+	return report
+}
+
+// sinkHealth checks the health of all sinks of the config, sorted by name.
+func (h *Handler) sinkHealth(ctx context.Context) []SinkHealth {
+	var health []SinkHealth
+	for name, sh := range h.taps.sinkHandlers() {
+		s := SinkHealth{Name: name}
+		if hc, ok := sh.(HealthChecker); ok {
+			s.Checked = true
+			if err := hc.HealthCheck(ctx); err != nil {
+				s.Error = err.Error()
+			}
+		}
+		health = append(health, s)
+	}
+	h.mu.Lock()
+	for _, unavailable := range h.unavailableSinks {
+		name, reason, _ := strings.Cut(unavailable, ": ")
+		health = append(health, SinkHealth{Name: name, Error: "unavailable: " + reason})
+	}
+	h.mu.Unlock()
+	slices.SortFunc(health, func(a, b SinkHealth) int { return strings.Compare(a.Name, b.Name) })
+	return health
+}
@@ -0,0 +1,78 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkedHandler is a sink handler implementing slogscope.HealthChecker.
+type checkedHandler struct {
+	slog.Handler
+	err error
+}
+
+func (h *checkedHandler) HealthCheck(context.Context) error { return h.err }
+
+func TestHandler_SelfTest(t *testing.T) {
+	var buf, pagerBuf bytes.Buffer
+	pager := &checkedHandler{Handler: slog.NewTextHandler(&pagerBuf, nil)}
+	h := slogscope.NewHandler(slog.NewTextHandler(&buf, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Sinks:    []slogscope.Sink{{Name: "pager", LogLevel: slogscope.LogLevelWarn}},
+		},
+		Sinks: map[string]slog.Handler{"pager": pager},
+	})
+
+	t.Run("test records traverse the pipeline", func(t *testing.T) {
+		report := h.SelfTest(context.Background())
+		assert.True(t, report.Passed)
+		assert.Equal(t, []slogscope.SelfTestRecord{
+			{Level: "DEBUG"},
+			{Level: "INFO", Logged: true},
+			{Level: "WARN", Logged: true, Sinks: []string{"pager"}},
+			{Level: "ERROR", Logged: true, Sinks: []string{"pager"}},
+		}, report.Records)
+		assert.Equal(t, []slogscope.SinkHealth{{Name: "pager", Checked: true}}, report.Sinks)
+		assert.NotContains(t, buf.String(), "level=DEBUG")
+		assert.Contains(t, buf.String(), "level=INFO msg=\"slogscope self-test\" slogscope.selftest="+report.ID)
+		assert.Contains(t, pagerBuf.String(), "level=ERROR msg=\"slogscope self-test\" slogscope.selftest="+report.ID)
+	})
+
+	t.Run("test unhealthy and unavailable sinks fail the self-test", func(t *testing.T) {
+		pager.err = errors.New("connection refused")
+		defer func() { pager.err = nil }()
+		cfg := h.GetConfig()
+		cfg.Sinks = append(cfg.Sinks, slogscope.Sink{Name: "logs"})
+		h.UseConfig(cfg)
+
+		report := h.SelfTest(context.Background())
+		assert.False(t, report.Passed)
+		assert.Equal(t, []slogscope.SinkHealth{
+			{Name: "logs", Error: "unavailable: no handler given via HandlerOptions.Sinks and no sink type configured"},
+			{Name: "pager", Checked: true, Error: "connection refused"},
+		}, report.Sinks)
+	})
+
+	t.Run("test admin endpoint", func(t *testing.T) {
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		res, err := http.Post(srv.URL+"/selftest", "", nil)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		var report slogscope.SelfTestReport
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+		assert.False(t, report.Passed)
+		assert.Len(t, report.Records, 4)
+	})
+}
@@ -0,0 +1,35 @@
+package slogscope
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// service returns the ID of the service selecting its section of Config.Services, which is HandlerOptions.ServiceID
+// or, if not set, the environment variable SLOGSCOPE_SERVICE or the name of the binary.
+func (ss *slogscope) service() string {
+	if ss.opts.ServiceID != "" {
+		return ss.opts.ServiceID
+	}
+	if id := os.Getenv(envService); id != "" {
+		return id
+	}
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+}
+
+// applyService returns the config with the settings of the section of the service applied (see mergeConfig).
+func (ss *slogscope) applyService(cfg Config) Config {
+	if len(cfg.Services) == 0 {
+		return cfg
+	}
+	id := ss.service()
+	s, ok := cfg.Services[id]
+	if !ok {
+		ss.logger.Debug(fmt.Sprintf("no section for service %q! -> using config without service section.", id))
+		return cfg
+	}
+	ss.logger.Debug(fmt.Sprintf("using section of service %q", id))
+	return mergeConfig(cfg, s)
+}
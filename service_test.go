@@ -0,0 +1,55 @@
+package slogscope_test
+
+import (
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Services(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Services: map[string]slogscope.Config{
+			"api":    {Packages: []slogscope.Package{{Name: "github.com/foo/bar/http", LogLevel: slogscope.LogLevelDebug}}},
+			"worker": {LogLevel: slogscope.LogLevelWarn},
+		},
+		Profiles: map[string]slogscope.Config{
+			"prod": {LogLevel: slogscope.LogLevelError},
+		},
+	}
+	effective := func(id, profile string) slogscope.Config {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &cfg, ServiceID: id, Profile: profile})
+		return h.EffectiveConfig()
+	}
+
+	t.Run("test section of the service is applied", func(t *testing.T) {
+		got := effective("api", "")
+		assert.Equal(t, slogscope.LogLevelInfo, got.LogLevel)
+		assert.Equal(t, []slogscope.Package{{Name: "github.com/foo/bar/http", LogLevel: slogscope.LogLevelDebug}}, got.Packages)
+		assert.Nil(t, got.Services)
+		assert.Equal(t, slogscope.LogLevelWarn, effective("worker", "").LogLevel)
+	})
+
+	t.Run("test profile is applied after the service section", func(t *testing.T) {
+		assert.Equal(t, slogscope.LogLevelError, effective("worker", "prod").LogLevel)
+	})
+
+	t.Run("test service from environment", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_SERVICE", "worker")
+		assert.Equal(t, slogscope.LogLevelWarn, effective("", "").LogLevel)
+	})
+
+	t.Run("test binary name without section", func(t *testing.T) {
+		got := effective("", "")
+		assert.Equal(t, slogscope.LogLevelInfo, got.LogLevel)
+		assert.Empty(t, got.Packages)
+	})
+
+	t.Run("test nested services are invalid", func(t *testing.T) {
+		invalid := slogscope.Config{LogLevel: slogscope.LogLevelInfo, Services: map[string]slogscope.Config{
+			"api": {Services: map[string]slogscope.Config{"x": {}}},
+		}}
+		assert.ErrorContains(t, invalid.Validate(), "services.api")
+	})
+}
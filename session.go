@@ -0,0 +1,150 @@
+package slogscope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+)
+
+// OverrideSession is an active temporary config (see Handler.UseConfigTemporarily), e.g. the debug session of an
+// operator started via the admin endpoints, as persisted by a SessionStore.
+type OverrideSession struct {
+	Name    string            `json:"name"`              // Name of the session (see HandlerOptions.AnnotateOverrides).
+	Source  string            `json:"source"`            // Source of the config, e.g. "admin PUT /override until ...".
+	Sources map[string]string `json:"sources,omitempty"` // Sources of individual package rules by package name.
+	Config  Config            `json:"config"`
+	// Package is the package elevated by Handler.SetPackageLevelFor, whose rule is reverted alone, empty if the whole
+	// config is reverted.
+	Package string    `json:"package,omitempty"`
+	Expires time.Time `json:"expires"` // Time, after which the session reverts.
+}
+
+// SessionStore persists the active override sessions, so a restart of the process in the middle of an incident
+// restores them instead of silently reverting to the baseline config (see HandlerOptions.SessionStore).
+type SessionStore interface {
+	// Load returns the sessions saved last in the order they were started, or none if nothing was saved yet.
+	Load() ([]OverrideSession, error)
+	// Save replaces the saved sessions.
+	Save(sessions []OverrideSession) error
+}
+
+// FileSessionStore is a SessionStore keeping the sessions in a small JSON state file.
+type FileSessionStore struct {
+	filename string
+}
+
+// NewFileSessionStore returns a FileSessionStore for the given state file, e.g. "/var/lib/myapp/slogscope.state.json".
+func NewFileSessionStore(filename string) *FileSessionStore {
+	return &FileSessionStore{filename: filename}
+}
+
+// Load reads the state file. A missing state file contains no sessions.
+func (s *FileSessionStore) Load() ([]OverrideSession, error) {
+	data, err := os.ReadFile(s.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sessions []OverrideSession
+	if err = json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("invalid session state file %s: %w", s.filename, err)
+	}
+	return sessions, nil
+}
+
+// Save replaces the state file atomically. The state file is removed if there are no sessions.
+func (s *FileSessionStore) Save(sessions []OverrideSession) error {
+	if len(sessions) == 0 {
+		if err := os.Remove(s.filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeDataAtomic(s.filename, data)
+}
+
+// startSession adds the session to the active sessions and saves them, if HandlerOptions.SessionStore is given.
+// It returns a function removing the session again.
+func (ss *slogscope) startSession(s OverrideSession) func() {
+	if ss.opts.SessionStore == nil {
+		return func() {}
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.sessionSeq++
+	id := ss.sessionSeq
+	ss.sessions = append(ss.sessions, activeSession{id: id, OverrideSession: s})
+	ss.saveSessions()
+	return func() {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+
+		ss.sessions = slices.DeleteFunc(ss.sessions, func(s activeSession) bool { return s.id == id })
+		ss.saveSessions()
+	}
+}
+
+// activeSession is an active override session together with its ID.
+type activeSession struct {
+	id uint64
+	OverrideSession
+}
+
+// saveSessions saves the active sessions to HandlerOptions.SessionStore. The caller must hold ss.mu.
+func (ss *slogscope) saveSessions() {
+	sessions := make([]OverrideSession, 0, len(ss.sessions))
+	for _, s := range ss.sessions {
+		sessions = append(sessions, s.OverrideSession)
+	}
+	if err := ss.opts.SessionStore.Save(sessions); err != nil {
+		slog.New(ss.slogh).Warn("slogscope: error saving override sessions", "error", err.Error())
+	}
+}
+
+// restoreSessions restarts all unexpired sessions of HandlerOptions.SessionStore in the order they were started, so
+// reverting a session returns to the state before it like in the previous process.
+func (h *Handler) restoreSessions() {
+	if h.opts.SessionStore == nil {
+		return
+	}
+	sessions, err := h.opts.SessionStore.Load()
+	if err != nil {
+		slog.New(h.slogh).Warn("slogscope: error loading override sessions", "error", err.Error())
+		return
+	}
+	var restored int
+	for _, s := range sessions {
+		revert := time.Until(s.Expires)
+		if revert <= 0 {
+			continue
+		}
+		if s.Package != "" {
+			i := slices.IndexFunc(s.Config.Packages, func(p Package) bool { return p.Name == s.Package })
+			if i < 0 {
+				continue
+			}
+			h.setPackageLevelFor(s.Package, s.Config.Packages[i].LogLevel, revert, s.Source, s.Name)
+		} else {
+			h.useConfigTemporarily(s.Config, revert, provenance{source: s.Source, packages: s.Sources, session: s.Name})
+		}
+		restored++
+	}
+	if restored < len(sessions) {
+		// Drop the expired sessions from the store.
+		h.mu.Lock()
+		h.saveSessions()
+		h.mu.Unlock()
+	}
+	h.logger.Debug(fmt.Sprintf("restored %d of %d override sessions", restored, len(sessions)))
+}
@@ -0,0 +1,83 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_SessionStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slogscope.state.json")
+	newHandler := func() *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{
+			Config:       &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+			SessionStore: slogscope.NewFileSessionStore(filename),
+		})
+	}
+
+	t.Run("test active session is restored after restart", func(t *testing.T) {
+		h := newHandler()
+		h.UsePackageLevelTemporarily("github.com/foo/bar", slogscope.LogLevelDebug, time.Hour)
+		assert.FileExists(t, filename)
+
+		restarted := newHandler()
+		d := restarted.ExplainDecision("github.com/foo/bar", slog.LevelDebug)
+		assert.True(t, d.Enabled)
+		assert.Contains(t, d.Source, "UsePackageLevelTemporarily until")
+		assert.False(t, restarted.ExplainDecision("github.com/foo/baz", slog.LevelDebug).Enabled)
+	})
+
+	t.Run("test package session is restored after restart", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filename))
+		h := newHandler()
+		h.SetPackageLevelFor("github.com/foo/payments", slogscope.LogLevelDebug, time.Hour)
+
+		restarted := newHandler()
+		d := restarted.ExplainDecision("github.com/foo/payments", slog.LevelDebug)
+		assert.True(t, d.Enabled)
+		assert.Contains(t, d.Source, "SetPackageLevelFor until")
+		sessions, err := slogscope.NewFileSessionStore(filename).Load()
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, "github.com/foo/payments", sessions[0].Package)
+	})
+
+	t.Run("test reverted session is removed", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filename))
+		h := newHandler()
+		h.UseConfigTemporarily(slogscope.Config{LogLevel: slogscope.LogLevelDebug}, 50*time.Millisecond)
+		assert.FileExists(t, filename)
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(filename)
+			return os.IsNotExist(err)
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelInfo, newHandler().EffectiveConfig().LogLevel)
+	})
+
+	t.Run("test expired sessions are dropped", func(t *testing.T) {
+		store := slogscope.NewFileSessionStore(filename)
+		assert.NoError(t, store.Save([]slogscope.OverrideSession{{
+			Name:    "incident-1234",
+			Source:  "admin PUT /override",
+			Config:  slogscope.Config{LogLevel: slogscope.LogLevelDebug},
+			Expires: time.Now().Add(-time.Minute),
+		}}))
+
+		assert.Equal(t, slogscope.LogLevelInfo, newHandler().EffectiveConfig().LogLevel)
+		sessions, err := store.Load()
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("test invalid state file", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filename, []byte("{"), 0644))
+		_, err := slogscope.NewFileSessionStore(filename).Load()
+		assert.ErrorContains(t, err, "invalid session state file")
+	})
+}
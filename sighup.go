@@ -0,0 +1,56 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Reload loads the config from the ConfigSource (by default the config file) and applies it, e.g. after the config
+// file was changed while the file watcher is disabled. If loading fails, the current config is kept. Like UseConfig,
+// the loaded config replaces any active temporary configs. While reloads are paused (see PauseReloads), the loaded
+// config is applied by ResumeReloads.
+func (h *Handler) Reload() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.source()
+	cfg, err := s.Load()
+	if err != nil {
+		return fmt.Errorf("cannot reload config: %w", err)
+	}
+	if h.reloadsPaused {
+		h.pendingReload = &pendingReload{cfg: cfg, prov: sourceProvenance(s)}
+		h.logger.Debug("config reload deferred, reloads are paused")
+		return nil
+	}
+	h.temporaries = nil
+	h.opts.Config = &cfg
+	h.prov = sourceProvenance(s)
+	h.configure()
+	h.logger.Debug(fmt.Sprintf("reloaded config: %#v", cfg))
+	return nil
+}
+
+// reloadOnSIGHUP reloads the config whenever the process receives SIGHUP (see HandlerOptions.ReloadOnSIGHUP) until the
+// Handler is closed.
+func (h *Handler) reloadOnSIGHUP() {
+	sigCh := make(chan os.Signal, 1)
+	if !notifySIGHUP(sigCh) {
+		h.logger.Debug("SIGHUP is not supported on this platform! -> reload on SIGHUP is disabled.")
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				if err := h.Reload(); err != nil {
+					slog.New(h.slogh).Warn("slogscope: error reloading config on SIGHUP", "error", err.Error())
+				}
+			case <-h.closeCh:
+				stopNotify(sigCh)
+				return
+			}
+		}
+	}()
+}
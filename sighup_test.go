@@ -0,0 +1,52 @@
+//go:build !js
+
+package slogscope_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ReloadOnSIGHUP(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slogscope.yml")
+	assert.NoError(t, os.WriteFile(filename, []byte("log_level: INFO\n"), 0644))
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: filename, ReloadOnSIGHUP: true})
+	assert.Equal(t, slogscope.LogLevelInfo, h.EffectiveConfig().LogLevel)
+
+	t.Run("test reload keeps the config if loading fails", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: [\n"), 0644))
+		assert.Error(t, h.Reload())
+		assert.Equal(t, slogscope.LogLevelInfo, h.EffectiveConfig().LogLevel)
+	})
+
+	t.Run("test reload is deferred while reloads are paused", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: WARN\n"), 0644))
+		h.PauseReloads()
+		assert.NoError(t, h.Reload())
+		assert.Equal(t, slogscope.LogLevelInfo, h.EffectiveConfig().LogLevel)
+		h.ResumeReloads()
+		assert.Equal(t, slogscope.LogLevelWarn, h.EffectiveConfig().LogLevel)
+	})
+
+	t.Run("test SIGHUP reloads the config", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.NoError(t, os.WriteFile(filename, []byte("log_level: ERROR\n"), 0644))
+		p, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err)
+		if err = p.Signal(syscall.SIGHUP); err != nil {
+			t.Skipf("SIGHUP is not supported: %s", err.Error())
+		}
+		assert.Eventually(t, func() bool {
+			return h.EffectiveConfig().LogLevel == slogscope.LogLevelError
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,26 @@
+//go:build !js && !slogscope_minimal
+
+package slogscope
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifySIGHUP relays SIGHUP to the channel and reports whether the platform supports it.
+func notifySIGHUP(ch chan<- os.Signal) bool {
+	signal.Notify(ch, syscall.SIGHUP)
+	return true
+}
+
+// notifyTermination relays SIGINT and SIGTERM to the channel and reports whether the platform supports it.
+func notifyTermination(ch chan<- os.Signal) bool {
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	return true
+}
+
+// stopNotify stops relaying signals to the channel.
+func stopNotify(ch chan<- os.Signal) {
+	signal.Stop(ch)
+}
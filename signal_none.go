@@ -0,0 +1,18 @@
+//go:build js || slogscope_minimal
+
+package slogscope
+
+import "os"
+
+// notifySIGHUP reports that signals are not supported by js/wasm and the minimal build.
+func notifySIGHUP(chan<- os.Signal) bool {
+	return false
+}
+
+// notifyTermination reports that signals are not supported by js/wasm and the minimal build.
+func notifyTermination(chan<- os.Signal) bool {
+	return false
+}
+
+// stopNotify does nothing, since signals are not supported by js/wasm and the minimal build.
+func stopNotify(chan<- os.Signal) {}
@@ -0,0 +1,10 @@
+//go:build !unix || slogscope_minimal
+
+package slogscope
+
+import "os"
+
+// notifyVerbositySignals reports that SIGUSR1 and SIGUSR2 are not supported by the platform or the minimal build.
+func notifyVerbositySignals(_, _ chan<- os.Signal) bool {
+	return false
+}
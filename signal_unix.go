@@ -0,0 +1,16 @@
+//go:build unix && !slogscope_minimal
+
+package slogscope
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyVerbositySignals relays SIGUSR1 to debug and SIGUSR2 to restore and reports whether the platform supports it.
+func notifyVerbositySignals(debug, restore chan<- os.Signal) bool {
+	signal.Notify(debug, syscall.SIGUSR1)
+	signal.Notify(restore, syscall.SIGUSR2)
+	return true
+}
@@ -0,0 +1,42 @@
+package slogscope
+
+import (
+	"os"
+	"time"
+)
+
+// debugSignalSession is the name of the override session started by SIGUSR1 (see HandlerOptions.DebugOnSIGUSR1).
+const debugSignalSession = "SIGUSR1"
+
+// debugOnSIGUSR1 raises the global log level to DEBUG whenever the process receives SIGUSR1 and reverts as soon as it
+// receives SIGUSR2 or ttl has elapsed (see HandlerOptions.DebugOnSIGUSR1). Another SIGUSR1 restarts the ttl.
+// It stops as soon as the Handler is closed.
+func (h *Handler) debugOnSIGUSR1(ttl time.Duration) {
+	debugCh, restoreCh := make(chan os.Signal, 1), make(chan os.Signal, 1)
+	if !notifyVerbositySignals(debugCh, restoreCh) {
+		h.logger.Debug("SIGUSR1 and SIGUSR2 are not supported on this platform! -> debug on SIGUSR1 is disabled.")
+		return
+	}
+	go func() {
+		revert := func() {}
+		for {
+			select {
+			case <-debugCh:
+				revert()
+				source := "SIGUSR1 until " + time.Now().Add(ttl).Format(time.RFC3339)
+				revert = h.updateTemporarily(ttl, func(cfg Config, _ provenance) (Config, provenance) {
+					cfg.LogLevel = LogLevelDebug
+					return cfg, provenance{source: source, session: debugSignalSession}
+				})
+			case <-restoreCh:
+				revert()
+				revert = func() {}
+			case <-h.closeCh:
+				stopNotify(debugCh)
+				stopNotify(restoreCh)
+				revert()
+				return
+			}
+		}
+	}()
+}
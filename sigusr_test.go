@@ -0,0 +1,75 @@
+//go:build unix && !slogscope_minimal
+
+package slogscope_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_DebugOnSIGUSR1(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Config:         &slogscope.Config{LogLevel: slogscope.LogLevelWarn},
+		DebugOnSIGUSR1: 300 * time.Millisecond,
+	})
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	debugEnabled := func() bool {
+		return h.Enabled(context.Background(), slog.LevelDebug)
+	}
+
+	t.Run("test SIGUSR2 reverts immediately", func(t *testing.T) {
+		assert.NoError(t, p.Signal(syscall.SIGUSR1))
+		assert.Eventually(t, debugEnabled, time.Second, 5*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelDebug, h.GetConfig().LogLevel)
+
+		assert.NoError(t, p.Signal(syscall.SIGUSR2))
+		assert.Eventually(t, func() bool { return !debugEnabled() }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test SIGUSR1 reverts after the ttl", func(t *testing.T) {
+		assert.NoError(t, p.Signal(syscall.SIGUSR1))
+		assert.Eventually(t, debugEnabled, time.Second, 5*time.Millisecond)
+		assert.Eventually(t, func() bool { return !debugEnabled() }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+	})
+}
+
+func TestHandler_DebugOnSIGUSR1Close(t *testing.T) {
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Config:         &slogscope.Config{LogLevel: slogscope.LogLevelWarn},
+		DebugOnSIGUSR1: time.Minute,
+	})
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Signal(syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelDebug
+	}, time.Second, 5*time.Millisecond)
+
+	// Closing the Handler reverts the debug session.
+	assert.NoError(t, h.Close())
+	assert.Eventually(t, func() bool {
+		return h.GetConfig().LogLevel == slogscope.LogLevelWarn
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, h.Close())
+
+	// Keep SIGUSR1 from terminating the test process, as the closed Handler no longer receives it.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+	assert.NoError(t, p.Signal(syscall.SIGUSR1))
+	<-sigCh
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+}
@@ -0,0 +1,217 @@
+// Package sentry provides a sink handler sending records as events to Sentry (see slogscope.HandlerOptions.Sinks).
+// Importing the package registers the sink type "sentry", so Sentry sinks can be created from the config with the
+// options dsn, environment, release and tags (comma-separated attribute keys).
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/internal/attrs"
+)
+
+func init() {
+	slogscope.RegisterSinkType("sentry", newSink)
+}
+
+// newSink creates a sink of the type "sentry" with the options dsn, environment, release and tags
+// (comma-separated attribute keys).
+func newSink(options map[string]string) (slog.Handler, error) {
+	opts := &Options{Environment: options["environment"], Release: options["release"]}
+	if tags := options["tags"]; tags != "" {
+		opts.Tags = strings.Split(tags, ",")
+	}
+	return NewHandler(options["dsn"], opts)
+}
+
+// Options are options for a Sentry handler created by NewHandler.
+type Options struct {
+	Environment string       // Environment of the events, e.g. "production".
+	Release     string       // Release of the events (default: main module version from the build info).
+	Tags        []string     // Attribute keys sent as tags. All other attributes are sent as extras.
+	Client      *http.Client // HTTP client used for sending events (default: client with a 5s timeout).
+	// QueueSize is the number of events queued for sending. Events are sent in the background, further records are
+	// dropped and counted in slogscope.Stats.SinkDrops (default: 100).
+	QueueSize int
+}
+
+// handler is a slog.Handler sending every record as event to Sentry. It is meant to be used as sink
+// (see slogscope.HandlerOptions.Sinks), so only records of selected packages and levels are forwarded.
+type handler struct {
+	opts     Options
+	storeURL string
+	auth     string
+	attrs    []slog.Attr          // Flattened attributes added via WithAttrs.
+	prefix   string               // Group prefix for attribute keys added via WithGroup.
+	queue    *slogscope.SinkQueue // Sends the events in the background, shared with the handlers derived via WithAttrs.
+}
+
+// event is the payload of the Sentry store endpoint.
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger,omitempty"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+}
+
+// NewHandler creates a new slog.Handler sending records as events to the Sentry project of the given DSN,
+// e.g. https://<key>@o0.ingest.sentry.io/<project>. A fingerprint attribute (see slogscope.HandlerOptions.Fingerprint)
+// is used as Sentry fingerprint, so events are grouped the same way.
+func NewHandler(dsn string, opts *Options) (slog.Handler, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || project == "." || project == "/" {
+		return nil, fmt.Errorf("invalid sentry dsn: %q", dsn)
+	}
+
+	h := &handler{
+		storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, strings.TrimSuffix(path.Dir(u.Path), "/"), project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=slogscope, sentry_key=%s", u.User.Username()),
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && h.opts.Release == "" {
+		h.opts.Release = bi.Main.Version
+	}
+	if h.opts.Client == nil {
+		h.opts.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	h.queue = slogscope.NewSinkQueue(h.opts.QueueSize)
+	return h, nil
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	ev := event{
+		EventID:     newEventID(),
+		Timestamp:   rec.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       level(rec.Level),
+		Logger:      slogscope.PackageName(rec.PC),
+		Message:     rec.Message,
+		Environment: h.opts.Environment,
+		Release:     h.opts.Release,
+		Tags:        map[string]string{},
+		Extra:       map[string]any{},
+	}
+	flat := slices.Clone(h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		flat = attrs.Flatten(flat, h.prefix, a)
+		return true
+	})
+	for _, a := range flat {
+		switch {
+		case a.Key == slogscope.FingerprintKey:
+			ev.Fingerprint = []string{a.Value.String()}
+		case slices.Contains(h.opts.Tags, a.Key):
+			ev.Tags[a.Key] = a.Value.String()
+		default:
+			ev.Extra[a.Key] = attrs.JSONValue(a.Value)
+		}
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return h.queue.Send(ctx, rec, func(ctx context.Context) error {
+		return h.send(ctx, body)
+	})
+}
+
+// send posts the encoded event to the Sentry store endpoint.
+func (h *handler) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", h.auth)
+
+	resp, err := h.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected sentry response: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops sending events after the queued events are sent. Records must not be handled afterward.
+func (h *handler) Close() error {
+	return h.queue.Close()
+}
+
+// SinkQueue returns the queue of the events (see slogscope.QueuedSink).
+func (h *handler) SinkQueue() *slogscope.SinkQueue {
+	return h.queue
+}
+
+func (h *handler) WithAttrs(as []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = slices.Clone(h.attrs)
+	for _, a := range as {
+		h2.attrs = attrs.Flatten(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// level maps the log level to a Sentry level.
+func level(lvl slog.Level) string {
+	switch {
+	case lvl > slog.LevelError:
+		return "fatal"
+	case lvl >= slog.LevelError:
+		return "error"
+	case lvl >= slog.LevelWarn:
+		return "warning"
+	case lvl >= slog.LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
+
+// newEventID returns a random event ID (UUID without dashes).
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,123 @@
+package sentry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/sink/sentry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+		var ev map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&ev))
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink, err := sentry.NewHandler(strings.Replace(srv.URL, "://", "://public@", 1)+"/42", &sentry.Options{
+		Environment: "test",
+		Tags:        []string{"user"},
+	})
+	assert.NoError(t, err)
+
+	h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Sinks: []slogscope.Sink{
+				{Name: "sentry", LogLevel: slogscope.LogLevelError, Packages: []string{"apperia-de/slogscope/sink/sentry_test"}},
+				{Name: "sentry", LogLevel: slogscope.LogLevelWarn, Packages: []string{"github.com/foo/bar"}},
+			},
+		},
+		Sinks: map[string]slog.Handler{"sentry": sink},
+	})
+	l := slog.New(h)
+	l.Warn("warn message", "user", "alice")
+	l.Error("error message", "user", "alice", slog.Group("req", "id", 7), "error", errors.New("failed"))
+	h.Flush()
+
+	mu.Lock()
+	if assert.Len(t, events, 1) {
+		ev := events[0]
+		assert.Equal(t, "error message", ev["message"])
+		assert.Equal(t, "error", ev["level"])
+		assert.Equal(t, "test", ev["environment"])
+		assert.Equal(t, "github.com/apperia-de/slogscope/sink/sentry_test", ev["logger"])
+		assert.Equal(t, map[string]any{"user": "alice"}, ev["tags"])
+		assert.Equal(t, map[string]any{"req.id": float64(7), "error": "failed"}, ev["extra"])
+	}
+	mu.Unlock()
+
+	t.Run("test unreachable sentry", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+
+		sink, err := sentry.NewHandler(strings.Replace(slow.URL, "://", "://public@", 1)+"/42", &sentry.Options{QueueSize: 1})
+		assert.NoError(t, err)
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks:    []slogscope.Sink{{Name: "sentry"}},
+			},
+			Sinks: map[string]slog.Handler{"sentry": sink},
+		})
+		l := slog.New(h)
+
+		start := time.Now()
+		for range 5 {
+			l.Error("error message")
+		}
+		assert.Less(t, time.Since(start), time.Second)
+		close(release)
+		h.Flush()
+		// One event is sent, one is queued and the others are dropped.
+		assert.GreaterOrEqual(t, h.Stats().SinkDrops["sentry"], uint64(3))
+	})
+
+	t.Run("test sink type", func(t *testing.T) {
+		events = nil
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks: []slogscope.Sink{{
+					Name:     "errors",
+					Type:     "sentry",
+					LogLevel: slogscope.LogLevelError,
+					Options:  map[string]string{"dsn": strings.Replace(srv.URL, "://", "://public@", 1) + "/42", "environment": "prod"},
+				}},
+			},
+		})
+		slog.New(h).Error("error message")
+		h.Flush()
+		assert.Empty(t, h.Stats().UnavailableSinks)
+		mu.Lock()
+		defer mu.Unlock()
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "prod", events[0]["environment"])
+		}
+	})
+
+	t.Run("test invalid dsn", func(t *testing.T) {
+		_, err := sentry.NewHandler("https://sentry.example.com/42", nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,218 @@
+// Package webhook provides a sink handler posting records to a webhook (see slogscope.HandlerOptions.Sinks), e.g. a
+// Slack, Teams or PagerDuty webhook. Importing the package registers the sink type "webhook", so webhook sinks can be
+// created from the config with the options url, template and content_type.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/internal/attrs"
+)
+
+func init() {
+	slogscope.RegisterSinkType("webhook", newSink)
+}
+
+// defaultTemplate renders a payload compatible with Slack and Microsoft Teams incoming webhooks.
+const defaultTemplate = `{"text": {{ printf "[%s] %s: %s" .Level .Package .Message | json }}}`
+
+// errRateLimited is returned by the webhook handler for records dropped due to the rate limit.
+var errRateLimited = errors.New("webhook rate limit exceeded")
+
+// Options are options for a webhook handler created by NewHandler.
+type Options struct {
+	// Template is a text/template rendering the request body from a Payload. The function json encodes
+	// a value as JSON (default: Slack and Microsoft Teams compatible {"text": "[LEVEL] package: message"}).
+	Template     string
+	ContentType  string            // Content-Type of the request body (default: application/json).
+	Headers      map[string]string // Additional request headers, e.g. for authorization.
+	RateLimit    int               // Maximum number of requests per RateInterval. Further records are dropped (default: unlimited).
+	RateInterval time.Duration     // Interval of the rate limit (default: 1m).
+	Client       *http.Client      // HTTP client used for sending requests (default: client with a 5s timeout).
+	// QueueSize is the number of requests queued for sending. Requests are sent in the background, further records
+	// are dropped and counted in slogscope.Stats.SinkDrops (default: 100).
+	QueueSize int
+}
+
+// Payload is the data passed to Options.Template.
+type Payload struct {
+	Time    time.Time
+	Level   string
+	Package string
+	Message string
+	Attrs   map[string]any // Attributes with dotted keys for groups.
+}
+
+// handler is a slog.Handler sending every record to a webhook. It is meant to be used as sink
+// (see slogscope.HandlerOptions.Sinks), so only records of selected packages and levels trigger the webhook.
+type handler struct {
+	url     string
+	opts    Options
+	tmpl    *template.Template
+	limiter *rateLimiter
+	attrs   []slog.Attr          // Flattened attributes added via WithAttrs.
+	prefix  string               // Group prefix for attribute keys added via WithGroup.
+	queue   *slogscope.SinkQueue // Sends the requests in the background, shared with the handlers derived via WithAttrs.
+}
+
+// NewHandler creates a new slog.Handler posting every record to the given URL, e.g. a Slack, Teams or
+// PagerDuty webhook, using a request body rendered by Options.Template.
+func NewHandler(url string, opts *Options) (slog.Handler, error) {
+	h := &handler{url: url}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Template == "" {
+		h.opts.Template = defaultTemplate
+	}
+	if h.opts.ContentType == "" {
+		h.opts.ContentType = "application/json"
+	}
+	if h.opts.RateInterval <= 0 {
+		h.opts.RateInterval = time.Minute
+	}
+	if h.opts.Client == nil {
+		h.opts.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if h.opts.RateLimit > 0 {
+		h.limiter = &rateLimiter{limit: h.opts.RateLimit, interval: h.opts.RateInterval}
+	}
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(h.opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	h.tmpl = tmpl
+	h.queue = slogscope.NewSinkQueue(h.opts.QueueSize)
+	return h, nil
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	if h.limiter != nil && !h.limiter.allow(time.Now()) {
+		return errRateLimited
+	}
+
+	p := Payload{
+		Time:    rec.Time,
+		Level:   rec.Level.String(),
+		Package: slogscope.PackageName(rec.PC),
+		Message: rec.Message,
+		Attrs:   map[string]any{},
+	}
+	flat := slices.Clone(h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		flat = attrs.Flatten(flat, h.prefix, a)
+		return true
+	})
+	for _, a := range flat {
+		p.Attrs[a.Key] = attrs.JSONValue(a.Value)
+	}
+
+	var body bytes.Buffer
+	if err := h.tmpl.Execute(&body, p); err != nil {
+		return err
+	}
+	return h.queue.Send(ctx, rec, func(ctx context.Context) error {
+		return h.send(ctx, body.Bytes())
+	})
+}
+
+// send posts the rendered request body to the webhook.
+func (h *handler) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", h.opts.ContentType)
+	for k, v := range h.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops sending requests after the queued requests are sent. Records must not be handled afterward.
+func (h *handler) Close() error {
+	return h.queue.Close()
+}
+
+// SinkQueue returns the queue of the requests (see slogscope.QueuedSink).
+func (h *handler) SinkQueue() *slogscope.SinkQueue {
+	return h.queue
+}
+
+func (h *handler) WithAttrs(as []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = slices.Clone(h.attrs)
+	for _, a := range as {
+		h2.attrs = attrs.Flatten(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// newSink creates a sink of the type "webhook" with the options url, template and content_type.
+func newSink(options map[string]string) (slog.Handler, error) {
+	return NewHandler(options["url"], &Options{Template: options["template"], ContentType: options["content_type"]})
+}
+
+// rateLimiter allows at most limit events per fixed interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	interval time.Duration
+	start    time.Time
+	count    int
+}
+
+// allow reports whether another event is allowed at the given time.
+func (l *rateLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.start) >= l.interval {
+		l.start = now
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	return true
+}
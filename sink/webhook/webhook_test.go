@@ -0,0 +1,125 @@
+package webhook_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/sink/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		mu.Lock()
+		bodies = append(bodies, r.Header.Get("Authorization")+" "+string(data))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	newHandler := func(opts *webhook.Options) *slogscope.Handler {
+		sink, err := webhook.NewHandler(srv.URL, opts)
+		assert.NoError(t, err)
+		return slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks:    []slogscope.Sink{{Name: "pager", LogLevel: slogscope.LogLevelError}},
+			},
+			Sinks: map[string]slog.Handler{"pager": sink},
+		})
+	}
+
+	t.Run("test default payload", func(t *testing.T) {
+		bodies = nil
+		h := newHandler(nil)
+		l := slog.New(h)
+		l.Warn("warn message")
+		l.Error(`error "message"`)
+		h.Flush()
+		assert.Equal(t, []string{` {"text": "[ERROR] github.com/apperia-de/slogscope/sink/webhook_test: error \"message\""}`}, bodies)
+	})
+
+	t.Run("test templated payload", func(t *testing.T) {
+		bodies = nil
+		h := newHandler(&webhook.Options{
+			Template: `{"summary": {{ json .Message }}, "severity": "critical", "source": {{ json (index .Attrs "db.host") }}}`,
+			Headers:  map[string]string{"Authorization": "Token secret"},
+		})
+		slog.New(h).Error("connection lost", slog.Group("db", "host", "db-1"))
+		h.Flush()
+		assert.Equal(t, []string{`Token secret {"summary": "connection lost", "severity": "critical", "source": "db-1"}`}, bodies)
+	})
+
+	t.Run("test rate limit", func(t *testing.T) {
+		bodies = nil
+		h := newHandler(&webhook.Options{RateLimit: 2, RateInterval: time.Hour})
+		l := slog.New(h)
+		for range 5 {
+			l.Error("error message")
+		}
+		h.Flush()
+		assert.Len(t, bodies, 2)
+	})
+
+	t.Run("test slow webhook", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+
+		sink, err := webhook.NewHandler(slow.URL, &webhook.Options{QueueSize: 1})
+		assert.NoError(t, err)
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks:    []slogscope.Sink{{Name: "pager", LogLevel: slogscope.LogLevelError}},
+			},
+			Sinks: map[string]slog.Handler{"pager": sink},
+		})
+		l := slog.New(h)
+
+		start := time.Now()
+		for range 5 {
+			l.Error("error message")
+		}
+		assert.Less(t, time.Since(start), time.Second)
+		close(release)
+		h.Flush()
+		// One request is sent, one is queued and the others are dropped.
+		assert.GreaterOrEqual(t, h.Stats().SinkDrops["pager"], uint64(3))
+	})
+
+	t.Run("test sink type", func(t *testing.T) {
+		bodies = nil
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Sinks: []slogscope.Sink{{
+					Name:     "pager",
+					Type:     "webhook",
+					LogLevel: slogscope.LogLevelError,
+					Options:  map[string]string{"url": srv.URL, "template": "{{ .Message }}"},
+				}},
+			},
+		})
+		slog.New(h).Error("error message")
+		h.Flush()
+		assert.Empty(t, h.Stats().UnavailableSinks)
+		assert.Equal(t, []string{" error message"}, bodies)
+	})
+
+	t.Run("test invalid template", func(t *testing.T) {
+		_, err := webhook.NewHandler(srv.URL, &webhook.Options{Template: "{{ .Message"})
+		assert.Error(t, err)
+	})
+}
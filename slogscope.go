@@ -2,24 +2,35 @@ package slogscope
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
-
-	"github.com/fsnotify/fsnotify"
-	"gopkg.in/yaml.v3"
+	"sync/atomic"
+	"time"
 )
 
 // Constants for debug mode and defaults values.
 const (
 	defaultLogLevel   = LogLevelInfo
 	defaultConfigFile = "slogscope.yml"
+
+	defaultMaxRetries     = 3
+	defaultRetryInterval  = 100 * time.Millisecond
+	defaultDeadLetterFile = "slogscope.dlq"
+	defaultSinkQueueSize  = 100 // Number of records queued by a SinkQueue before records are dropped.
+
+	tailBufferSize      = 256              // Number of records buffered per tail client before records are dropped.
+	maxSnapshotDuration = 10 * time.Minute // Maximum duration of debug snapshots requested via the admin endpoints.
 )
 
 // Available log levels for the Config.
@@ -30,218 +41,659 @@ const (
 	LogLevelError = "ERROR"
 )
 
+// Available formats for Handler.ExportConfig.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+	FormatEnv  = "env"
+)
+
+// Available delivery guarantees for the Config.
+const (
+	DeliveryBestEffort = "best-effort" // Records are handed over to the wrapped slog.Handler exactly once.
+	DeliveryDurable    = "durable"     // Records are delivered at least once (see DeliveryOptions).
+)
+
 // slogscope contains all required Handler configurations.
 type slogscope struct {
-	h      *Handler
-	slogh  slog.Handler
-	opts   *HandlerOptions
-	logLvl slog.Level // Global log level
-	pkgMap sync.Map
+	h     *Handler
+	slogh slog.Handler
+	opts  *HandlerOptions
+	// settings contains the settings of the applied config used while handling records, which are replaced as a whole
+	// by configure, so Enabled and Handle don't need to lock ss.mu.
+	settings atomic.Pointer[settings]
+	pkgMap   sync.Map
+	// patterns contains the package rules with wildcard names, which are also part of pkgMap.
+	patterns atomic.Pointer[patterns]
 	//lvlMap sync.Map
-	mu     sync.Mutex
-	doneCh chan struct{}
-	logger *slog.Logger
+	mu       sync.Mutex
+	updateMu sync.Mutex // Serializes updates of a part of the config, e.g. SetPackageLevel.
+	doneCh   chan struct{}
+	// closeCh is closed by Handler.Close to stop the goroutines running for the lifetime of the Handler.
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	logger    *slog.Logger
+	delivery  *deliverer
+	taps      taps
+	mirrors   taps     // Handlers receiving a copy of every emitted record (see Handler.Mirror).
+	observed  sync.Map // Number of observed log calls (*atomic.Uint64) by package name.
+	// occurrences contains the records counted for Package.First (*occurrences) by package name.
+	occurrences sync.Map
+	children    children
+	removeSinks []func() // Removes the taps of the currently configured sinks.
+	// createdSinks contains the handlers created by registered sink types by sink definition, so they are reused by
+	// reapplied configs and closed as soon as no config uses them anymore.
+	createdSinks map[string]slog.Handler
+	// unavailableSinks describes all sinks of the config, which are disabled, because their handler is missing.
+	unavailableSinks []string
+	expiryTimer      *wallTimer     // Reapplies the config as soon as the next package rule expires.
+	quietTimer       *wallTimer     // Reapplies the config as soon as the next quiet hours start or end.
+	maintenance      bool           // Whether the maintenance mode is active (see Handler.SetMaintenanceMode).
+	reloadsPaused    bool           // Whether config changes of the watcher are deferred (see Handler.PauseReloads).
+	pendingReload    *pendingReload // Latest config change of the watcher received while reloads are paused.
+	prov             provenance     // Provenance of HandlerOptions.Config.
+	globalSource     string         // Source of the global log level.
+	history          []HistoryEntry
+	temporaries      []*temporaryConfig // Stack of active temporary configs, the last one is applied.
+	watchers         configWatchers     // Channels notified about applied configs (see Handler.WatchConfig).
+	applied          *Config            // Last applied HandlerOptions.Config, which is validated and recorded only once.
+	appliedAt        time.Time
+	appliedCnt       uint64          // Value of handled when the last HandlerOptions.Config was applied.
+	handled          atomic.Uint64   // Number of records handed to the wrapped slog.Handler.
+	unresolved       atomic.Uint64   // Number of log calls without a resolvable caller.
+	warnings         warnings        // Internal warnings emitted once per key (see warnOnce).
+	scopes           sync.Map        // Registered package names (string) by runtime package name (see Handler.RegisterScope).
+	droppedAttrs     sync.Map        // Number of attributes (*atomic.Uint64) removed by Package.AllowedKeys by package name.
+	sessions         []activeSession // Active override sessions persisted by HandlerOptions.SessionStore.
+	sessionSeq       uint64          // ID of the last started override session.
+	callers          sync.Map        // Resolved functions (*caller) by entry (see callerOf).
+	decisions        *decisionCache  // Decisions of Handler.Enabled by call site.
+	runtime          runtimeSnapshot // Runtime snapshot attached to ERROR records (see Package.RuntimeMetrics).
 }
 
-// pkg contains information about the package name and corresponding log level.
-type pkg struct {
-	name     string
-	logLevel slog.Level
+// settings contains the settings of the applied config, which are used while handling records.
+type settings struct {
+	logLvl slog.Level // Global log level
+	// durable is the global delivery guarantee, which applies to all packages without their own delivery setting.
+	durable    bool
+	metadata   []slog.Attr // Instance metadata attributes added to every record.
+	normalizer *normalizer // Unit normalization of duration and size attributes, nil if disabled (see Config.Normalize).
+	renamer    *renamer    // Moves attributes to new keys, nil if disabled (see Config.Renames).
+	// schemaVersion is the record schema version added to every record, empty if disabled (see Config.SchemaVersion).
+	schemaVersion string
+	// overrideSession is the name of the active override session added to every record (see
+	// HandlerOptions.AnnotateOverrides), empty if no temporary override is active.
+	overrideSession string
+	// buildInfo enables the build group for records at or above buildInfoLvl.
+	buildInfo    bool
+	buildInfoLvl slog.Level
+	generated    string // Scope of records logged from generated code (see Config.Generated).
+	// fallbackScope is the package name of records without a resolvable caller (see Config.FallbackScope).
+	fallbackScope string
+	testPackages  bool // Whether external test packages match the rules of their packages (see Config.TestPackages).
 }
 
-// callInfo represents the result of the call to getCallerInfo(skip int).
-type callInfo struct {
-	FuncName    string `json:"funcName,omitempty"`
-	PackageName string `json:"packageName"`
-	Filename    string `json:"filename"`
-	FilePath    string `json:"filePath"`
-	LineNo      int    `json:"lineNo"`
-	Source      string `json:"source"`
+// pkg contains information about the package name and corresponding log level.
+type pkg struct {
+	name        string
+	logLevel    slog.Level
+	durable     bool
+	description string
+	source      string        // Source of the package rule (see provenance).
+	re          stringMatcher // Regular expression of a rule with MatchRegex.
+	module      bool          // Whether the name is a module path (see MatchModule).
+	short       bool          // Whether the name is the last path element of packages (see MatchShort).
+	priority    int
+	first       int                 // Number of records of every distinct message, which are emitted regardless of logLevel.
+	allowedKeys map[string]struct{} // Attribute keys the package may emit, nil if all keys are allowed.
+	runtime     bool                // Whether ERROR records carry a runtime snapshot (see Package.RuntimeMetrics).
+	maxLevel    *slog.Level         // Maximum log level of records in strict mode, nil if unrestricted (see Package.MaxLevel).
+	functions   []function          // Log level overrides for functions of the package.
+	receivers   []function          // Log level overrides for the methods of receiver types of the package.
 }
 
-// initConfigFileWatcher watches the specified config file for changes and reflects them instantly in their
-// log response during program runtime without restarting.
-func (ss *slogscope) initConfigFileWatcher() chan struct{} {
-	if !checkFileExists(ss.opts.ConfigFile) {
-		ss.logger.Debug(fmt.Sprintf("config file %q does not exists! -> file watcher is disabled.", ss.opts.ConfigFile))
-		return nil
-	}
-
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		ss.logger.Debug(err.Error())
-		return nil
-	}
-
-	// Add the config file to watch.
-	err = watcher.Add(ss.opts.ConfigFile)
-	if err != nil {
-		ss.logger.Debug(err.Error())
-		return nil
-	}
-
+// watchConfig watches the ConfigSource for changes and reflects them instantly in their
+// log response during program runtime without restarting. Watching stops as soon as the returned channel is closed.
+func (ss *slogscope) watchConfig() chan struct{} {
+	s := ss.source()
 	doneCh := make(chan struct{})
-	// Start listening for events.
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		ss.logger.Debug(fmt.Sprintf("started file watcher for config file (%s).", ss.opts.ConfigFile))
+		<-doneCh
+		cancel()
+	}()
 
-		closeWatcher := func() {
-			if err := watcher.Close(); err != nil {
-				ss.logger.Debug(fmt.Sprintf("file watcher error for config file (%s): %s.", ss.opts.ConfigFile, err.Error()))
-				return
-			}
-			ss.logger.Debug(fmt.Sprintf("stopped file watcher for config file (%s).", ss.opts.ConfigFile))
+	apply := func(cfg Config) {
+		// The provenance is determined before locking, because sources like CompositeSource lock their own state,
+		// which is also locked while they pass on the config.
+		prov := sourceProvenance(s)
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		select {
+		case <-doneCh:
+			// The config was replaced in the meantime, e.g. by UseConfig.
+			return
+		default:
+		}
+		if ss.reloadsPaused {
+			ss.pendingReload = &pendingReload{cfg: cfg, prov: prov, doneCh: doneCh}
+			return
 		}
+		ss.opts.Config = &cfg
+		ss.prov = prov
+		ss.configure()
+	}
 
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				switch {
-				case event.Has(fsnotify.Remove):
-					ss.logger.Debug(fmt.Sprintf("config file (%s) was removed.", event.Name))
-					closeWatcher()
-					return
-				case event.Has(fsnotify.Rename):
-					ss.logger.Debug(fmt.Sprintf("config file (%s) was renamed.", event.Name))
-					closeWatcher()
-					return
-				case event.Has(fsnotify.Write):
-					ss.logger.Debug(fmt.Sprintf("config file (%s) was modified.", event.Name))
-					ss.loadConfig().initHandler()
-					closeWatcher()
-					return
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				ss.logger.Debug(fmt.Sprintf("file watcher error for config file (%s): %s.", ss.opts.ConfigFile, err.Error()))
-			case <-doneCh:
-				closeWatcher()
-				return
-			}
+	// ConfigProviders are watched before returning, so changes right after creating the Handler are not missed.
+	if ps, ok := s.(*providerSource); ok {
+		if ch := ps.startWatch(ctx); ch != nil {
+			go ps.forward(ctx, ch, apply)
 		}
-	}()
+	} else {
+		go s.Watch(ctx, apply)
+	}
 
 	return doneCh
 }
 
+// source returns the configured HandlerOptions.ConfigSource or, for the deprecated options, a ConfigSource for
+// HandlerOptions.ConfigProvider or HandlerOptions.ConfigFiles. Otherwise, it returns a ConfigSource for the config
+// directory or file given by HandlerOptions.ConfigFile.
+func (ss *slogscope) source() ConfigSource {
+	if ss.opts.ConfigSource != nil {
+		return ss.opts.ConfigSource
+	}
+	if ss.opts.ConfigProvider != nil {
+		return ss.sourceOf(ss.opts.ConfigProvider)
+	}
+	if len(ss.opts.ConfigFiles) > 0 {
+		mp := NewMultiFileProvider(ss.opts.ConfigFiles...)
+		mp.logger = ss.logger
+		return ss.sourceOf(mp)
+	}
+	if isDir(ss.opts.ConfigFile) {
+		dp := NewDirProvider(ss.opts.ConfigFile)
+		dp.logger = ss.logger
+		return ss.sourceOf(dp)
+	}
+	fp := NewFileProvider(ss.opts.ConfigFile)
+	fp.logger = ss.logger
+	return ss.sourceOf(fp)
+}
+
+// sourceOf returns a ConfigSource for the ConfigProvider like SourceOf, logging via the logger of the Handler.
+func (ss *slogscope) sourceOf(p ConfigProvider) ConfigSource {
+	return &providerSource{provider: p, logger: ss.logger}
+}
+
+// hasSource reports whether the config is loaded from a HandlerOptions.ConfigSource or HandlerOptions.ConfigProvider
+// instead of config files.
+func (ss *slogscope) hasSource() bool {
+	return ss.opts.ConfigSource != nil || ss.opts.ConfigProvider != nil
+}
+
 // initHandler initializes the slogscope instance depending on the given HandlerOptions.
-// If opts.EnableFileWatcher == true, the Handler will try to load the config from a config file,
-// specified by HandlerOptions.ConfigFile (fallback filename is defaultConfigFile), and if that fails,
-// it uses a default Config with "INFO" as global log level.
+// If opts.EnableFileWatcher == true, the Handler watches the HandlerOptions.ConfigSource or ConfigProvider or,
+// if not present, the config file specified by HandlerOptions.ConfigFile (fallback filename is defaultConfigFile)
+// for changes.
+// Without a config, it uses a default Config with "INFO" as global log level.
 func (ss *slogscope) initHandler() {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
+	ss.configure()
+
+	if ss.doneCh != nil {
+		close(ss.doneCh)
+		ss.doneCh = nil
+	}
+
+	if ss.opts.EnableFileWatcher && (ss.hasSource() || ss.opts.ConfigFile != "") && !ss.closed() {
+		ss.doneCh = ss.watchConfig()
+	}
+}
+
+// closed reports whether the Handler was closed (see Handler.Close).
+func (ss *slogscope) closed() bool {
+	select {
+	case <-ss.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// configure applies the current HandlerOptions.Config. The caller must hold ss.mu.
+func (ss *slogscope) configure() {
 	if ss.opts.Config == nil {
 		ss.opts.Config = &Config{
 			LogLevel: defaultLogLevel,
 			Packages: nil,
 		}
+		ss.prov = provenance{source: "default config"}
 
 		// Create a config file if it does not already exist.
-		if !checkFileExists(ss.opts.ConfigFile) {
+		if !minimalBuild && !ss.opts.DisableConfigFileCreation && !ss.hasSource() && len(ss.opts.ConfigFiles) == 0 && !checkFileExists(ss.opts.ConfigFile) {
 			ss.opts.Config.Packages = ss.createPackageList()
 
-			data, err := yaml.Marshal(ss.opts.Config)
+			data, err := marshalConfig(ss.opts.ConfigFile, ss.opts.Config)
 			if err != nil {
 				ss.logger.Error(err.Error())
-			}
-			err = os.WriteFile(ss.opts.ConfigFile, data, 0644)
-			if err != nil {
+			} else if err = os.WriteFile(ss.opts.ConfigFile, data, 0644); err != nil {
 				ss.logger.Error(err.Error())
 			}
 		}
 	}
 
 	ss.logger.Debug("use config:", "config", *ss.opts.Config)
-
-	// Set global log level
-	ss.logLvl = ss.h.GetLogLevel(ss.opts.Config.LogLevel)
+	if ss.applied != ss.opts.Config {
+		ss.applied, ss.appliedAt, ss.appliedCnt = ss.opts.Config, time.Now(), ss.handled.Load()
+		ss.warnInvalidConfig()
+		ss.record()
+		ss.watchers.notify(HistoryEntry{Time: ss.appliedAt, Source: ss.prov.source, Config: *ss.opts.Config})
+	}
+	cfg, quiet := ss.applyQuietHours(ss.applyMaintenance(ss.applyRollout(ss.applyProfile(ss.applyService(*ss.opts.Config)))).WithVerbosity(ss.opts.Verbosity))
+
+	ss.warnUnmatchedRules(cfg.Packages)
+
+	// Set global log level and delivery guarantee.
+	// Without an explicit setting, records are delivered durably only if HandlerOptions.Delivery is given.
+	st := &settings{
+		logLvl:        ss.logLevel(cfg.LogLevel),
+		durable:       ss.isDurableDelivery(cfg.Delivery, ss.opts.Delivery != nil),
+		metadata:      metadataAttrs(cfg.Metadata),
+		normalizer:    newNormalizer(cfg.Normalize),
+		renamer:       newRenamer(cfg.Renames),
+		schemaVersion: cfg.SchemaVersion,
+		generated:     strings.ToLower(cfg.Generated),
+		fallbackScope: cfg.FallbackScope,
+		testPackages:  cfg.TestPackages,
+		buildInfo:     cfg.BuildInfo != nil,
+	}
+	if ss.opts.AnnotateOverrides {
+		st.overrideSession = ss.prov.session
+	}
+	if st.fallbackScope == "" {
+		st.fallbackScope = defaultFallbackScope
+	}
+	if st.buildInfo {
+		st.buildInfoLvl = slog.LevelError
+		if cfg.BuildInfo.LogLevel != "" {
+			st.buildInfoLvl = ss.logLevel(cfg.BuildInfo.LogLevel)
+		}
+	}
+	ss.settings.Store(st)
+
+	var sources map[string]string
+	ss.globalSource, sources = ss.resolveSources(*ss.opts.Config)
+	if quiet {
+		ss.globalSource += " (quiet hours)"
+		for name, source := range sources {
+			sources[name] = source + " (quiet hours)"
+		}
+	}
 
 	ss.pkgMap.Clear()
-	for _, v := range ss.opts.Config.Packages {
+	var patternRules, excludes, files, groups []*pkg
+	for _, v := range ss.activePackages(cfg.Packages) {
+		name, exclude := strings.CutPrefix(v.Name, "!")
 		p := &pkg{
-			name:     v.Name,
-			logLevel: ss.h.GetLogLevel(v.LogLevel),
+			name:        name,
+			logLevel:    ss.h.GetLogLevel(v.LogLevel),
+			durable:     ss.isDurableDelivery(v.Delivery, st.durable),
+			description: v.Description,
+			source:      sources[v.Name],
+			priority:    v.Priority,
+			first:       v.First,
+			runtime:     v.RuntimeMetrics,
+		}
+		if v.MaxLevel != "" {
+			maxLevel := ss.logLevel(v.MaxLevel)
+			p.maxLevel = &maxLevel
+		}
+		if v.AllowedKeys != nil {
+			p.allowedKeys = make(map[string]struct{}, len(v.AllowedKeys))
+			for _, k := range v.AllowedKeys {
+				p.allowedKeys[k] = struct{}{}
+			}
+		}
+		for _, f := range v.Functions {
+			p.functions = append(p.functions, function{name: f.Name, logLevel: ss.logLevel(f.LogLevel)})
+		}
+		for _, r := range v.Receivers {
+			p.receivers = append(p.receivers, function{name: receiverName(r.Name), logLevel: ss.logLevel(r.LogLevel)})
+		}
+		if strings.EqualFold(v.Match, MatchFile) {
+			if exclude {
+				ss.logger.Debug(fmt.Sprintf("exclusions are not supported for files: %q -> ignoring package rule.", v.Name))
+				continue
+			}
+			p.logLevel = ss.logLevel(v.LogLevel)
+			files = append(files, p)
+			continue
 		}
+		if strings.EqualFold(v.Match, MatchGroup) {
+			if exclude {
+				ss.logger.Debug(fmt.Sprintf("exclusions are not supported for groups: %q -> ignoring package rule.", v.Name))
+				continue
+			}
+			p.logLevel = ss.logLevel(v.LogLevel)
+			groups = append(groups, p)
+			continue
+		}
+		if strings.EqualFold(v.Match, MatchRegex) {
+			re, err := compilePackageRegex(name)
+			if err != nil {
+				ss.logger.Debug(fmt.Sprintf("%s -> ignoring package rule.", err.Error()))
+				continue
+			}
+			p.re = re
+		}
+		p.module = strings.EqualFold(v.Match, MatchModule)
+		p.short = strings.EqualFold(v.Match, MatchShort)
+		if exclude {
+			excludes = append(excludes, p)
+			continue
+		}
+		p.logLevel = ss.logLevel(v.LogLevel)
 		ss.pkgMap.Store(p.name, p)
+		if p.isPattern() {
+			patternRules = append(patternRules, p)
+		}
 	}
+	ss.patterns.Store(newPatterns(patternRules, excludes, files, groups))
+	ss.decisions.invalidate()
 
-	if ss.doneCh != nil {
-		close(ss.doneCh)
-		ss.doneCh = nil
+	ss.configureSinks(cfg.Sinks)
+	ss.children.broadcast(ss.effectiveConfig)
+}
+
+// configureSinks replaces the taps of the previously configured sinks with taps for the given sinks.
+func (ss *slogscope) configureSinks(sinks []Sink) {
+	for _, remove := range ss.removeSinks {
+		remove()
+	}
+	ss.removeSinks = nil
+	ss.unavailableSinks = nil
+	created := ss.createdSinks
+	ss.createdSinks = nil
+
+	for _, s := range sinks {
+		h, err := ss.sinkHandler(s, created)
+		if err != nil {
+			ss.unavailableSinks = append(ss.unavailableSinks, fmt.Sprintf("%s: %s", s.Name, err.Error()))
+			continue
+		}
+		lvl := slog.LevelError
+		if s.LogLevel != "" {
+			lvl = ss.logLevel(s.LogLevel)
+		}
+		pkgs := s.Packages
+		if len(pkgs) == 0 {
+			pkgs = []string{""}
+		}
+		for _, p := range pkgs {
+			ss.removeSinks = append(ss.removeSinks, ss.taps.add(&tap{pkg: p, level: lvl, sink: s.Name, h: h}))
+		}
+	}
+	// The warning is emitted only once for the same sinks, not on every reapplied config.
+	if len(ss.unavailableSinks) > 0 {
+		ss.warnOnce("unavailable_sinks:"+strings.Join(ss.unavailableSinks, "; "), "slogscope: unavailable sinks are disabled", "sinks", ss.unavailableSinks)
+	}
+	// Sinks created for the previous config, which aren't used anymore, send their queued records and stop.
+	for key, h := range created {
+		if _, ok := ss.createdSinks[key]; !ok {
+			if c, ok := h.(io.Closer); ok {
+				_ = c.Close()
+			}
+		}
+	}
+}
+
+// sinkHandler returns the handler of the sink given via HandlerOptions.Sinks or, if not present, created by its
+// registered sink type. Handlers contained in created for the same sink definition are reused. The error explains why
+// the sink is unavailable.
+func (ss *slogscope) sinkHandler(s Sink, created map[string]slog.Handler) (slog.Handler, error) {
+	if h, ok := ss.opts.Sinks[s.Name]; ok {
+		return h, nil
+	}
+	if s.Type == "" {
+		return nil, errors.New("no handler given via HandlerOptions.Sinks and no sink type configured")
+	}
+	factory, ok := sinkFactory(s.Type)
+	if !ok {
+		return nil, fmt.Errorf("sink type %q is not registered (registered: %s)", s.Type, strings.Join(registeredSinkTypes(), ", "))
+	}
+	key := fmt.Sprintf("%s\x00%s\x00%v", s.Name, s.Type, s.Options)
+	h, ok := created[key]
+	if !ok {
+		var err error
+		if h, err = factory(s.Options); err != nil {
+			return nil, fmt.Errorf("error creating sink of type %q: %w", s.Type, err)
+		}
+	}
+	if ss.createdSinks == nil {
+		ss.createdSinks = make(map[string]slog.Handler)
+	}
+	ss.createdSinks[key] = h
+	return h, nil
+}
+
+// isDurableDelivery reports whether the given delivery guarantee is DeliveryDurable.
+// It returns fallback for an empty or invalid delivery guarantee.
+func (ss *slogscope) isDurableDelivery(delivery string, fallback bool) bool {
+	switch strings.ToLower(delivery) {
+	case DeliveryDurable:
+		return true
+	case DeliveryBestEffort:
+		return false
+	case "":
+		return fallback
+	}
+	ss.logger.Debug(fmt.Sprintf("invalid delivery guarantee: %q! -> fallback to durable=%t", delivery, fallback))
+	return fallback
+}
+
+// observe increments the number of observed log calls for the given package.
+func (ss *slogscope) observe(pkgName string) {
+	v, ok := ss.observed.Load(pkgName)
+	if !ok {
+		v, _ = ss.observed.LoadOrStore(pkgName, new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(1)
+}
+
+// levelFor returns the configured log level for the given package.
+func (ss *slogscope) levelFor(pkgName string) slog.Level {
+	if p, ok := ss.rule(pkgName); ok {
+		return p.logLevel
+	}
+	return ss.settings.Load().logLvl
+}
+
+// function is the log level override for a function of a package.
+type function struct {
+	name     string
+	logLevel slog.Level
+}
+
+// funcLevel returns the log level of the rule for the given function, which is overridden for the function itself and
+// all closures within it, e.g. "(*Server).handleRequest.func1", or for the receiver type of the method.
+func (p *pkg) funcLevel(funcName string) slog.Level {
+	for _, f := range p.functions {
+		if funcName == f.name || strings.HasPrefix(funcName, f.name+".") {
+			return f.logLevel
+		}
 	}
+	if len(p.receivers) > 0 {
+		if recv := receiverOf(funcName); recv != "" {
+			for _, r := range p.receivers {
+				if recv == r.name {
+					return r.logLevel
+				}
+			}
+		}
+	}
+	return p.logLevel
+}
 
-	if ss.opts.EnableFileWatcher && ss.opts.ConfigFile != "" {
-		ss.doneCh = ss.initConfigFileWatcher()
+// receiverOf returns the receiver type of a method given by its function name without the package, e.g. "Worker" for
+// "(*Worker).Run", "Worker.Run" or "(*Worker[...]).Run.func1", or an empty string for functions and their closures.
+func receiverOf(funcName string) string {
+	recv, method, ok := strings.Cut(funcName, ".")
+	if !ok {
+		return ""
 	}
+	if strings.HasPrefix(recv, "(*") {
+		recv = strings.TrimSuffix(strings.TrimPrefix(recv, "(*"), ")")
+	} else if isClosureName(method) {
+		return ""
+	}
+	recv, _, _ = strings.Cut(recv, "[")
+	return recv
+}
+
+// isClosureName reports whether the name following the function name is generated for a closure, e.g. "func1",
+// "func1.2" or ".func1" of package-level closures.
+func isClosureName(name string) bool {
+	name, _, _ = strings.Cut(name, ".")
+	digits, ok := strings.CutPrefix(name, "func")
+	return name == "" || ok && digits != "" && strings.Trim(digits, "0123456789") == ""
 }
 
+// receiverName returns the receiver type of Package.Receivers, which may be given as "Worker", "*Worker" or
+// "(*Worker)".
+func receiverName(name string) string {
+	return strings.TrimPrefix(strings.Trim(name, "()"), "*")
+}
+
+// loadConfig loads the HandlerOptions.Config from the ConfigSource (see source).
 func (ss *slogscope) loadConfig() *slogscope {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
-	if !checkFileExists(ss.opts.ConfigFile) {
-		ss.logger.Debug(fmt.Sprintf("config file (%s) does not exists! -> file watcher is disabled.", ss.opts.ConfigFile))
+	s := ss.source()
+	cfg, err := s.Load()
+	if err != nil {
+		ss.logger.Debug(err.Error())
 		ss.opts.Config = nil
 		return ss
 	}
+	ss.opts.Config = &cfg
+	ss.prov = sourceProvenance(s)
+	ss.logger.Debug("config loaded.")
+	return ss
+}
 
-	data, err := os.ReadFile(ss.opts.ConfigFile)
+// unmarshalConfig decodes the content of a config file depending on its file extension and migrates it to
+// ConfigVersion (see migrateConfig). Files with the extension .toml or .json are decoded accordingly, all other files
+// are decoded as YAML. Before decoding, the placeholders ${VAR} and ${VAR:default} are replaced (see expandEnv).
+func unmarshalConfig(filename string, data []byte, cfg *Config) error {
+	data = expandEnv(data)
+	var err error
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".toml":
+		err = unmarshalTOML(data, cfg)
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	default:
+		err = unmarshalYAML(data, cfg)
+	}
 	if err != nil {
-		ss.logger.Debug(fmt.Sprintf("error reading config file (%s): %s", ss.opts.ConfigFile, err.Error()))
-		ss.opts.Config = nil
-		return ss
+		return err
 	}
+	return migrateConfig(cfg)
+}
+
+// UnmarshalConfig decodes a Config received by a ConfigSource or ConfigProvider of another package like a config file of the given
+// name (see unmarshalConfig), e.g. "config/slogscope.yml". If name is empty, the format is detected from the content
+// (see NewConfigFromReader).
+func UnmarshalConfig(name string, data []byte) (Config, error) {
 	var cfg Config
-	err = yaml.Unmarshal(data, &cfg)
+	if name == "" {
+		err := decodeConfig(data, &cfg)
+		return cfg, err
+	}
+	err := unmarshalConfig(name, data, &cfg)
+	return cfg, err
+}
+
+// NewConfigFromReader reads a Config in JSON, YAML or TOML format from r (see decodeConfig), e.g. from a config
+// embedded via go:embed or received over the network.
+func NewConfigFromReader(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		ss.logger.Debug(fmt.Sprintf("error unmarshalling config file (%s): %s", ss.opts.ConfigFile, err.Error()))
-		ss.opts.Config = nil
-		return ss
+		return nil, err
 	}
-	ss.opts.Config = &cfg
-	ss.logger.Debug(fmt.Sprintf("config file (%s) loaded.", ss.opts.ConfigFile))
-	return ss
+	var cfg Config
+	if err = decodeConfig(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
 }
 
-// getCallerInfo returns the *callInfo for a caller.
-// It includes the function name, package name, filename, filepath and line number.
-// For convenience, there is also a Source attribute, containing the full filename and line number.
-// As in runtime.Caller, the argument skip is the number of stack frames to ascend,
-// with 0 identifying the caller of Caller.
-func getCallerInfo(skip int) *callInfo {
-	pc, file, lineNo, ok := runtime.Caller(skip)
-	if !ok {
-		return &callInfo{}
+// decodeConfig decodes a config of unknown format and migrates it to ConfigVersion (see migrateConfig).
+// Data starting with "{" is decoded as JSON, all other data as YAML or, if that fails, as TOML.
+func decodeConfig(data []byte, cfg *Config) error {
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, cfg)
+	} else if err = unmarshalYAML(data, cfg); err != nil {
+		*cfg = Config{}
+		if unmarshalTOML(data, cfg) == nil {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
 	}
+	return migrateConfig(cfg)
+}
+
+// marshalConfig encodes the config depending on the file extension of the config file (see unmarshalConfig).
+// The encoded document is always of the current ConfigVersion.
+func marshalConfig(filename string, cfg *Config) ([]byte, error) {
+	versioned := *cfg
+	versioned.Version = ConfigVersion
+	cfg = &versioned
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".toml":
+		return marshalTOML(cfg)
+	case ".json":
+		return json.MarshalIndent(cfg, "", "  ")
+	}
+	return marshalYAML(cfg)
+}
+
+// PackageName returns the package name for a program counter, e.g. slog.Record.PC, like the Handler determines the
+// package of a record. Sink handlers of other packages use it to report the package of a record.
+func PackageName(pc uintptr) string {
+	return getPackageName(pc)
+}
 
-	funcName := runtime.FuncForPC(pc).Name()
-	filename := path.Base(file) // The Base function returns the last element of the path
-	filePath := path.Dir(file)
+// getPackageName returns the package name for a program counter, e.g. slog.Record.PC.
+func getPackageName(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkgName, _ := splitFuncName(frame.Function)
+	return pkgName
+}
 
-	lastSlash := strings.LastIndexByte(funcName, '/')
+// splitFuncName splits a fully qualified function name, as returned by runtime.Func.Name(),
+// into its package name (see normalizePackagePath) and function name.
+func splitFuncName(name string) (string, string) {
+	lastSlash := strings.LastIndexByte(name, '/')
 	if lastSlash < 0 {
 		lastSlash = 0
 	}
-	firstDot := strings.IndexByte(funcName[lastSlash:], '.') + lastSlash
-
-	ci := &callInfo{
-		FuncName:    funcName[firstDot+1:],
-		PackageName: funcName[:firstDot],
-		Filename:    filename,
-		FilePath:    filePath,
-		LineNo:      lineNo,
+	firstDot := strings.IndexByte(name[lastSlash:], '.') + lastSlash
+	if firstDot < lastSlash {
+		return name, ""
 	}
-
-	ci.Source = fmt.Sprintf("%s/%s:%d", ci.FilePath, ci.Filename, ci.LineNo)
-
-	return ci
+	return normalizePackagePath(name[:firstDot]), name[firstDot+1:]
 }
 
 // checkFileExists returns true if a file exists at that location on disk.
@@ -250,28 +702,31 @@ func checkFileExists(filePath string) bool {
 	return !errors.Is(err, os.ErrNotExist)
 }
 
-// createPackageList returns a list of the project package names retrieved via go list command
+// createPackageList returns a list of the project packages for the default config file, retrieved from the build info
+// of the binary instead of the go toolchain, which is usually not available in production: the root package of the
+// main module, the main package and the modules it depends on. Package rules apply to subpackages as well, so the
+// rules of the modules cover all of their packages.
 func (ss *slogscope) createPackageList() []Package {
 	var packages []Package
 
-	cmd := exec.Command("go", "list", "./...")
-	cmdOutput := &bytes.Buffer{}
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
-	if err != nil {
-		ss.logger.Error(err.Error())
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		ss.logger.Debug("build info is not available! -> empty package list.")
 		return packages
 	}
-
-	pkgPaths := strings.Split(cmdOutput.String(), "\n")
-
-	for _, pkgPath := range pkgPaths {
-		if pkgPath != "" {
-			packages = append(packages, Package{
-				Name:     pkgPath,
-				LogLevel: "ERROR",
-			})
+	paths := []string{bi.Main.Path, bi.Path}
+	for _, dep := range bi.Deps {
+		// Packages of replaced modules keep the import path of the module.
+		paths = append(paths, dep.Path)
+	}
+	for _, pkgPath := range paths {
+		if pkgPath == "" || strings.HasSuffix(pkgPath, ".test") || slices.ContainsFunc(packages, func(p Package) bool { return p.Name == pkgPath }) {
+			continue
 		}
+		packages = append(packages, Package{
+			Name:     pkgPath,
+			LogLevel: "ERROR",
+		})
 	}
 
 	return packages
@@ -0,0 +1,47 @@
+// Package slogscopetest provides helpers for tests of applications using slogscope.
+package slogscopetest
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+)
+
+// Mirror passes every record emitted by the handler, i.e. respecting its scoped config, to t.Log while the test
+// runs, so integration tests show the relevant logs inline with their failures without changing the application
+// wiring. Mirroring stops when the test and its subtests have completed.
+func Mirror(t testing.TB, h *slogscope.Handler) {
+	t.Helper()
+	w := &testWriter{t: t}
+	remove := h.Mirror(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.Level(math.MinInt)}))
+	t.Cleanup(func() {
+		remove()
+		w.close()
+	})
+}
+
+// testWriter writes every line to t.Log until it is closed, since logging after the test has completed panics.
+type testWriter struct {
+	mu     sync.Mutex
+	t      testing.TB
+	closed bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.t.Log(string(bytes.TrimSuffix(p, []byte("\n"))))
+	}
+	return len(p), nil
+}
+
+func (w *testWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+}
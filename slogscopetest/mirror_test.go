@@ -0,0 +1,49 @@
+package slogscopetest_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/slogscopetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTB records the messages logged via Log.
+type recordingTB struct {
+	testing.TB
+	logs []string
+}
+
+func (tb *recordingTB) Log(args ...any) {
+	for _, a := range args {
+		tb.logs = append(tb.logs, a.(string))
+	}
+}
+
+func TestMirror(t *testing.T) {
+	var out bytes.Buffer
+	h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{Config: &slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope/slogscopetest_test", LogLevel: slogscope.LogLevelWarn}},
+	}})
+	l := slog.New(h)
+	tb := &recordingTB{}
+
+	t.Run("test emitted records are mirrored", func(t *testing.T) {
+		tb.TB = t
+		slogscopetest.Mirror(tb, h)
+
+		l.Info("filtered message")
+		l.Warn("emitted message", "n", 1)
+		assert.Len(t, tb.logs, 1)
+		assert.Contains(t, tb.logs[0], `msg="emitted message" n=1`)
+		assert.Contains(t, out.String(), "emitted message")
+	})
+
+	t.Run("test mirroring stops after the test", func(t *testing.T) {
+		l.Warn("later message")
+		assert.Len(t, tb.logs, 1)
+	})
+}
@@ -0,0 +1,22 @@
+package slogscopetest
+
+import (
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+)
+
+// FailOnViolation returns a slogscope.HandlerOptions.OnLevelViolation callback, which marks the test as failed for
+// every record logged above the maximum log level of its package rule, instead of panicking in strict mode:
+//
+//	h := slogscope.NewHandler(next, &slogscope.HandlerOptions{
+//		Config:           cfg,
+//		Strict:           true,
+//		OnLevelViolation: slogscopetest.FailOnViolation(t),
+//	})
+func FailOnViolation(t testing.TB) func(slogscope.LevelViolation) {
+	return func(v slogscope.LevelViolation) {
+		t.Helper()
+		t.Error(v.Error())
+	}
+}
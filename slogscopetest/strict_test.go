@@ -0,0 +1,45 @@
+package slogscopetest_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/apperia-de/slogscope/slogscopetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingTB records the errors reported via Error.
+type failingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *failingTB) Helper() {}
+
+func (tb *failingTB) Error(args ...any) {
+	for _, a := range args {
+		tb.errors = append(tb.errors, a.(string))
+	}
+}
+
+func TestFailOnViolation(t *testing.T) {
+	tb := &failingTB{TB: t}
+	h := slogscope.NewHandler(slog.NewTextHandler(io.Discard, nil), &slogscope.HandlerOptions{
+		Config: &slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope/slogscopetest_test", LogLevel: slogscope.LogLevelInfo, MaxLevel: slogscope.LogLevelWarn}},
+		},
+		Strict:           true,
+		OnLevelViolation: slogscopetest.FailOnViolation(tb),
+	})
+	l := slog.New(h)
+
+	l.Warn("allowed message")
+	assert.Empty(t, tb.errors)
+
+	l.Error("forbidden message")
+	assert.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], `logged "forbidden message" at ERROR`)
+}
@@ -0,0 +1,191 @@
+//go:build slogscope_soak
+
+package slogscope_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// Thresholds of the soak test. A run exceeding one of them fails, so performance regressions are caught locally
+// (see make soak).
+const (
+	soakMaxP99Latency     = 100 * time.Microsecond // 99th percentile of the duration of a single log call.
+	soakMinThroughput     = 0.95                   // Minimum share of the target rate, which must be logged.
+	soakMaxGoroutineDelta = 10                     // Maximum number of additional goroutines after the warm-up.
+	soakMaxHeapGrowth     = 16 << 20               // Maximum growth of the live heap after the warm-up in bytes.
+)
+
+// countingHandler counts the handled records.
+type countingHandler struct {
+	handled atomic.Uint64
+}
+
+func (h *countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *countingHandler) Handle(context.Context, slog.Record) error {
+	h.handled.Add(1)
+	return nil
+}
+
+func (h *countingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *countingHandler) WithGroup(string) slog.Handler { return h }
+
+// latencies is a histogram of log call durations with buckets by powers of two nanoseconds, so its size does not
+// depend on the duration of the soak test.
+type latencies struct {
+	buckets [64]atomic.Uint64
+}
+
+func (l *latencies) add(d time.Duration) {
+	l.buckets[bits.Len64(uint64(max(d, 0)))].Add(1)
+}
+
+// percentile returns the upper bound of the bucket containing the given percentile.
+func (l *latencies) percentile(p float64) time.Duration {
+	var total uint64
+	for i := range l.buckets {
+		total += l.buckets[i].Load()
+	}
+	var n uint64
+	for i := range l.buckets {
+		n += l.buckets[i].Load()
+		if float64(n) >= p*float64(total) {
+			return time.Duration(1) << i
+		}
+	}
+	return 0
+}
+
+// soakSetting returns the setting of the environment variable or the default value.
+func soakSetting[T any](t *testing.T, key string, def T, parse func(string) (T, error)) T {
+	s, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	v, err := parse(s)
+	if err != nil {
+		t.Fatalf("invalid %s: %s", key, err.Error())
+	}
+	return v
+}
+
+// TestSoak logs at a constant rate from all CPUs for SLOGSCOPE_SOAK_DURATION (default: 1m), while the config file is
+// reloaded and temporary overrides are applied and reverted continuously. It fails on dropped records and when
+// exceeding the latency, throughput, goroutine or heap thresholds.
+func TestSoak(t *testing.T) {
+	duration := soakSetting(t, "SLOGSCOPE_SOAK_DURATION", time.Minute, time.ParseDuration)
+	rate := soakSetting(t, "SLOGSCOPE_SOAK_RATE", 50_000, strconv.Atoi) // Records per second.
+	warmUp := min(duration/10, time.Minute)
+
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "slogscope.yml")
+	dlq := filepath.Join(dir, "soak.dlq")
+	writeConfig := func(level string) {
+		cfg := fmt.Sprintf("log_level: INFO\npackages:\n  - name: github.com/apperia-de/slogscope_test\n    log_level: %s\n", level)
+		assert.NoError(t, os.WriteFile(cfgFile, []byte(cfg), 0644))
+	}
+	writeConfig(slogscope.LogLevelInfo)
+
+	ch := &countingHandler{}
+	h := slogscope.NewHandler(ch, &slogscope.HandlerOptions{
+		ConfigFile:        cfgFile,
+		EnableFileWatcher: true,
+		Delivery:          &slogscope.DeliveryOptions{QueueSize: 64 << 10, DeadLetterFile: dlq},
+	})
+	l := slog.New(h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	var (
+		wg      sync.WaitGroup
+		logged  atomic.Uint64
+		latency latencies
+	)
+	every := func(d time.Duration, fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(d)
+			defer ticker.Stop()
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					fn(i)
+				}
+			}
+		}()
+	}
+
+	// High log volume from all CPUs at the target rate.
+	loggers := runtime.GOMAXPROCS(0)
+	batch := max(rate/loggers/100, 1)
+	for range loggers {
+		every(10*time.Millisecond, func(int) {
+			for range batch {
+				start := time.Now()
+				l.Info("soak message", "n", 42, "component", "soak")
+				latency.add(time.Since(start))
+				logged.Add(1)
+			}
+		})
+	}
+	// Reloads of the config file and temporary overrides.
+	every(100*time.Millisecond, func(i int) {
+		writeConfig([]string{slogscope.LogLevelInfo, slogscope.LogLevelDebug}[i%2])
+	})
+	every(250*time.Millisecond, func(int) {
+		h.UsePackageLevelTemporarily("github.com/apperia-de/slogscope_test", slogscope.LogLevelDebug, 50*time.Millisecond)
+	})
+
+	// Baseline after the warm-up, e.g. when the delivery queues and caches have been populated.
+	time.Sleep(warmUp)
+	goroutines, heap := soakUsage()
+	t.Logf("baseline after %s: %d goroutines, %d KiB heap", warmUp, goroutines, heap>>10)
+
+	start := time.Now()
+	loggedAtStart := logged.Load()
+	<-ctx.Done()
+	wg.Wait()
+	elapsed := time.Since(start)
+	h.Flush()
+	// Pending reverts of temporary overrides.
+	time.Sleep(100 * time.Millisecond)
+
+	throughput := float64(logged.Load()-loggedAtStart) / elapsed.Seconds()
+	p99 := latency.percentile(0.99)
+	endGoroutines, endHeap := soakUsage()
+	t.Logf("logged %d records (%.0f/s), p50 %s, p99 %s, %d goroutines, %d KiB heap",
+		logged.Load(), throughput, latency.percentile(0.5), p99, endGoroutines, endHeap>>10)
+
+	assert.Equal(t, logged.Load(), ch.handled.Load(), "dropped records")
+	assert.NoFileExists(t, dlq, "dead-lettered records")
+	assert.LessOrEqual(t, p99, soakMaxP99Latency, "p99 latency")
+	assert.GreaterOrEqual(t, throughput, soakMinThroughput*float64(rate), "throughput")
+	assert.LessOrEqual(t, endGoroutines, goroutines+soakMaxGoroutineDelta, "goroutine leak")
+	assert.LessOrEqual(t, endHeap, heap+soakMaxHeapGrowth, "heap growth")
+}
+
+// soakUsage returns the number of goroutines and the size of the live heap.
+func soakUsage() (int, uint64) {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return runtime.NumGoroutine(), ms.HeapAlloc
+}
@@ -0,0 +1,172 @@
+package slogscope
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ConfigSource is the extension point for loading the Config, e.g. from a file, the environment, a URL or a static
+// value. Use it via HandlerOptions.ConfigSource. The Handler loads and watches every config through a ConfigSource,
+// ConfigProviders like FileProvider or HTTPProvider are adapted via SourceOf.
+type ConfigSource interface {
+	// Load returns the current Config of the source.
+	Load() (Config, error)
+	// Watch calls fn with every changed Config of the source and blocks until ctx is done.
+	// Sources, which cannot change or cannot be watched, return immediately.
+	Watch(ctx context.Context, fn func(Config))
+}
+
+// SourceOf returns a ConfigSource for the given ConfigProvider, which is watched if it implements ConfigWatcher.
+func SourceOf(p ConfigProvider) ConfigSource {
+	return &providerSource{provider: p, logger: slog.New(NewNilHandler())}
+}
+
+// NewFileSource returns a ConfigSource for a YAML, JSON or TOML config file (see FileProvider).
+func NewFileSource(filename string) ConfigSource {
+	return SourceOf(NewFileProvider(filename))
+}
+
+// NewFilesSource returns a ConfigSource merging multiple config files, where later files override earlier ones
+// (see MultiFileProvider).
+func NewFilesSource(filenames ...string) ConfigSource {
+	return SourceOf(NewMultiFileProvider(filenames...))
+}
+
+// NewHTTPSource returns a ConfigSource fetching the config from a URL and polling it every interval (see HTTPProvider).
+func NewHTTPSource(url string, interval time.Duration) ConfigSource {
+	return SourceOf(NewHTTPProvider(url, interval))
+}
+
+// NewEnvSource returns a ConfigSource building the Config from environment variables (see NewConfigFromEnv).
+// The environment of a running process does not change, so the source is never watched.
+func NewEnvSource() ConfigSource {
+	return &envSource{}
+}
+
+// NewReaderSource returns a ConfigSource decoding the Config in JSON, YAML or TOML format from r (see
+// NewConfigFromReader). The reader is consumed by the first call of Load, later calls return the same result.
+func NewReaderSource(r io.Reader) ConfigSource {
+	return &readerSource{r: r}
+}
+
+// NewStaticSource returns a ConfigSource, which always returns the given Config, e.g. for tests or as the base of
+// other sources.
+func NewStaticSource(cfg Config) ConfigSource {
+	return &staticSource{cfg: cfg}
+}
+
+// providerSource adapts a ConfigProvider to the ConfigSource interface.
+type providerSource struct {
+	provider ConfigProvider
+	logger   *slog.Logger
+}
+
+func (s *providerSource) Load() (Config, error) {
+	return s.provider.Load()
+}
+
+func (s *providerSource) Watch(ctx context.Context, fn func(Config)) {
+	if ch := s.startWatch(ctx); ch != nil {
+		s.forward(ctx, ch, fn)
+	}
+}
+
+// startWatch starts watching the ConfigProvider until ctx is done and returns the channel of changed configs, or nil
+// if the provider cannot be watched. Unlike Watch, it returns as soon as watching started, so no change made after its
+// return is missed.
+func (s *providerSource) startWatch(ctx context.Context) <-chan Config {
+	w, ok := s.provider.(ConfigWatcher)
+	if !ok {
+		s.logger.Debug("config provider does not support watching! -> file watcher is disabled.")
+		return nil
+	}
+	ch := make(chan Config)
+	if err := w.Watch(ch, ctx.Done()); err != nil {
+		s.logger.Debug(fmt.Sprintf("%s! -> file watcher is disabled.", err.Error()))
+		return nil
+	}
+	return ch
+}
+
+// forward calls fn with every config received from ch until ctx is done.
+func (s *providerSource) forward(ctx context.Context, ch <-chan Config, fn func(Config)) {
+	for {
+		select {
+		case cfg := <-ch:
+			fn(cfg)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *providerSource) provenance() provenance {
+	return provenanceOf(s.provider)
+}
+
+type envSource struct {
+	mu   sync.Mutex
+	prov provenance
+}
+
+func (s *envSource) Load() (Config, error) {
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	s.mu.Lock()
+	s.prov = envProvenance(*cfg)
+	s.mu.Unlock()
+	return *cfg, nil
+}
+
+func (s *envSource) Watch(context.Context, func(Config)) {}
+
+func (s *envSource) provenance() provenance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prov
+}
+
+type staticSource struct {
+	cfg Config
+}
+
+func (s *staticSource) Load() (Config, error) {
+	return s.cfg, nil
+}
+
+func (s *staticSource) Watch(context.Context, func(Config)) {}
+
+func (s *staticSource) provenance() provenance {
+	return provenance{source: "static config"}
+}
+
+type readerSource struct {
+	once sync.Once
+	r    io.Reader
+	cfg  Config
+	err  error
+}
+
+func (s *readerSource) Load() (Config, error) {
+	s.once.Do(func() {
+		cfg, err := NewConfigFromReader(s.r)
+		if err != nil {
+			s.err = fmt.Errorf("error reading config: %w", err)
+			return
+		}
+		s.cfg = *cfg
+	})
+	return s.cfg, s.err
+}
+
+func (s *readerSource) Watch(context.Context, func(Config)) {}
+
+func (s *readerSource) provenance() provenance {
+	return provenance{source: "config reader"}
+}
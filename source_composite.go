@@ -0,0 +1,183 @@
+package slogscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Available merge strategies for NewCompositeSource.
+const (
+	// MergeOverride applies the settings of later sources over those of earlier ones. The package rules of the last
+	// source defining any package rules replace all others.
+	MergeOverride = "override"
+	// MergeUnion applies the settings of later sources over those of earlier ones and combines the package rules of
+	// all sources. Rules for the same package are overridden by later sources (see mergeConfig).
+	MergeUnion = "union"
+	// MergeMostVerbose combines the package rules of all sources like MergeUnion, but the most verbose log level wins
+	// for the global log level and for rules of the same package, regardless of the order of the sources.
+	MergeMostVerbose = "most-verbose"
+)
+
+// CompositeSource is a ConfigSource merging the Configs of multiple sources in ascending order of precedence
+// according to a merge strategy, e.g. a config file, environment variables and a remote source.
+type CompositeSource struct {
+	strategy string
+	sources  []ConfigSource
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	configs []*Config // Last loaded Config by source, nil if the source failed.
+	// watchMu serializes the calls of the Watch callback, so merged Configs are passed on in the order of the changes
+	// without holding mu, which is needed by provenance while the callback blocks.
+	watchMu sync.Mutex
+}
+
+// NewCompositeSource returns a CompositeSource for the given sources and merge strategy, which can be one of
+// MergeOverride, MergeUnion or MergeMostVerbose (default: MergeUnion).
+func NewCompositeSource(strategy string, sources ...ConfigSource) *CompositeSource {
+	switch strategy {
+	case MergeOverride, MergeUnion, MergeMostVerbose:
+	default:
+		strategy = MergeUnion
+	}
+	return &CompositeSource{
+		strategy: strategy,
+		sources:  sources,
+		logger:   slog.New(NewNilHandler()),
+		configs:  make([]*Config, len(sources)),
+	}
+}
+
+// Load loads all sources and merges their Configs. Failing sources are skipped.
+// It returns an error if none of the sources can be loaded.
+func (s *CompositeSource) Load() (Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for i, src := range s.sources {
+		cfg, err := src.Load()
+		if err != nil {
+			s.logger.Debug(err.Error())
+			errs = append(errs, err)
+			s.configs[i] = nil
+			continue
+		}
+		s.configs[i] = &cfg
+	}
+	if len(errs) == len(s.sources) {
+		return Config{}, fmt.Errorf("none of the config sources can be loaded: %w", errors.Join(errs...))
+	}
+	return s.merge(), nil
+}
+
+// Watch watches all sources and calls fn with the merged Config after any of them changed.
+func (s *CompositeSource) Watch(ctx context.Context, fn func(Config)) {
+	for i, src := range s.sources {
+		go src.Watch(ctx, func(cfg Config) {
+			s.watchMu.Lock()
+			defer s.watchMu.Unlock()
+
+			s.mu.Lock()
+			s.configs[i] = &cfg
+			merged := s.merge()
+			s.mu.Unlock()
+			fn(merged)
+		})
+	}
+	<-ctx.Done()
+}
+
+// merge merges the last loaded Configs of all sources according to the merge strategy. The caller must hold s.mu.
+func (s *CompositeSource) merge() Config {
+	var merged Config
+	for _, cfg := range s.configs {
+		if cfg == nil {
+			continue
+		}
+		overlay := *cfg
+		switch s.strategy {
+		case MergeOverride:
+			packages := merged.Packages
+			if len(overlay.Packages) > 0 {
+				packages = overlay.Packages
+			}
+			merged = mergeConfig(merged, overlay)
+			merged.Packages = packages
+		case MergeMostVerbose:
+			level := moreVerbose(merged.LogLevel, overlay.LogLevel)
+			packages := slices.Clone(merged.Packages)
+			for _, p := range overlay.Packages {
+				i := slices.IndexFunc(packages, func(q Package) bool { return q.Name == p.Name })
+				if i < 0 {
+					packages = append(packages, p)
+					continue
+				}
+				if moreVerbose(packages[i].LogLevel, p.LogLevel) == p.LogLevel {
+					packages[i] = p
+				}
+			}
+			merged = mergeConfig(merged, overlay)
+			merged.LogLevel, merged.Packages = level, packages
+		default:
+			merged = mergeConfig(merged, overlay)
+		}
+	}
+	return merged
+}
+
+// moreVerbose returns the more verbose of both log levels, ignoring empty log levels.
+func moreVerbose(a, b string) string {
+	la, _ := parseLogLevel(a)
+	lb, _ := parseLogLevel(b)
+	if a == "" || b != "" && lb < la {
+		return b
+	}
+	return a
+}
+
+// provenance attributes the global log level and every package rule of the merged Config to the source it was taken
+// from, which is the last source with an identical setting.
+func (s *CompositeSource) provenance() provenance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := s.merge()
+	provs := make([]provenance, len(s.sources))
+	var names []string
+	for i, src := range s.sources {
+		if s.configs[i] == nil {
+			continue
+		}
+		provs[i] = sourceProvenance(src)
+		names = append(names, provs[i].source)
+	}
+
+	prov := provenance{source: fmt.Sprintf("composite %s (%s)", s.strategy, strings.Join(names, ", "))}
+	for i := len(s.configs) - 1; i >= 0; i-- {
+		if cfg := s.configs[i]; cfg != nil && cfg.LogLevel != "" && cfg.LogLevel == merged.LogLevel {
+			prov.source = fmt.Sprintf("composite %s: %s", s.strategy, provs[i].source)
+			break
+		}
+	}
+	for _, p := range merged.Packages {
+		for i := len(s.configs) - 1; i >= 0; i-- {
+			if s.configs[i] == nil || !slices.ContainsFunc(s.configs[i].Packages, func(q Package) bool { return reflect.DeepEqual(p, q) }) {
+				continue
+			}
+			source := provs[i].source
+			if src, ok := provs[i].packages[p.Name]; ok {
+				source = src
+			}
+			prov = prov.with(p.Name, source)
+			break
+		}
+	}
+	return prov
+}
@@ -0,0 +1,205 @@
+package slogscope_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// funcSource is a slogscope.ConfigSource sending every config of updates to the watch callback.
+type funcSource struct {
+	cfg     slogscope.Config
+	updates chan slogscope.Config
+}
+
+func (s *funcSource) Load() (slogscope.Config, error) {
+	return s.cfg, nil
+}
+
+func (s *funcSource) Watch(ctx context.Context, fn func(slogscope.Config)) {
+	for {
+		select {
+		case cfg := <-s.updates:
+			fn(cfg)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func TestHandler_ConfigSource(t *testing.T) {
+	t.Run("test custom source is loaded and watched", func(t *testing.T) {
+		s := &funcSource{cfg: oldCfg, updates: make(chan slogscope.Config)}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:      s,
+			EnableFileWatcher: true,
+		})
+		assert.Equal(t, oldCfg, h.GetConfig())
+
+		s.updates <- newCfg
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == newCfg.LogLevel
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("test source takes precedence over provider", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:   slogscope.NewStaticSource(newCfg),
+			ConfigProvider: &memoryProvider{cfg: oldCfg},
+		})
+		assert.Equal(t, newCfg, h.GetConfig())
+		assert.Equal(t, "static config", h.ExplainDecision("github.com/apperia-de/slogscope_test", slog.LevelInfo).Source)
+	})
+
+	t.Run("test env source", func(t *testing.T) {
+		t.Setenv("SLOGSCOPE_LOG_LEVEL", "ERROR")
+		t.Setenv("SLOGSCOPE_PACKAGES", "github.com/foo/bar=DEBUG")
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:      slogscope.NewEnvSource(),
+			EnableFileWatcher: true,
+		})
+		cfg := h.GetConfig()
+		assert.Equal(t, slogscope.LogLevelError, cfg.LogLevel)
+		assert.Equal(t, []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: "DEBUG"}}, cfg.Packages)
+	})
+
+	t.Run("test file source", func(t *testing.T) {
+		requireFullBuild(t)
+		cfg, err := slogscope.NewFileSource("test/data/slogscope.test_config.toml").Load()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, cfg.LogLevel)
+	})
+
+	t.Run("test reader source", func(t *testing.T) {
+		s := slogscope.NewReaderSource(strings.NewReader(`{"log_level": "WARN"}`))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:      s,
+			EnableFileWatcher: true,
+		})
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+
+		// The reader is consumed, but the config is loaded again, e.g. by Handler.Reload.
+		assert.NoError(t, h.Reload())
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+	})
+
+	t.Run("test files source", func(t *testing.T) {
+		dir := t.TempDir()
+		base, overlay := filepath.Join(dir, "base.json"), filepath.Join(dir, "overlay.json")
+		assert.NoError(t, os.WriteFile(base, []byte(`{"log_level": "INFO", "packages": [{"name": "foo", "log_level": "DEBUG"}]}`), 0o644))
+		assert.NoError(t, os.WriteFile(overlay, []byte(`{"log_level": "ERROR"}`), 0o644))
+
+		cfg, err := slogscope.NewFilesSource(base, overlay, filepath.Join(dir, "missing.json")).Load()
+		assert.NoError(t, err)
+		assert.Equal(t, slogscope.LogLevelError, cfg.LogLevel)
+		assert.Equal(t, []slogscope.Package{{Name: "foo", LogLevel: "DEBUG"}}, cfg.Packages)
+	})
+
+	t.Run("test provider adapted via SourceOf", func(t *testing.T) {
+		p := &memoryProvider{cfg: oldCfg, updates: make(chan slogscope.Config)}
+		s := slogscope.SourceOf(p)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		received := make(chan slogscope.Config, 1)
+		go s.Watch(ctx, func(cfg slogscope.Config) { received <- cfg })
+		p.updates <- newCfg
+		assert.Equal(t, newCfg, <-received)
+		cancel()
+	})
+}
+
+func TestCompositeSource(t *testing.T) {
+	base := slogscope.Config{
+		LogLevel: slogscope.LogLevelDebug,
+		Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug},
+			{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelWarn},
+		},
+	}
+	overlay := slogscope.Config{
+		LogLevel: slogscope.LogLevelError,
+		Packages: []slogscope.Package{
+			{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError},
+			{Name: "github.com/foo/qux", LogLevel: slogscope.LogLevelInfo},
+		},
+	}
+
+	tests := []struct {
+		strategy string
+		want     slogscope.Config
+	}{
+		{slogscope.MergeOverride, overlay},
+		{slogscope.MergeUnion, slogscope.Config{
+			LogLevel: slogscope.LogLevelError,
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelError},
+				{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelWarn},
+				{Name: "github.com/foo/qux", LogLevel: slogscope.LogLevelInfo},
+			},
+		}},
+		{slogscope.MergeMostVerbose, slogscope.Config{
+			LogLevel: slogscope.LogLevelDebug,
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelDebug},
+				{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelWarn},
+				{Name: "github.com/foo/qux", LogLevel: slogscope.LogLevelInfo},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			s := slogscope.NewCompositeSource(tt.strategy, slogscope.NewStaticSource(base), slogscope.NewStaticSource(overlay))
+			cfg, err := s.Load()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+
+	t.Run("test failing sources are skipped", func(t *testing.T) {
+		s := slogscope.NewCompositeSource(slogscope.MergeUnion, slogscope.NewFileSource("test/data/does_not_exist.yml"), slogscope.NewStaticSource(base))
+		cfg, err := s.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, base, cfg)
+
+		_, err = slogscope.NewCompositeSource(slogscope.MergeUnion, slogscope.NewFileSource("test/data/does_not_exist.yml")).Load()
+		assert.Error(t, err)
+	})
+
+	t.Run("test change of any source is merged", func(t *testing.T) {
+		remote := &funcSource{cfg: overlay, updates: make(chan slogscope.Config)}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:      slogscope.NewCompositeSource(slogscope.MergeMostVerbose, slogscope.NewStaticSource(base), remote),
+			EnableFileWatcher: true,
+		})
+		assert.Equal(t, slogscope.LogLevelDebug, h.GetConfig().LogLevel)
+		assert.Equal(t, "static config", h.ExplainDecision("github.com/foo/bar", slog.LevelDebug).Source)
+
+		remote.updates <- slogscope.Config{LogLevel: "DEBUG-4", Packages: []slogscope.Package{{Name: "github.com/foo/bar", LogLevel: "DEBUG-4"}}}
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == "DEBUG-4"
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "DEBUG-4", h.GetConfig().Packages[0].LogLevel)
+		assert.Contains(t, h.ExplainDecision("github.com/foo/bar", slog.LevelDebug).Source, "funcSource")
+	})
+
+	t.Run("test bursts of changes are merged without blocking", func(t *testing.T) {
+		remote := &funcSource{cfg: overlay, updates: make(chan slogscope.Config, 5)}
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{
+			ConfigSource:      slogscope.NewCompositeSource(slogscope.MergeOverride, slogscope.NewStaticSource(base), remote),
+			EnableFileWatcher: true,
+		})
+		for _, level := range []string{slogscope.LogLevelInfo, slogscope.LogLevelWarn, slogscope.LogLevelInfo, slogscope.LogLevelWarn, "DEBUG-4"} {
+			remote.updates <- slogscope.Config{LogLevel: level}
+		}
+		assert.Eventually(t, func() bool {
+			return h.GetConfig().LogLevel == "DEBUG-4"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+}
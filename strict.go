@@ -0,0 +1,45 @@
+package slogscope
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// LevelViolation describes a record logged above the maximum log level of its package rule in strict mode (see
+// Package.MaxLevel and HandlerOptions.Strict).
+type LevelViolation struct {
+	Package  string     // Package of the log call.
+	Rule     string     // Name of the package rule declaring the maximum log level.
+	Level    slog.Level // Log level of the record.
+	MaxLevel slog.Level // Maximum log level of the package rule.
+	Message  string     // Message of the record.
+	Source   string     // File and line of the log call, empty if unknown.
+}
+
+func (v LevelViolation) Error() string {
+	msg := fmt.Sprintf("slogscope: package %s logged %q at %s, but rule %q allows at most %s", v.Package, v.Message, v.Level, v.Rule, v.MaxLevel)
+	if v.Source != "" {
+		msg += " (" + v.Source + ")"
+	}
+	return msg
+}
+
+// checkLevel reports a record above the maximum log level of the rule p in strict mode. The rule may be nil.
+func (ss *slogscope) checkLevel(p *pkg, pkgName string, rec slog.Record) {
+	if !ss.opts.Strict || p == nil || p.maxLevel == nil || rec.Level <= *p.maxLevel {
+		return
+	}
+	v := LevelViolation{Package: pkgName, Rule: p.name, Level: rec.Level, MaxLevel: *p.maxLevel, Message: rec.Message}
+	if rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		if frame.File != "" {
+			v.Source = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+	if ss.opts.OnLevelViolation != nil {
+		ss.opts.OnLevelViolation(v)
+		return
+	}
+	panic(v)
+}
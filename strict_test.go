@@ -0,0 +1,60 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Strict(t *testing.T) {
+	newHandler := func(buf *bytes.Buffer, strict bool, onViolation func(slogscope.LevelViolation)) *slogscope.Handler {
+		return slogscope.NewHandler(slog.NewTextHandler(buf, nil), &slogscope.HandlerOptions{
+			Config: &slogscope.Config{
+				LogLevel: slogscope.LogLevelInfo,
+				Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelInfo, MaxLevel: slogscope.LogLevelWarn}},
+			},
+			Strict:           strict,
+			OnLevelViolation: onViolation,
+		})
+	}
+
+	t.Run("test records above the maximum log level panic", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := slog.New(newHandler(&buf, true, nil))
+		assert.NotPanics(t, func() { l.Warn("allowed message") })
+
+		defer func() {
+			v, ok := recover().(slogscope.LevelViolation)
+			assert.True(t, ok)
+			assert.Equal(t, "github.com/apperia-de/slogscope_test", v.Package)
+			assert.Equal(t, "github.com/apperia-de/slogscope_test", v.Rule)
+			assert.Equal(t, slog.LevelError, v.Level)
+			assert.Equal(t, slog.LevelWarn, v.MaxLevel)
+			assert.Equal(t, "forbidden message", v.Message)
+			assert.Contains(t, v.Source, "strict_test.go:")
+			assert.NotContains(t, buf.String(), "forbidden message")
+		}()
+		l.Error("forbidden message")
+	})
+
+	t.Run("test violations are reported to the callback", func(t *testing.T) {
+		var buf bytes.Buffer
+		var violations []slogscope.LevelViolation
+		l := slog.New(newHandler(&buf, true, func(v slogscope.LevelViolation) { violations = append(violations, v) }))
+		l.Error("forbidden message")
+
+		assert.Len(t, violations, 1)
+		assert.Contains(t, violations[0].Error(), `package github.com/apperia-de/slogscope_test logged "forbidden message" at ERROR, but rule "github.com/apperia-de/slogscope_test" allows at most WARN`)
+		assert.Contains(t, buf.String(), "forbidden message")
+	})
+
+	t.Run("test maximum log level is ignored without strict mode", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := slog.New(newHandler(&buf, false, nil))
+		assert.NotPanics(t, func() { l.Error("error message") })
+		assert.Contains(t, buf.String(), "error message")
+	})
+}
@@ -0,0 +1,119 @@
+package slogscope
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// tap receives a copy of every record matching its filter, independent of the configured log levels
+// and without touching the wrapped slog.Handler.
+type tap struct {
+	pkg   string        // Package name or trailing part of a package path (e.g. "pkg/db"). Empty matches all packages.
+	level slog.Level    // Minimum log level of the records.
+	re    stringMatcher // Optional regular expression the record message must match.
+	sink  string        // Name of the sink (see Config.Sinks), empty for other taps.
+	h     slog.Handler
+}
+
+// taps contains all registered taps of a Handler.
+type taps struct {
+	mu  sync.RWMutex
+	m   map[*tap]struct{}
+	cnt atomic.Int32 // Number of registered taps, used as lock-free fast path in Handler.Enabled.
+}
+
+// matches reports whether records of package pkgName at level lvl are of interest for the tap.
+func (t *tap) matches(pkgName string, lvl slog.Level) bool {
+	if lvl < t.level {
+		return false
+	}
+	return t.pkg == "" || pkgName == t.pkg || strings.HasSuffix(pkgName, "/"+t.pkg)
+}
+
+// add registers a tap and returns a function for removing it again.
+func (ts *taps) add(t *tap) func() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.m == nil {
+		ts.m = make(map[*tap]struct{})
+	}
+	ts.m[t] = struct{}{}
+	ts.cnt.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ts.mu.Lock()
+			defer ts.mu.Unlock()
+			delete(ts.m, t)
+			ts.cnt.Add(-1)
+		})
+	}
+}
+
+// enabled reports whether any tap is interested in records of package pkgName at level lvl.
+func (ts *taps) enabled(pkgName string, lvl slog.Level) bool {
+	if ts.cnt.Load() == 0 {
+		return false
+	}
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for t := range ts.m {
+		if t.matches(pkgName, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// sinks returns the sorted names of all sinks interested in records of package pkgName at level lvl.
+func (ts *taps) sinks(pkgName string, lvl slog.Level) []string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	var names []string
+	for t := range ts.m {
+		if t.sink != "" && t.matches(pkgName, lvl) && !slices.Contains(names, t.sink) {
+			names = append(names, t.sink)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// sinkHandlers returns the handlers of all configured sinks by name.
+func (ts *taps) sinkHandlers() map[string]slog.Handler {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	m := make(map[string]slog.Handler)
+	for t := range ts.m {
+		if t.sink != "" {
+			m[t.sink] = t.h
+		}
+	}
+	return m
+}
+
+// handle passes the record to all taps matching it.
+func (ts *taps) handle(ctx context.Context, pkgName string, rec slog.Record) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for t := range ts.m {
+		if t.matches(pkgName, rec.Level) && (t.re == nil || t.re.MatchString(rec.Message)) {
+			_ = t.h.Handle(ctx, rec.Clone())
+		}
+	}
+}
+
+// Mirror passes a copy of every record emitted to the wrapped slog.Handler, i.e. respecting the scoped config, to h
+// as well, e.g. for showing the logs of integration tests inline with their failures (see slogscopetest.Mirror).
+// Attributes and groups added via WithAttrs and WithGroup are not passed to h. The returned function stops
+// mirroring.
+func (h *Handler) Mirror(mirror slog.Handler) (remove func()) {
+	return h.mirrors.add(&tap{level: slog.Level(math.MinInt), h: mirror})
+}
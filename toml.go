@@ -0,0 +1,27 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+)
+
+// marshalTOML returns the TOML encoding of v. Keys are taken from the toml struct tags, which may contain the
+// omitempty option.
+func marshalTOML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalTOML parses the TOML document and stores the result in the value pointed to by v. Keys are matched
+// against the toml struct tags, unknown keys are ignored.
+func unmarshalTOML(data []byte, v any) error {
+	return toml.Unmarshal(data, v)
+}
@@ -0,0 +1,71 @@
+package slogscope_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_TOMLConfigFile(t *testing.T) {
+	requireFullBuild(t)
+
+	t.Run("test config file in TOML format", func(t *testing.T) {
+		buf.Reset()
+		h := setupHandlerWithConfigFile("test/data/slogscope.test_config.toml")
+		cfg := h.GetConfig()
+		assert.Equal(t, slogscope.Config{
+			LogLevel: slogscope.LogLevelInfo,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelError},
+				{Name: "ANOTHER_PACKAGE_NAME_YOU_WANT_TO_OVERRIDE_DEFAULT_LOG_LEVEL", LogLevel: slogscope.LogLevelInfo},
+			},
+		}, cfg)
+
+		l := slog.New(h)
+		l.Info("Info message not printed")
+		l.Error("Error message printed")
+		assert.Equal(t, 0, countLogMessageByLogLevel(buf, slogscope.LogLevelInfo))
+		assert.Equal(t, 1, countLogMessageByLogLevel(buf, slogscope.LogLevelError))
+	})
+
+	t.Run("test config file with inline tables and multi-line strings", func(t *testing.T) {
+		h := setupHandlerWithConfigFile("test/data/slogscope.test_config_inline.toml")
+		assert.Equal(t, slogscope.Config{
+			LogLevel: slogscope.LogLevelDebug,
+			Delivery: slogscope.DeliveryDurable,
+			Packages: []slogscope.Package{
+				{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelWarn},
+				{Name: "github.com/apperia-de/slogscope", LogLevel: slogscope.LogLevelError, Delivery: slogscope.DeliveryBestEffort},
+			},
+			Rollout: &slogscope.Rollout{Percent: 10, Seed: "incident-42"},
+		}, h.GetConfig())
+	})
+
+	t.Run("test exported TOML config can be loaded again", func(t *testing.T) {
+		cfg := slogscope.Config{
+			LogLevel: slogscope.LogLevelWarn,
+			Packages: []slogscope.Package{{Name: "pkg/with \"quotes\"\tand\ttabs", LogLevel: "DEBUG-2"}},
+			Metadata: &slogscope.Metadata{Hostname: true},
+			Sinks:    []slogscope.Sink{{Name: "sentry", LogLevel: slogscope.LogLevelError, Packages: []string{"pkg/db", "pkg/api"}}},
+		}
+		data, err := setupHandlerWithConfig(cfg).ExportConfig(slogscope.FormatTOML)
+		assert.NoError(t, err)
+
+		cfgFile := filepath.Join(t.TempDir(), "slogscope.toml")
+		assert.NoError(t, os.WriteFile(cfgFile, data, 0644))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: cfgFile})
+		assert.Equal(t, cfg, h.GetConfig())
+	})
+
+	t.Run("test invalid TOML config file", func(t *testing.T) {
+		cfgFile := filepath.Join(t.TempDir(), "slogscope.toml")
+		assert.NoError(t, os.WriteFile(cfgFile, []byte("log_level = \"DEBUG"), 0644))
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{ConfigFile: cfgFile})
+		// Invalid config files are ignored and the default config is used instead.
+		assert.Equal(t, slogscope.LogLevelInfo, h.GetConfig().LogLevel)
+	})
+}
@@ -0,0 +1,26 @@
+package slogscope
+
+import "runtime"
+
+// Tuning contains the effective settings of the internal workers, which scale with GOMAXPROCS unless they are
+// overridden via HandlerOptions, e.g. DeliveryOptions.Workers and DeliveryOptions.Shards.
+type Tuning struct {
+	GOMAXPROCS        int `json:"gomaxprocs"`
+	DeliveryWorkers   int `json:"delivery_workers"`
+	DeliveryShards    int `json:"delivery_shards"`
+	DeliveryQueueSize int `json:"delivery_queue_size"` // Total size of all delivery queues, zero for synchronous delivery.
+}
+
+// Tuning returns the effective settings of the internal workers. They are also part of every debug snapshot
+// (see DebugSnapshot).
+func (h *Handler) Tuning() Tuning {
+	t := Tuning{GOMAXPROCS: runtime.GOMAXPROCS(0)}
+	if h.delivery.queues != nil {
+		t.DeliveryWorkers = h.delivery.opts.Workers
+		t.DeliveryShards = len(h.delivery.queues)
+		for _, q := range h.delivery.queues {
+			t.DeliveryQueueSize += cap(q)
+		}
+	}
+	return t
+}
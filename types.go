@@ -1,18 +1,277 @@
 package slogscope
 
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
 type Config struct {
-	LogLevel string    `yaml:"log_level"` // Global log level used as default.
-	Packages []Package `yaml:"packages"`
+	// Version is the schema version of the config document (see ConfigVersion). Documents without a version are
+	// version 1 and migrated automatically when they are loaded. Configs in memory are always of the current version,
+	// so it is only set within encoded documents.
+	Version   int        `yaml:"version,omitempty" json:"version,omitempty" toml:"version,omitzero"`
+	LogLevel  string     `yaml:"log_level" json:"log_level" toml:"log_level"`                            // Global log level used as default.
+	Delivery  string     `yaml:"delivery,omitempty" json:"delivery,omitempty" toml:"delivery,omitempty"` // Default delivery guarantee for all packages ("best-effort" or "durable").
+	Packages  []Package  `yaml:"packages" json:"packages" toml:"packages"`
+	Rollout   *Rollout   `yaml:"rollout,omitempty" json:"rollout,omitempty" toml:"rollout,omitempty"`          // Log level changes for a percentage of all instances.
+	Metadata  *Metadata  `yaml:"metadata,omitempty" json:"metadata,omitempty" toml:"metadata,omitempty"`       // Instance metadata attached to all records.
+	BuildInfo *BuildInfo `yaml:"build_info,omitempty" json:"build_info,omitempty" toml:"build_info,omitempty"` // Build information attached to records at or above a log level.
+	Sinks     []Sink     `yaml:"sinks,omitempty" json:"sinks,omitempty" toml:"sinks,omitempty"`                // Forwarding of records to the sinks of HandlerOptions.Sinks.
+	Normalize *Normalize `yaml:"normalize,omitempty" json:"normalize,omitempty" toml:"normalize,omitempty"`    // Unit normalization of duration and size attributes.
+	// Renames move attributes of records to new keys or into groups, e.g. "user_id" to "user.id", so downstream
+	// consumers requiring renamed fields don't force changes of every call site. They are applied in order.
+	Renames []Rename `yaml:"renames,omitempty" json:"renames,omitempty" toml:"renames,omitempty"`
+	// SchemaVersion is added as attribute schema_version to all records, if set. Changing it together with the
+	// record format, e.g. renamed fields, lets downstream parsers handle format changes across deploys deterministically.
+	SchemaVersion string `yaml:"schema_version,omitempty" json:"schema_version,omitempty" toml:"schema_version,omitempty"`
+	// Generated selects the package name used for records logged from generated code, e.g. *.pb.go or
+	// zz_generated.*.go files (one of GeneratedScopePackage, GeneratedScopeParent or GeneratedScopeDedicated).
+	Generated string `yaml:"generated,omitempty" json:"generated,omitempty" toml:"generated,omitempty"`
+	// FallbackScope is the package name used for records, whose caller can't be resolved to a Go package, e.g. from
+	// cgo callbacks or stripped frames (default: "unknown").
+	FallbackScope string `yaml:"fallback_scope,omitempty" json:"fallback_scope,omitempty" toml:"fallback_scope,omitempty"`
+	// TestPackages applies the rule of a package to its external test package as well, e.g. the rule of
+	// github.com/foo/bar to github.com/foo/bar_test, unless there is a rule for the test package itself.
+	TestPackages bool `yaml:"test_packages,omitempty" json:"test_packages,omitempty" toml:"test_packages,omitempty"`
+	// Profiles contains named configs, e.g. "dev" or "prod". The profile selected by HandlerOptions.Profile is
+	// merged into this config, so profiles only need to contain the settings differing from it.
+	Profiles map[string]Config `yaml:"profiles,omitempty" json:"profiles,omitempty" toml:"profiles,omitempty"`
+	// Services contains sections keyed by binary name or service ID (see HandlerOptions.ServiceID), so several binaries
+	// can share one config file. The section of the running service is merged into this config before the profile.
+	Services map[string]Config `yaml:"services,omitempty" json:"services,omitempty" toml:"services,omitempty"`
+	// Maintenance contains the settings applied while the maintenance mode is active (see Handler.SetMaintenanceMode).
+	// Like a profile, it only needs to contain the settings differing from this config.
+	Maintenance *Config `yaml:"maintenance,omitempty" json:"maintenance,omitempty" toml:"maintenance,omitempty"`
+	// QuietHours raise the log level of all packages during daily time windows, e.g. for batch windows.
+	QuietHours []QuietHours `yaml:"quiet_hours,omitempty" json:"quiet_hours,omitempty" toml:"quiet_hours,omitempty"`
 }
 
 type HandlerOptions struct {
-	Debug             bool
-	Config            *Config
-	ConfigFile        string
+	Debug      bool
+	Config     *Config
+	ConfigFile string
+	// ConfigFiles are config files merged in ascending order of precedence, used instead of ConfigFile if given.
+	//
+	// Deprecated: Use ConfigSource with NewFilesSource instead.
+	ConfigFiles []string
+	// ConfigReader is the source of the Config in JSON, YAML or TOML format, if no Config is given (see
+	// NewConfigFromReader).
+	//
+	// Deprecated: Use ConfigSource with NewReaderSource instead.
+	ConfigReader io.Reader
+	// ConfigProvider is the source of the Config, if no Config is given.
+	//
+	// Deprecated: Use ConfigSource with SourceOf instead.
+	ConfigProvider ConfigProvider
+	// ConfigSource is the source of the Config, if no Config is given (default: the config directory or file given by
+	// ConfigFile). Takes precedence over the deprecated ConfigProvider and ConfigFiles.
+	ConfigSource      ConfigSource
 	EnableFileWatcher bool
+	// ReloadOnSIGHUP reloads the config from the ConfigSource whenever the process receives SIGHUP (see
+	// Handler.Reload), e.g. where the file watcher is disabled or unreliable like on NFS.
+	ReloadOnSIGHUP bool
+	// DebugOnSIGUSR1 raises the global log level to DEBUG for the given duration whenever the process receives SIGUSR1,
+	// if it is greater than zero. SIGUSR2 reverts immediately. Only available on Unix platforms.
+	DebugOnSIGUSR1 time.Duration
+	Delivery       *DeliveryOptions // Enables the at-least-once delivery mode if not nil.
+	InstanceID     string           // Identifies the instance for Config.Rollout (default: hostname).
+	Profile        string           // Name of the active Config.Profiles entry (default: environment variable SLOGSCOPE_PROFILE).
+	ServiceID      string           // Key of the Config.Services section (default: environment variable SLOGSCOPE_SERVICE or the binary name).
+	// ConfigFromEnv builds the Config from environment variables if no Config is given (see NewConfigFromEnv).
+	//
+	// Deprecated: Use ConfigSource with NewEnvSource instead.
+	ConfigFromEnv bool
+	Fingerprint   *FingerprintOptions     // Enables the fingerprint attribute if not nil.
+	Sinks         map[string]slog.Handler // Sink handlers by name, which receive records according to Config.Sinks (see package sink/sentry).
+	HistorySize   int                     // Number of applied configs kept for Handler.Rollback (default: 10, negative disables the history).
+	// Verbosity is the number of verbosity flags of a CLI, e.g. 2 for -vv, which makes the config more verbose
+	// (see Config.WithVerbosity, ParseVerbosity and Verbosity).
+	Verbosity int
+	// DisableConfigFileCreation prevents writing a default ConfigFile populated with the packages of the module, if the
+	// config file does not exist, e.g. in read-only containers. The default Config is used in memory instead.
+	DisableConfigFileCreation bool
+	// PersistChanges writes configs applied at runtime, e.g. via UseConfig or the admin endpoints, back to ConfigFile.
+	// Temporary changes (see UseConfigTemporarily) are not persisted.
+	PersistChanges bool
+	// SessionStore persists the active temporary configs and overrides (see OverrideSession), which are restored by
+	// NewHandler, e.g. NewFileSessionStore("slogscope.state.json"). By default, a restart reverts them.
+	SessionStore SessionStore
+	// DecisionCacheSize is the maximum number of decisions of Enabled cached by call site and log level (default:
+	// 4096). The cache is invalidated whenever the config is applied. A negative size disables the cache.
+	DecisionCacheSize int
+	// WarmUp resolves the functions of all packages matched by the package rules in NewHandler (see Handler.WarmUp).
+	WarmUp bool
+	// AnnotateOverrides adds the attribute slogscope.override with the name of the override session, e.g.
+	// "incident-1234", to all records while a temporary config or override is active (see UseConfigTemporarily), so
+	// log consumers can tell debug-session traffic from normal traffic. Unnamed sessions are called "temporary".
+	AnnotateOverrides bool
+	// Clock returns the wall clock time for scheduled rules, e.g. quiet hours and expiring package rules
+	// (default: time.Now). It is mainly meant for tests simulating clock changes.
+	Clock func() time.Time
+	// Strict enforces Package.MaxLevel: a record logged above the maximum log level of its package rule panics with a
+	// LevelViolation, unless OnLevelViolation is set. It is meant for development and test builds.
+	Strict bool
+	// OnLevelViolation is called instead of panicking in strict mode, e.g. to fail a test (see
+	// slogscopetest.FailOnViolation).
+	OnLevelViolation func(LevelViolation)
 }
 
 type Package struct {
-	Name     string `yaml:"name"`
-	LogLevel string `yaml:"log_level"`
+	// Name is the package name, which applies to its subpackages as well, or a pattern (see Package.Match).
+	// A name prefixed with "!" excludes the matching packages from all less specific rules.
+	Name     string `yaml:"name" json:"name" toml:"name"`
+	LogLevel string `yaml:"log_level" json:"log_level" toml:"log_level"`
+	Delivery string `yaml:"delivery,omitempty" json:"delivery,omitempty" toml:"delivery,omitempty"` // Overrides Config.Delivery for this package.
+	// Expires is the date (e.g. 2025-08-01) or RFC 3339 timestamp, after which the rule is ignored.
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty" toml:"expires,omitempty"`
+	// Description explains why the rule exists, e.g. "silenced due to issue #123".
+	Description string `yaml:"description,omitempty" json:"description,omitempty" toml:"description,omitempty"`
+	// Match is the matching mode of Name, MatchGlob (default), MatchRegex, MatchModule, MatchShort, MatchFile
+	// or MatchGroup.
+	Match string `yaml:"match,omitempty" json:"match,omitempty" toml:"match,omitempty"`
+	// Priority decides between multiple rules matching a package, e.g. overlapping patterns. The rule with the highest
+	// priority wins, rules of the same priority are ordered by specificity (see Handler.ExplainMatch).
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty" toml:"priority,omitzero"`
+	// First is the number of records of every distinct message, which are emitted regardless of LogLevel before the
+	// normal filtering applies, e.g. to catch rare details of the startup path without permanent verbosity.
+	First int `yaml:"first,omitempty" json:"first,omitempty" toml:"first,omitzero"`
+	// AllowedKeys restricts the attribute keys of the records of the package, e.g. to protect the index cardinality of
+	// log consumers from raw maps logged in a hot path. Other attributes are dropped and counted (see
+	// Stats.DroppedAttrs). Only the attributes of the log call are filtered, not those added via slog.Logger.With.
+	AllowedKeys []string `yaml:"allowed_keys,omitempty" json:"allowed_keys,omitempty" toml:"allowed_keys,omitempty"`
+	// RuntimeMetrics attaches a snapshot of the Go runtime (goroutines, heap and the last GC pause) within the group
+	// "runtime" to ERROR records of the package, e.g. to correlate errors with resource pressure. The snapshot is taken
+	// at most once per second.
+	RuntimeMetrics bool `yaml:"runtime_metrics,omitempty" json:"runtime_metrics,omitempty" toml:"runtime_metrics,omitempty"`
+	// MaxLevel is the highest log level the package may log at, e.g. WARN for library packages, which should return
+	// errors instead of logging them. It is only enforced in strict mode (see HandlerOptions.Strict).
+	MaxLevel string `yaml:"max_level,omitempty" json:"max_level,omitempty" toml:"max_level,omitempty"`
+	// Functions overrides the log level for single functions of the package, e.g. a hot request handler.
+	Functions []Function `yaml:"functions,omitempty" json:"functions,omitempty" toml:"functions,omitempty"`
+	// Receivers overrides the log level for all methods of receiver types of the package, e.g. "Worker" for the methods
+	// of Worker and *Worker, when several components share a package. Functions take precedence over receivers.
+	Receivers []Function `yaml:"receivers,omitempty" json:"receivers,omitempty" toml:"receivers,omitempty"`
+}
+
+// QuietHours raises the global log level and the log levels of all package rules to at least LogLevel during a daily
+// time window, e.g. to suppress DEBUG and INFO records from 00:00 to 06:00.
+type QuietHours struct {
+	From string `yaml:"from" json:"from" toml:"from"` // Start of the window, e.g. "00:00".
+	// To is the end of the window (exclusive), e.g. "06:00". Windows may span midnight, e.g. from 22:00 to 02:00.
+	To       string `yaml:"to" json:"to" toml:"to"`
+	LogLevel string `yaml:"log_level,omitempty" json:"log_level,omitempty" toml:"log_level,omitempty"` // Minimum log level during the window (default: WARN).
+	Location string `yaml:"location,omitempty" json:"location,omitempty" toml:"location,omitempty"`    // IANA time zone, e.g. "Europe/Berlin" (default: local time).
+}
+
+// Function overrides the log level of a package rule for a function of the package.
+type Function struct {
+	// Name is the function name without the package, e.g. "handleRequest" or "(*Server).handleRequest" for methods.
+	// The log level applies to closures within the function as well.
+	Name     string `yaml:"name" json:"name" toml:"name"`
+	LogLevel string `yaml:"log_level" json:"log_level" toml:"log_level"`
+}
+
+// Rollout contains log level changes, which only apply to Percent of all instances of a fleet.
+// Instances are selected by a hash of their HandlerOptions.InstanceID, so debug verbosity can be sampled
+// across a fleet without overwhelming the log aggregation.
+type Rollout struct {
+	Percent  int       `yaml:"percent" json:"percent" toml:"percent"`                                     // Percentage of selected instances (0-100).
+	Seed     string    `yaml:"seed,omitempty" json:"seed,omitempty" toml:"seed,omitempty"`                // Changing the seed selects different instances.
+	LogLevel string    `yaml:"log_level,omitempty" json:"log_level,omitempty" toml:"log_level,omitempty"` // Overrides Config.LogLevel.
+	Packages []Package `yaml:"packages,omitempty" json:"packages,omitempty" toml:"packages,omitempty"`    // Overrides or extends Config.Packages.
+}
+
+// Metadata toggles the instance metadata attributes, which are attached to all records within the "instance" group.
+type Metadata struct {
+	Hostname     bool `yaml:"hostname,omitempty" json:"hostname,omitempty" toml:"hostname,omitempty"`
+	PodName      bool `yaml:"pod_name,omitempty" json:"pod_name,omitempty" toml:"pod_name,omitempty"`                // From POD_NAME or the hostname within Kubernetes.
+	ContainerID  bool `yaml:"container_id,omitempty" json:"container_id,omitempty" toml:"container_id,omitempty"`    // From /proc/self/cgroup or /proc/self/mountinfo.
+	BuildVersion bool `yaml:"build_version,omitempty" json:"build_version,omitempty" toml:"build_version,omitempty"` // Main module version from the build info.
+}
+
+// BuildInfo enables the "build" group containing the VCS revision, the dirty flag, the Go version and the main module
+// version for all records at or above LogLevel, e.g. for ERROR records shipped to ticketing systems.
+type BuildInfo struct {
+	LogLevel string `yaml:"log_level" json:"log_level" toml:"log_level"` // Minimum log level of the records (default: ERROR).
+}
+
+// Rename moves the attribute From to To like the move operation of JSON Patch (RFC 6902). Both are paths of keys
+// separated by dots, where a dot descends into a group, e.g. "http.status" for the attribute status within the group
+// http. Missing groups of To are created and an existing attribute at To is replaced. Records without the attribute
+// From are left unchanged.
+type Rename struct {
+	From string `yaml:"from" json:"from" toml:"from"`
+	To   string `yaml:"to" json:"to" toml:"to"`
+}
+
+// Normalize converts duration and byte size attributes into consistent units, e.g. "latency" with 1.5s into
+// "latency_ms" with 1500, so dashboards don't have to parse different representations across packages.
+type Normalize struct {
+	// DurationUnit is the unit of all time.Duration attributes, one of "ns", "us", "ms" or "s". The unit is appended
+	// to the key, e.g. "latency_ms".
+	DurationUnit string `yaml:"duration_unit,omitempty" json:"duration_unit,omitempty" toml:"duration_unit,omitempty"`
+	// SizeKeys are the keys of byte size attributes, given as numbers or strings like "1.5MB" or "512KiB", which are
+	// converted into the number of bytes with the key suffix "_bytes", e.g. "body_size_bytes".
+	SizeKeys []string `yaml:"size_keys,omitempty" json:"size_keys,omitempty" toml:"size_keys,omitempty"`
+	// Precision is the maximum number of decimal places of normalized durations (default: 0).
+	Precision int `yaml:"precision,omitempty" json:"precision,omitempty" toml:"precision,omitzero"`
+}
+
+// Sink forwards all records of the given packages at or above LogLevel to the sink handler registered under Name
+// in HandlerOptions.Sinks, in addition to the wrapped slog.Handler and independent of the package log levels.
+type Sink struct {
+	Name string `yaml:"name" json:"name" toml:"name"`
+	// Type creates the sink handler via a registered sink type (see RegisterSinkType), e.g. "sentry" or "webhook",
+	// if HandlerOptions.Sinks contains no handler of the same name.
+	Type     string            `yaml:"type,omitempty" json:"type,omitempty" toml:"type,omitempty"`
+	Options  map[string]string `yaml:"options,omitempty" json:"options,omitempty" toml:"options,omitempty"`    // Options of the sink type, e.g. "dsn".
+	LogLevel string            `yaml:"log_level" json:"log_level" toml:"log_level"`                            // Minimum log level of the records (default: ERROR).
+	Packages []string          `yaml:"packages,omitempty" json:"packages,omitempty" toml:"packages,omitempty"` // Package names or trailing parts of package paths (default: all packages).
+}
+
+// FingerprintOptions configure the fingerprint attribute, which is a stable hash of the package name, the message
+// template and the error type of a record. Records with the same fingerprint can be aggregated by downstream systems.
+type FingerprintOptions struct {
+	Level slog.Leveler // Minimum log level of records getting a fingerprint (default: WARN).
+	// Template extracts the message template from a log message (default: MessageTemplate).
+	Template func(msg string) string
+}
+
+// DeliveryOptions configures the at-least-once delivery mode of the Handler.
+// Records the wrapped slog.Handler fails to handle are retried and, if they are still undeliverable,
+// appended to the DeadLetterFile instead of being silently dropped.
+type DeliveryOptions struct {
+	MaxRetries     int           // Number of retries after the first failed attempt (default: 3).
+	RetryInterval  time.Duration // Wait time before the first retry, doubled on every further retry (default: 100ms).
+	DeadLetterFile string        // File for undeliverable records in JSON lines format (default: slogscope.dlq).
+	QueueSize      int           // If greater than zero, records are delivered asynchronously using a queue of that size.
+	Workers        int           // Number of workers delivering queued records (default: GOMAXPROCS).
+	// Shards is the number of queues, which records are distributed to by their package name (default: Workers).
+	// With a single worker per shard, records of the same package are delivered in order.
+	Shards int
+	// FlushOnSignal flushes the queue as soon as the process receives SIGINT or SIGTERM and raises the signal again
+	// afterwards, so the process still terminates. Applications handling these signals themselves should call
+	// Handler.Flush on shutdown instead.
+	FlushOnSignal bool
+	// FinalFlushTimeout bounds the flush on crash paths (see Handler.FlushOnPanic and FlushOnSignal), so a crashing
+	// process doesn't hang on an unavailable handler (default: 2s).
+	FinalFlushTimeout time.Duration
+	// RespectDeadline bounds synchronous deliveries by the deadline of the context passed to Handle, so logging
+	// doesn't extend the latency of a request beyond its budget. Retries, which wouldn't end in time, are skipped and
+	// the record is written to the dead letter file instead. An attempt still running at the deadline continues in
+	// the background and dead-letters the record on failure. Cut short deliveries are counted in
+	// Stats.DeadlineExceeded.
+	RespectDeadline bool
+}
+
+// PackageInfo describes a configured or observed package together with its effective log level.
+type PackageInfo struct {
+	Name     string `json:"name"`
+	LogLevel string `json:"log_level"`
+	Observed uint64 `json:"observed"` // Number of log calls observed from the package since the Handler was created.
+	// Description of the package rule (see Package.Description).
+	Description string `json:"description,omitempty"`
+	// Source of the package rule, e.g. "file slogscope.yml:12" or "admin POST /overrides from 10.0.0.1:51234".
+	Source string `json:"source,omitempty"`
 }
@@ -0,0 +1,345 @@
+package slogscope
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// ValidationError describes a single problem of a Config.
+type ValidationError struct {
+	Field   string `json:"field"` // Path of the setting, e.g. "packages[2].log_level".
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors contains all problems found by Config.Validate or ValidateConfigFile.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid config (%d problems): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Validate checks the Config for invalid log levels and delivery guarantees, package rules without or with
+// duplicate names, invalid expiry dates and rollout percentages. It returns nil or ValidationErrors.
+func (c Config) Validate() error {
+	var errs ValidationErrors
+	c.validate("", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c Config) validate(prefix string, errs *ValidationErrors) {
+	add := func(field, format string, args ...any) {
+		*errs = append(*errs, ValidationError{Field: prefix + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Version < 0 || c.Version > ConfigVersion {
+		add("version", "unsupported config version %d: expected 1 to %d", c.Version, ConfigVersion)
+	}
+	validateLogLevel(prefix+"log_level", c.LogLevel, errs)
+	validateDelivery(prefix+"delivery", c.Delivery, errs)
+	validatePackages(prefix+"packages", c.Packages, errs)
+	switch strings.ToLower(c.Generated) {
+	case "", GeneratedScopePackage, GeneratedScopeParent, GeneratedScopeDedicated:
+	default:
+		add("generated", "invalid scope %q: expected %q, %q or %q", c.Generated, GeneratedScopePackage, GeneratedScopeParent, GeneratedScopeDedicated)
+	}
+	if c.Rollout != nil {
+		if c.Rollout.Percent < 0 || c.Rollout.Percent > 100 {
+			add("rollout.percent", "must be between 0 and 100, got %d", c.Rollout.Percent)
+		}
+		validateLogLevel(prefix+"rollout.log_level", c.Rollout.LogLevel, errs)
+		validatePackages(prefix+"rollout.packages", c.Rollout.Packages, errs)
+	}
+	if c.BuildInfo != nil {
+		validateLogLevel(prefix+"build_info.log_level", c.BuildInfo.LogLevel, errs)
+	}
+	if n := c.Normalize; n != nil {
+		if _, ok := durationUnits[n.DurationUnit]; n.DurationUnit != "" && !ok {
+			add("normalize.duration_unit", "invalid unit %q: expected \"ns\", \"us\", \"ms\" or \"s\"", n.DurationUnit)
+		}
+		if n.Precision < 0 || n.Precision > maxNormalizePrecision {
+			add("normalize.precision", "must be between 0 and %d, got %d", maxNormalizePrecision, n.Precision)
+		}
+		for i, k := range n.SizeKeys {
+			if k == "" {
+				add(fmt.Sprintf("normalize.size_keys[%d]", i), "must not be empty")
+			}
+		}
+	}
+	for i, r := range c.Renames {
+		field := fmt.Sprintf("renames[%d]", i)
+		if !validAttrPath(r.From) {
+			add(field+".from", "invalid attribute path %q", r.From)
+		}
+		if !validAttrPath(r.To) {
+			add(field+".to", "invalid attribute path %q", r.To)
+		}
+		if r.From == r.To {
+			add(field, "from and to must differ, got %q", r.From)
+		}
+	}
+	for i, s := range c.Sinks {
+		field := fmt.Sprintf("sinks[%d]", i)
+		if s.Name == "" {
+			add(field+".name", "must not be empty")
+		}
+		validateLogLevel(prefix+field+".log_level", s.LogLevel, errs)
+		if _, ok := sinkFactory(s.Type); s.Type != "" && !ok {
+			add(field+".type", "unknown sink type %q (registered: %s): import the package registering it", s.Type, strings.Join(registeredSinkTypes(), ", "))
+		}
+	}
+	for i, q := range c.QuietHours {
+		field := fmt.Sprintf("quiet_hours[%d]", i)
+		if _, _, _, err := q.window(); err != nil {
+			add(field, "%s", err.Error())
+		}
+		validateLogLevel(prefix+field+".log_level", q.LogLevel, errs)
+	}
+	if m := c.Maintenance; m != nil {
+		if len(m.Profiles) > 0 || m.Maintenance != nil {
+			add("maintenance", "must not contain profiles or a maintenance section")
+		}
+		sub := *m
+		sub.Profiles, sub.Maintenance = nil, nil
+		sub.validate(prefix+"maintenance.", errs)
+	}
+	for id, s := range c.Services {
+		if len(s.Services) > 0 || len(s.Profiles) > 0 {
+			add(fmt.Sprintf("services.%s", id), "must not contain services or profiles")
+		}
+		s.Services, s.Profiles = nil, nil
+		s.validate(fmt.Sprintf("%sservices.%s.", prefix, id), errs)
+	}
+	for name, p := range c.Profiles {
+		if len(p.Profiles) > 0 {
+			add(fmt.Sprintf("profiles.%s.profiles", name), "profiles must not be nested")
+		}
+		p.Profiles = nil
+		p.validate(fmt.Sprintf("%sprofiles.%s.", prefix, name), errs)
+	}
+}
+
+// validateFunctions adds errors for function or receiver overrides without name or log level.
+func validateFunctions(field string, functions []Function, errs *ValidationErrors) {
+	for i, f := range functions {
+		fnField := fmt.Sprintf("%s[%d]", field, i)
+		if f.Name == "" {
+			*errs = append(*errs, ValidationError{Field: fnField + ".name", Message: "must not be empty"})
+		}
+		if f.LogLevel == "" {
+			*errs = append(*errs, ValidationError{Field: fnField + ".log_level", Message: "must not be empty"})
+		}
+		validateLogLevel(fnField+".log_level", f.LogLevel, errs)
+	}
+}
+
+// validateLogLevel adds an error if the log level is neither empty nor accepted by Handler.GetLogLevel.
+func validateLogLevel(field, level string, errs *ValidationErrors) {
+	if _, ok := parseLogLevel(level); level != "" && !ok {
+		*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf("invalid log level %q: expected DEBUG, INFO, WARN or ERROR with an optional offset, e.g. DEBUG-2", level)})
+	}
+}
+
+// validateDelivery adds an error if the delivery guarantee is neither empty, DeliveryBestEffort nor DeliveryDurable.
+func validateDelivery(field, delivery string, errs *ValidationErrors) {
+	switch strings.ToLower(delivery) {
+	case "", DeliveryBestEffort, DeliveryDurable:
+		return
+	}
+	*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf("invalid delivery guarantee %q: expected %q or %q", delivery, DeliveryBestEffort, DeliveryDurable)})
+}
+
+// validatePackages adds errors for package rules without or with duplicate names and invalid settings.
+func validatePackages(field string, packages []Package, errs *ValidationErrors) {
+	seen := make(map[string]int, len(packages))
+	for i, p := range packages {
+		pkgField := fmt.Sprintf("%s[%d]", field, i)
+		switch j, ok := seen[p.Name]; {
+		case p.Name == "":
+			*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: "must not be empty"})
+		case ok:
+			*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("duplicate package %q (see %s[%d])", p.Name, field, j)})
+		default:
+			seen[p.Name] = i
+		}
+		name, exclude := strings.CutPrefix(p.Name, "!")
+		switch strings.ToLower(p.Match) {
+		case "", MatchGlob:
+			if _, err := path.Match(name, ""); isPattern(name) && err != nil {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("invalid package pattern %q: %s", name, err.Error())})
+			}
+		case MatchRegex:
+			if _, err := compilePackageRegex(name); err != nil {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: err.Error()})
+			}
+		case MatchModule:
+			if isPattern(name) {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("invalid module path %q: must not contain wildcards", name)})
+			}
+		case MatchShort:
+			if strings.Contains(name, "/") || isPattern(name) {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("invalid short name %q: must be a single path element without wildcards", name)})
+			}
+		case MatchFile:
+			if _, err := path.Match(name, ""); err != nil {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("invalid file pattern %q: %s", name, err.Error())})
+			}
+			if exclude {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: "exclusions are not supported for files"})
+			}
+		case MatchGroup:
+			if isPattern(name) {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: fmt.Sprintf("invalid group %q: must not contain wildcards", name)})
+			}
+			if exclude {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: "exclusions are not supported for groups"})
+			}
+		default:
+			*errs = append(*errs, ValidationError{Field: pkgField + ".match", Message: fmt.Sprintf("invalid matching mode %q: expected %q, %q, %q, %q, %q or %q", p.Match, MatchGlob, MatchRegex, MatchModule, MatchShort, MatchFile, MatchGroup)})
+		}
+		if exclude && name == "" {
+			*errs = append(*errs, ValidationError{Field: pkgField + ".name", Message: "must not be empty after \"!\""})
+		}
+		// Exclusions have no log level of their own.
+		if p.LogLevel == "" && !exclude {
+			*errs = append(*errs, ValidationError{Field: pkgField + ".log_level", Message: "must not be empty"})
+		}
+		validateLogLevel(pkgField+".log_level", p.LogLevel, errs)
+		validateLogLevel(pkgField+".max_level", p.MaxLevel, errs)
+		validateDelivery(pkgField+".delivery", p.Delivery, errs)
+		validateFunctions(pkgField+".functions", p.Functions, errs)
+		validateFunctions(pkgField+".receivers", p.Receivers, errs)
+		for j, k := range p.AllowedKeys {
+			if k == "" {
+				*errs = append(*errs, ValidationError{Field: fmt.Sprintf("%s.allowed_keys[%d]", pkgField, j), Message: "must not be empty"})
+			}
+		}
+		if p.First < 0 {
+			*errs = append(*errs, ValidationError{Field: pkgField + ".first", Message: fmt.Sprintf("must not be negative, got %d", p.First)})
+		}
+		if p.Expires != "" {
+			if _, err := parseExpires(p.Expires); err != nil {
+				*errs = append(*errs, ValidationError{Field: pkgField + ".expires", Message: err.Error()})
+			}
+		}
+	}
+}
+
+// ValidateConfigFile reads the config file and reports unknown keys in addition to the problems found by
+// Config.Validate. It returns nil, ValidationErrors or an error reading or decoding the file.
+func ValidateConfigFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var cfg Config
+	if err = unmarshalConfig(filename, data, &cfg); err != nil {
+		return err
+	}
+	errs := unknownKeys(filename, data)
+	var invalid ValidationErrors
+	if errors.As(cfg.Validate(), &invalid) {
+		errs = append(errs, invalid...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// unknownKeys returns an error for every key of the config file, which is not a setting of the Config.
+func unknownKeys(filename string, data []byte) ValidationErrors {
+	var raw map[string]any
+	if strings.ToLower(path.Ext(filename)) == ".toml" {
+		if unmarshalTOML(data, &raw) != nil {
+			return nil
+		}
+	} else if unmarshalYAML(data, &raw) != nil {
+		// JSON is decoded as YAML as well.
+		return nil
+	}
+	var errs ValidationErrors
+	collectUnknownKeys(raw, reflect.TypeOf(Config{}), "", &errs)
+	return errs
+}
+
+// collectUnknownKeys compares the decoded value v with the type t and adds an error for every unknown key.
+func collectUnknownKeys(v any, t reflect.Type, field string, errs *ValidationErrors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			fields[name] = t.Field(i).Type
+		}
+		for key, value := range m {
+			keyField := strings.TrimPrefix(field+"."+key, ".")
+			ft, ok := fields[key]
+			if !ok {
+				*errs = append(*errs, ValidationError{Field: keyField, Message: "unknown key"})
+				continue
+			}
+			collectUnknownKeys(value, ft, keyField, errs)
+		}
+	case reflect.Slice:
+		var items []any
+		switch arr := v.(type) {
+		case []any:
+			items = arr
+		case []map[string]any:
+			for _, m := range arr {
+				items = append(items, m)
+			}
+		}
+		for i, item := range items {
+			collectUnknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", field, i), errs)
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]any); ok {
+			for key, value := range m {
+				collectUnknownKeys(value, t.Elem(), field+"."+key, errs)
+			}
+		}
+	}
+}
+
+// warnInvalidConfig emits a warning via the wrapped slog.Handler, listing all problems of the current config and
+// of the data it was decoded from. The config is applied nevertheless, invalid settings fall back to their defaults.
+// The caller must hold ss.mu.
+func (ss *slogscope) warnInvalidConfig() {
+	errs := ss.prov.problems
+	var invalid ValidationErrors
+	if errors.As(ss.opts.Config.Validate(), &invalid) {
+		errs = append(errs[:len(errs):len(errs)], invalid...)
+	}
+	if len(errs) == 0 {
+		return
+	}
+	problems := make([]string, len(errs))
+	for i, err := range errs {
+		problems[i] = err.Error()
+	}
+	slog.New(ss.slogh).Warn("slogscope: invalid config", "source", ss.prov.source, "problems", problems)
+}
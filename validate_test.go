@@ -0,0 +1,105 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("test valid configs", func(t *testing.T) {
+		requireFullBuild(t)
+
+		assert.NoError(t, oldCfg.Validate())
+		assert.NoError(t, newCfg.Validate())
+		for _, file := range []string{"slogscope.test_config.yml", "slogscope.test_config.toml", "slogscope.test_config_inline.toml"} {
+			assert.NoError(t, slogscope.ValidateConfigFile(filepath.Join("test/data", file)), file)
+		}
+	})
+
+	t.Run("test all problems are reported", func(t *testing.T) {
+		cfg := slogscope.Config{
+			LogLevel: "VERBOSE",
+			Delivery: "sometimes",
+			Packages: []slogscope.Package{
+				{Name: "github.com/foo/bar", LogLevel: "debug-2"},
+				{Name: "", LogLevel: slogscope.LogLevelInfo},
+				{Name: "github.com/foo/bar", LogLevel: slogscope.LogLevelWarn, Expires: "tomorrow"},
+				{Name: "github.com/foo/baz", LogLevel: slogscope.LogLevelWarn, MaxLevel: "FATAL"},
+			},
+			Rollout:  &slogscope.Rollout{Percent: 120},
+			Profiles: map[string]slogscope.Config{"dev": {LogLevel: "TRACE"}},
+		}
+		var errs slogscope.ValidationErrors
+		assert.True(t, errors.As(cfg.Validate(), &errs))
+
+		fields := make([]string, len(errs))
+		for i, err := range errs {
+			fields[i] = err.Field
+		}
+		assert.ElementsMatch(t, []string{
+			"log_level",
+			"delivery",
+			"packages[1].name",
+			"packages[2].name",
+			"packages[2].expires",
+			"packages[3].max_level",
+			"rollout.percent",
+			"profiles.dev.log_level",
+		}, fields)
+		assert.Contains(t, errs.Error(), `duplicate package "github.com/foo/bar" (see packages[0])`)
+	})
+
+	t.Run("test unknown keys in config files", func(t *testing.T) {
+		requireFullBuild(t)
+
+		dir := t.TempDir()
+		yml, toml := filepath.Join(dir, "slogscope.yml"), filepath.Join(dir, "slogscope.toml")
+		assert.NoError(t, os.WriteFile(yml, []byte("log_level: INFO\nloglevel: DEBUG\npackages:\n  - name: github.com/foo/bar\n    level: DEBUG\n    log_level: WARN\n"), 0644))
+		assert.NoError(t, os.WriteFile(toml, []byte("log_level = \"INFO\"\n\n[[packages]]\nname = \"github.com/foo/bar\"\nlog_level = \"WARN\"\ncomment = \"x\"\n"), 0644))
+
+		var errs slogscope.ValidationErrors
+		assert.True(t, errors.As(slogscope.ValidateConfigFile(yml), &errs))
+		assert.ElementsMatch(t, slogscope.ValidationErrors{
+			{Field: "loglevel", Message: "unknown key"},
+			{Field: "packages[0].level", Message: "unknown key"},
+		}, errs)
+
+		assert.True(t, errors.As(slogscope.ValidateConfigFile(toml), &errs))
+		assert.Equal(t, slogscope.ValidationErrors{{Field: "packages[0].comment", Message: "unknown key"}}, errs)
+	})
+
+	t.Run("test invalid config is applied with a warning", func(t *testing.T) {
+		requireFullBuild(t)
+
+		file := filepath.Join(t.TempDir(), "slogscope.yml")
+		assert.NoError(t, os.WriteFile(file, []byte("log_level: WARN\nlog_levle: DEBUG\n"), 0644))
+
+		var out bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&out, nil), &slogscope.HandlerOptions{ConfigFile: file})
+		assert.Equal(t, slogscope.LogLevelWarn, h.GetConfig().LogLevel)
+		assert.Contains(t, out.String(), `msg="slogscope: invalid config"`)
+		assert.Contains(t, out.String(), "log_levle: unknown key")
+	})
+
+	t.Run("test admin endpoint rejects invalid configs", func(t *testing.T) {
+		h := slogscope.NewHandler(slogscope.NewNilHandler(), &slogscope.HandlerOptions{Config: &oldCfg})
+		srv := newAdminServer(t, h)
+		defer srv.Close()
+
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/config", strings.NewReader(`{"log_level": "LOUD"}`))
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, oldCfg, h.GetConfig())
+	})
+}
@@ -0,0 +1,58 @@
+package slogscope
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizePackagePath returns the import path of a package loaded from a vendor directory, e.g.
+// github.com/dep/lib for github.com/myorg/service/vendor/github.com/dep/lib in GOPATH mode, so a rule for the import
+// path matches regardless of how the dependency was vendored. Other package names are returned unchanged.
+func normalizePackagePath(pkgName string) string {
+	if i := strings.LastIndex(pkgName, "/vendor/"); i >= 0 {
+		return pkgName[i+len("/vendor/"):]
+	}
+	return strings.TrimPrefix(pkgName, "vendor/")
+}
+
+// normalizeFilePath returns the file path of a source file within the module cache without the module versions and
+// with the case-encoding of the module cache reverted, e.g. /go/pkg/mod/github.com/dep/lib/client.go for
+// /go/pkg/mod/github.com/dep/lib@v1.2.3/client.go, so file rules (see MatchFile) match regardless of the version in use.
+// Other file paths are returned unchanged.
+func normalizeFilePath(file string) string {
+	const modCache = "/pkg/mod/"
+	i := strings.Index(file, modCache)
+	if i < 0 {
+		return file
+	}
+	segments := strings.Split(file[i+len(modCache):], "/")
+	for j, s := range segments {
+		if name, _, ok := strings.Cut(s, "@"); ok {
+			segments[j] = name
+		}
+		segments[j] = unescapeModulePath(segments[j])
+	}
+	return file[:i+len(modCache)] + strings.Join(segments, "/")
+}
+
+// unescapeModulePath reverts the case-encoding of the module cache, e.g. github.com/!burnt!sushi for
+// github.com/BurntSushi.
+func unescapeModulePath(s string) string {
+	if !strings.Contains(s, "!") {
+		return s
+	}
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		switch {
+		case r == '!':
+			upper = true
+			continue
+		case upper:
+			r = unicode.ToUpper(r)
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package slogscope
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Verbosity is a flag.Value counting repeated verbosity flags of a CLI, e.g. -v -v or -v=2, to be passed via
+// HandlerOptions.Verbosity. Use ParseVerbosity for combined flags like -vv.
+//
+// Example:
+//
+//	var v slogscope.Verbosity
+//	flag.Var(&v, "v", "increase verbosity (repeatable)")
+type Verbosity int
+
+// String returns the number of verbosity flags.
+func (v *Verbosity) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// Set increments the verbosity for a flag without value, otherwise it sets the given verbosity.
+func (v *Verbosity) Set(s string) error {
+	if s == "true" {
+		*v++
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = Verbosity(n)
+	return nil
+}
+
+// IsBoolFlag allows the flag to be given without value.
+func (v *Verbosity) IsBoolFlag() bool {
+	return true
+}
+
+// ParseVerbosity returns the verbosity given by the command line arguments, which is the number of flags -v and
+// --verbose, where combined flags like -vvv count as multiple flags. Parsing stops at the argument "--".
+func ParseVerbosity(args []string) int {
+	var n int
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return n
+		case arg == "--verbose":
+			n++
+		case len(arg) > 1 && arg[0] == '-' && strings.Trim(arg[1:], "v") == "":
+			n += len(arg) - 1
+		}
+	}
+	return n
+}
+
+// WithVerbosity returns the config adjusted to the verbosity of a CLI (see HandlerOptions.Verbosity):
+//   - 1 (-v): the global log level and the rules for packages of the main module are one step more verbose,
+//     e.g. INFO becomes DEBUG. Rules for other packages, e.g. silenced dependencies, are kept.
+//   - 2 (-vv): additionally, the global log level and all package rules are at least DEBUG.
+//   - 3 (-vvv) or more: the global log level and all package rules are at least DEBUG-4.
+func (c Config) WithVerbosity(v int) Config {
+	if v <= 0 {
+		return c
+	}
+	var module string
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		module = bi.Main.Path
+	}
+	adjust := func(level string, mainModule bool) string {
+		lvl, _ := parseLogLevel(level)
+		switch {
+		case v >= 3:
+			lvl = min(lvl, slog.LevelDebug-4)
+		case v == 2:
+			lvl = min(lvl, slog.LevelDebug)
+		case mainModule && lvl > slog.LevelDebug:
+			lvl = max(lvl-4, slog.LevelDebug)
+		}
+		return lvl.String()
+	}
+
+	c.LogLevel = adjust(c.LogLevel, true)
+	packages := make([]Package, len(c.Packages))
+	for i, p := range c.Packages {
+		if strings.HasPrefix(p.Name, "!") {
+			packages[i] = p
+			continue
+		}
+		mainModule := module == "" || p.Name == module || strings.HasPrefix(p.Name, module+"/")
+		p.LogLevel = adjust(p.LogLevel, mainModule)
+		adjustFunctions := func(functions []Function) []Function {
+			if functions == nil {
+				return nil
+			}
+			adjusted := make([]Function, len(functions))
+			for j, f := range functions {
+				f.LogLevel = adjust(f.LogLevel, mainModule)
+				adjusted[j] = f
+			}
+			return adjusted
+		}
+		p.Functions, p.Receivers = adjustFunctions(p.Functions), adjustFunctions(p.Receivers)
+		packages[i] = p
+	}
+	if c.Packages != nil {
+		c.Packages = packages
+	}
+	return c
+}
+
+// verbosityFlag returns the verbosity as command line flag, e.g. -vv.
+func verbosityFlag(v int) string {
+	return "-" + strings.Repeat("v", v)
+}
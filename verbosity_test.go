@@ -0,0 +1,75 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"-v"}, 1},
+		{[]string{"-vv", "serve"}, 2},
+		{[]string{"-v", "--verbose", "-vv"}, 4},
+		{[]string{"-x", "-", "--", "-v"}, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, slogscope.ParseVerbosity(tt.args), tt.args)
+	}
+
+	var v slogscope.Verbosity
+	fs := flag.NewFlagSet("cli", flag.ContinueOnError)
+	fs.Var(&v, "v", "increase verbosity")
+	assert.NoError(t, fs.Parse([]string{"-v", "-v"}))
+	assert.Equal(t, slogscope.Verbosity(2), v)
+	assert.NoError(t, fs.Parse([]string{"-v=3"}))
+	assert.Equal(t, slogscope.Verbosity(3), v)
+}
+
+func TestConfig_WithVerbosity(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{
+			{Name: "github.com/apperia-de/slogscope/examples", LogLevel: slogscope.LogLevelError},
+			{Name: "github.com/foo/dependency", LogLevel: slogscope.LogLevelError},
+		},
+	}
+	tests := []struct {
+		verbosity int
+		global    string
+		own       string
+		dep       string
+	}{
+		{0, "INFO", "ERROR", "ERROR"},
+		{1, "DEBUG", "WARN", "ERROR"},
+		{2, "DEBUG", "DEBUG", "DEBUG"},
+		{3, "DEBUG-4", "DEBUG-4", "DEBUG-4"},
+	}
+	for _, tt := range tests {
+		got := cfg.WithVerbosity(tt.verbosity)
+		assert.Equal(t, tt.global, got.LogLevel)
+		assert.Equal(t, tt.own, got.Packages[0].LogLevel)
+		assert.Equal(t, tt.dep, got.Packages[1].LogLevel)
+	}
+	assert.Equal(t, slogscope.LogLevelError, cfg.Packages[0].LogLevel, "the original config must not be modified")
+
+	t.Run("test handler applies verbosity", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := slogscope.NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), &slogscope.HandlerOptions{
+			Config:    &slogscope.Config{LogLevel: slogscope.LogLevelInfo},
+			Verbosity: 1,
+		})
+		slog.New(h).Debug("debug message")
+		assert.Contains(t, buf.String(), "debug message")
+		assert.Equal(t, slogscope.LogLevelInfo, h.GetConfig().LogLevel)
+		assert.Equal(t, slogscope.LogLevelDebug, h.EffectiveConfig().LogLevel)
+	})
+}
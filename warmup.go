@@ -0,0 +1,92 @@
+//go:build !slogscope_minimal
+
+package slogscope
+
+import (
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// WarmUp resolves all functions of the packages matched by the package rules of the current config in advance, so
+// the first log call from every function doesn't pay the cost of resolving its caller, e.g. in latency-sensitive
+// services. The functions are found in the symbol table of the executable, which must be an ELF or Mach-O binary
+// without stripped Go symbols. It returns the number of resolved functions (see HandlerOptions.WarmUp).
+// It fails with the build tag slogscope_minimal.
+func (h *Handler) WarmUp() (int, error) {
+	start := time.Now()
+	table, err := readSymbolTable()
+	if err != nil {
+		return 0, fmt.Errorf("cannot read symbol table: %w", err)
+	}
+
+	// The executable may be loaded at a different address, e.g. if it is position independent.
+	self := runtime.FuncForPC(reflect.ValueOf((*Handler).WarmUp).Pointer())
+	sym := table.LookupFunc(self.Name())
+	if sym == nil {
+		return 0, errors.New("cannot read symbol table: executable doesn't match the running binary")
+	}
+	offset := self.Entry() - uintptr(sym.Entry)
+
+	var resolved int
+	for _, fn := range table.Funcs {
+		pkgName, _ := splitFuncName(fn.Name)
+		if isUnresolvedPackage(pkgName) {
+			continue
+		}
+		entry := uintptr(fn.Entry) + offset
+		f := runtime.FuncForPC(entry)
+		if f == nil || f.Entry() != entry {
+			continue
+		}
+		file, _ := f.FileLine(entry)
+		if _, ok := h.callerRule(h.scope(pkgName, file), file, ""); !ok {
+			continue
+		}
+		// The return address of a call is behind the entry (see callerOf).
+		h.callerOf(entry + 1)
+		resolved++
+	}
+	h.logger.Debug(fmt.Sprintf("warmed up %d functions in %s", resolved, time.Since(start)))
+	return resolved, nil
+}
+
+// readSymbolTable returns the Go symbol table of the executable.
+func readSymbolTable() (*gosym.Table, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	var pclntab []byte
+	var text uint64
+	if f, err := elf.Open(exe); err == nil {
+		defer f.Close()
+		s, t := f.Section(".gopclntab"), f.Section(".text")
+		if s == nil || t == nil {
+			return nil, errors.New("missing .gopclntab section")
+		}
+		if pclntab, err = s.Data(); err != nil {
+			return nil, err
+		}
+		text = t.Addr
+	} else if f, err := macho.Open(exe); err == nil {
+		defer f.Close()
+		s, t := f.Section("__gopclntab"), f.Section("__text")
+		if s == nil || t == nil {
+			return nil, errors.New("missing __gopclntab section")
+		}
+		if pclntab, err = s.Data(); err != nil {
+			return nil, err
+		}
+		text = t.Addr
+	} else {
+		return nil, fmt.Errorf("unsupported executable format: %s", exe)
+	}
+	return gosym.NewTable(nil, gosym.NewLineTable(pclntab, text))
+}
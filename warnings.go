@@ -0,0 +1,46 @@
+package slogscope
+
+import (
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// warnings counts the internal warnings by key, e.g. "unknown_log_level:TRACE", so every misconfiguration is
+// reported once instead of flooding the output (see slogscope.warnOnce).
+type warnings struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// warnOnce emits the warning with the given message and arguments through the wrapped slog.Handler on the first
+// occurrence of the key only. All occurrences are counted (see Stats.Warnings).
+func (ss *slogscope) warnOnce(key, msg string, args ...any) {
+	ss.warnings.mu.Lock()
+	if ss.warnings.counts == nil {
+		ss.warnings.counts = map[string]uint64{}
+	}
+	ss.warnings.counts[key]++
+	first := ss.warnings.counts[key] == 1
+	ss.warnings.mu.Unlock()
+
+	if first {
+		slog.New(ss.slogh).Warn(msg, args...)
+	}
+}
+
+// snapshot returns the number of occurrences of all warnings by key.
+func (w *warnings) snapshot() map[string]uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return maps.Clone(w.counts)
+}
+
+// logLevel returns the log level like Handler.GetLogLevel and warns once about every invalid log level.
+func (ss *slogscope) logLevel(level string) slog.Level {
+	lvl, ok := parseLogLevel(level)
+	if !ok && level != "" {
+		ss.warnOnce("unknown_log_level:"+level, "slogscope: invalid log level is replaced", "log_level", level, "replacement", lvl.String())
+	}
+	return lvl
+}
@@ -0,0 +1,95 @@
+package slogscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// probeLevels are the log levels the Enabled contract of middleware handlers is checked for (see Chain.Validate).
+var probeLevels = []slog.Level{slog.LevelDebug - 4, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, slog.LevelError + 4}
+
+// Chain is a chain of middleware handlers, e.g. OpenTelemetry bridges or redactors, composed by Wrap.
+type Chain struct {
+	middleware []func(slog.Handler) slog.Handler
+}
+
+// Wrap returns the Chain of the given middleware handlers. Its Handler is always the outermost handler, because it
+// resolves the package of the log call in Enabled, which only works if slog.Logger calls it directly. Records
+// enabled by the config pass the middleware handlers in the given order, before they reach the wrapped handler:
+//
+//	h, err := slogscope.Wrap(otelMiddleware, redactMiddleware).NewHandler(slog.NewJSONHandler(os.Stdout, nil), opts)
+func Wrap(chain ...func(slog.Handler) slog.Handler) Chain {
+	return Chain{middleware: chain}
+}
+
+// NewHandler validates the chain and returns a Handler for next with the middleware handlers in between (see
+// NewHandler and Chain.Validate).
+func (c Chain) NewHandler(next slog.Handler, opts *HandlerOptions) (*Handler, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	return NewHandler(next, opts), nil
+}
+
+// Validate checks that every middleware handler returns a handler, stays in the chain for WithAttrs and WithGroup and
+// keeps the Enabled contract: as the Handler decides which records are enabled and doesn't call Enabled of the
+// handlers it wraps, a middleware handler must not disable records its next handler accepts. Such a filter would be
+// bypassed silently, so log levels have to be configured via the Config instead.
+func (c Chain) Validate() error {
+	var errs []error
+	for i, m := range c.middleware {
+		if err := validateMiddleware(m); err != nil {
+			errs = append(errs, fmt.Errorf("chain[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateMiddleware checks a single middleware handler against a probe handler, which is enabled for all records.
+func validateMiddleware(m func(slog.Handler) slog.Handler) error {
+	if m == nil {
+		return errors.New("middleware must not be nil")
+	}
+	h := m(probeHandler{})
+	if h == nil {
+		return errors.New("middleware returned a nil handler")
+	}
+	for _, lvl := range probeLevels {
+		if !h.Enabled(context.Background(), lvl) {
+			return fmt.Errorf("Enabled(%s) returns false, although the next handler is enabled: configure log levels via the config instead", lvl)
+		}
+	}
+	// A middleware handler embedding its next handler without overriding WithAttrs and WithGroup drops itself from
+	// the chain as soon as slog.Logger.With is used.
+	switch h.WithAttrs([]slog.Attr{slog.String("probe", "probe")}).(type) {
+	case nil:
+		return errors.New("WithAttrs returned a nil handler")
+	case probeHandler:
+		return errors.New("WithAttrs returned the next handler, which drops the middleware")
+	}
+	switch h.WithGroup("probe").(type) {
+	case nil:
+		return errors.New("WithGroup returned a nil handler")
+	case probeHandler:
+		return errors.New("WithGroup returned the next handler, which drops the middleware")
+	}
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "probe", 0)
+	if err := h.Handle(context.Background(), rec); err != nil {
+		return fmt.Errorf("Handle failed: %w", err)
+	}
+	return nil
+}
+
+// probeHandler is the slog.Handler enabled for all records, which middleware handlers are validated with.
+type probeHandler struct{}
+
+func (probeHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (probeHandler) Handle(context.Context, slog.Record) error { return nil }
+func (p probeHandler) WithAttrs([]slog.Attr) slog.Handler      { return p }
+func (p probeHandler) WithGroup(string) slog.Handler           { return p }
@@ -0,0 +1,109 @@
+package slogscope_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/apperia-de/slogscope"
+	"github.com/stretchr/testify/assert"
+)
+
+// redactHandler replaces the value of the attribute "password".
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h redactHandler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		if a.Key == "password" {
+			a.Value = slog.StringValue("REDACTED")
+		}
+		out.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return redactHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h.Handler.WithGroup(name)}
+}
+
+// prefixHandler prefixes the message of all records.
+type prefixHandler struct {
+	slog.Handler
+	prefix string
+}
+
+func (h prefixHandler) Handle(ctx context.Context, rec slog.Record) error {
+	rec.Message = h.prefix + rec.Message
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h prefixHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return prefixHandler{h.Handler.WithAttrs(attrs), h.prefix}
+}
+
+func (h prefixHandler) WithGroup(name string) slog.Handler {
+	return prefixHandler{h.Handler.WithGroup(name), h.prefix}
+}
+
+// levelFilter disables all records below WARN, breaking the Enabled contract.
+type levelFilter struct {
+	slog.Handler
+}
+
+func (levelFilter) Enabled(_ context.Context, lvl slog.Level) bool { return lvl >= slog.LevelWarn }
+
+func TestWrap(t *testing.T) {
+	cfg := slogscope.Config{
+		LogLevel: slogscope.LogLevelInfo,
+		Packages: []slogscope.Package{{Name: "github.com/apperia-de/slogscope_test", LogLevel: slogscope.LogLevelDebug}},
+	}
+	redact := func(next slog.Handler) slog.Handler { return redactHandler{next} }
+	prefix := func(p string) func(slog.Handler) slog.Handler {
+		return func(next slog.Handler) slog.Handler { return prefixHandler{next, p} }
+	}
+
+	t.Run("test records pass the chain in order with scoping preserved", func(t *testing.T) {
+		var buf bytes.Buffer
+		h, err := slogscope.Wrap(redact, prefix("a:"), prefix("b:")).NewHandler(slog.NewTextHandler(&buf, nil), &slogscope.HandlerOptions{Config: &cfg})
+		assert.NoError(t, err)
+		l := slog.New(h).With("service", "api")
+		l.Debug("login", "password", "secret")
+
+		assert.Contains(t, buf.String(), `level=DEBUG msg=b:a:login service=api password=REDACTED`)
+		assert.Equal(t, "github.com/apperia-de/slogscope_test", h.GetPackages()[0].Name)
+		assert.Equal(t, uint64(1), h.GetPackages()[0].Observed)
+	})
+
+	t.Run("test middleware breaking the Enabled contract is rejected", func(t *testing.T) {
+		filter := func(next slog.Handler) slog.Handler { return levelFilter{next} }
+		h, err := slogscope.Wrap(redact, filter).NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), &slogscope.HandlerOptions{Config: &cfg})
+		assert.Nil(t, h)
+		assert.EqualError(t, err, "chain[1]: Enabled(DEBUG-4) returns false, although the next handler is enabled: configure log levels via the config instead")
+	})
+
+	t.Run("test invalid middleware is rejected", func(t *testing.T) {
+		embedding := func(next slog.Handler) slog.Handler { return levelFilter{next} }
+		err := slogscope.Wrap(nil, func(slog.Handler) slog.Handler { return nil }, embedding).Validate()
+		assert.Error(t, err)
+		assert.Equal(t, []string{
+			"chain[0]: middleware must not be nil",
+			"chain[1]: middleware returned a nil handler",
+			"chain[2]: Enabled(DEBUG-4) returns false, although the next handler is enabled: configure log levels via the config instead",
+		}, strings.Split(err.Error(), "\n"))
+	})
+
+	t.Run("test middleware dropping itself on WithAttrs is rejected", func(t *testing.T) {
+		embedding := func(next slog.Handler) slog.Handler { return struct{ slog.Handler }{next} }
+		assert.EqualError(t, slogscope.Wrap(embedding).Validate(), "chain[0]: WithAttrs returned the next handler, which drops the middleware")
+	})
+}